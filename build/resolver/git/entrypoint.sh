@@ -16,13 +16,33 @@ USAGE=$(cat <<-END
      Supported commands are:
      - help Print out this help messages.
      - pull Pull git source to $WORKDIR/data, "/workspace/data" by default.
-     - push Push git source to remote git server. (Not implemented yet)
+     - push Push a tag or the head commit of git source in $WORKDIR/data to
+       remote git server.
 
-     Environment variables GIT_URL, GIT_REVISION must be set. Only HTTPS is
-     supported now for GIT_URL. And revision supports branch and tag, but not
-     commit id. PULL_POLICY indicates whether pull resources when there already
-     are old data, if set to IfNotPresent, will make use of the old data and
-     perform incremental pull, otherwise old data would be removed.
+     Environment variables:
+     - GIT_URL (required) URL of the repository, HTTP(S) and SSH are supported,
+       for example, 'https://github.com/caicloud/cyclone.git' or
+       'git@github.com:caicloud/cyclone.git'.
+     - GIT_REVISION (required to pull) Revision to pull, it can be a branch,
+       a tag, a commit SHA, or a ref such as 'refs/pull/1/head' and
+       'refs/pull/1/merge'.
+     - GIT_TOKEN Token to access HTTP(S) repositories.
+     - GIT_SSH_KEY Private key to access SSH repositories.
+     - GIT_SSH_KNOWN_HOSTS (required for SSH) Known hosts of SSH repositories,
+       in the format of 'ssh-keyscan' output.
+     - GIT_SSH_INSECURE_SKIP_HOST_KEY_CHECK Skip checking host keys of SSH
+       repositories if GIT_SSH_KNOWN_HOSTS is unset, false by default. It's
+       vulnerable to man-in-the-middle attacks.
+     - GIT_DEPTH Depth of the clone, full history is pulled if unset or 0.
+     - GIT_SUBMODULES Whether to init submodules recursively, false by default.
+     - GIT_LFS Whether to pull Git LFS objects, false by default.
+     - GIT_PUSH_TAG Tag to create on the head commit and push.
+     - GIT_PUSH_BRANCH Branch to push the head commit to.
+     - PULL_POLICY Whether pull resources when there already are old data, if
+       set to IfNotPresent, will make use of the old data and perform
+       incremental pull, otherwise old data would be removed.
+     - RESULT_PATH File to write the result, such as the commit SHA resolved,
+       "/dev/termination-log" by default.
 END
 )
 
@@ -35,8 +55,7 @@ COMMAND=$1
 # Check whether environment variables are set.
 if [ -z ${WORKDIR+x} ]; then echo "WORKDIR is unset"; exit 1; fi
 if [ -z ${GIT_URL+x} ]; then echo "GIT_URL is unset"; exit 1; fi
-if [ -z ${GIT_REVISION+x} ]; then echo "GIT_REVISION is unset"; exit 1; fi
-if [ -z ${GIT_TOKEN+x} ]; then echo "WARN: GIT_TOKEN is unset"; fi
+if [ $COMMAND == "pull" ] && [ -z ${GIT_REVISION+x} ]; then echo "GIT_REVISION is unset"; exit 1; fi
 
 # Lock file for the WorkflowRun.
 PULLING_LOCK=$WORKDIR/${WORKFLOWRUN_NAME}-pulling.lock
@@ -51,6 +70,79 @@ releaseLock() {
 # Make sure the lock file is deleted if created by this resolver.
 trap releaseLock EXIT
 
+# Configure credentials to access the repository. For SSH, the private key is
+# used by ssh command of git. For HTTP(S), the token is added to URLs of the
+# server by url.<base>.insteadOf, so that it's also used by submodules and LFS
+# without being saved in the remote URL.
+setupAuth() {
+    case $GIT_URL in
+        http://* | https://* )
+            if [ -z "${GIT_TOKEN}" ]; then
+                echo "WARN: GIT_TOKEN is unset"
+            else
+                # Base URL of the server, for example, 'https://github.com/'.
+                server=$(echo $GIT_URL | sed -E 's|^(https?://)([^/@]*@)?([^/]+)/.*$|\1\3/|')
+                git config --global url."${server%%//*}//${GIT_TOKEN}@${server#*//}".insteadOf "$server"
+            fi
+            ;;
+        * )
+            if [ -z "${GIT_SSH_KEY}" ]; then echo "GIT_SSH_KEY is unset for SSH URL"; exit 1; fi
+            mkdir -p ~/.ssh
+            chmod 700 ~/.ssh
+            echo "${GIT_SSH_KEY}" > ~/.ssh/id_cyclone
+            chmod 600 ~/.ssh/id_cyclone
+            if [ ! -z "${GIT_SSH_KNOWN_HOSTS}" ]; then
+                echo "${GIT_SSH_KNOWN_HOSTS}" > ~/.ssh/known_hosts
+                export GIT_SSH_COMMAND="ssh -i ~/.ssh/id_cyclone -o IdentitiesOnly=yes -o StrictHostKeyChecking=yes"
+            elif echo "${GIT_SSH_INSECURE_SKIP_HOST_KEY_CHECK}" | grep -qxE '1|t|T|true|TRUE|True'; then
+                echo "WARN: GIT_SSH_INSECURE_SKIP_HOST_KEY_CHECK is true, host keys would not be checked"
+                export GIT_SSH_COMMAND="ssh -i ~/.ssh/id_cyclone -o IdentitiesOnly=yes -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null"
+            else
+                echo "GIT_SSH_KNOWN_HOSTS is unset for SSH URL"
+                exit 1
+            fi
+            ;;
+    esac
+}
+
+# Fetch the revision from origin and checkout it. Branches, tags and refs are
+# fetched directly. Commit SHAs are fetched directly if the server allows, or
+# found in all branches otherwise.
+fetchRevision() {
+    DEPTH_ARGS=""
+    if [ ${GIT_DEPTH:-0} -gt 0 ]; then
+        DEPTH_ARGS="--depth=${GIT_DEPTH}"
+    fi
+
+    echo "Fetch $GIT_REVISION from origin"
+    if echo $GIT_REVISION | grep -qE '^[0-9a-f]{7,40}$'; then
+        if [ ${#GIT_REVISION} -eq 40 ] && git fetch -v $DEPTH_ARGS origin $GIT_REVISION; then
+            git checkout -f FETCH_HEAD
+        else
+            echo "Fetch commit $GIT_REVISION directly failed, search it in all branches"
+            git fetch -v $([ -f .git/shallow ] && echo --unshallow) origin '+refs/heads/*:refs/remotes/origin/*'
+            git checkout -f $GIT_REVISION
+        fi
+    else
+        git fetch -v $DEPTH_ARGS origin $GIT_REVISION
+        git checkout -f FETCH_HEAD
+    fi
+}
+
+# Init submodules and pull LFS objects if required.
+fetchExtras() {
+    if [ "${GIT_SUBMODULES:-false}" == "true" ]; then
+        echo "Update submodules"
+        git submodule sync --recursive
+        git submodule update --init --recursive $DEPTH_ARGS
+    fi
+    if [ "${GIT_LFS:-false}" == "true" ]; then
+        echo "Pull LFS objects"
+        git lfs install --local
+        git lfs pull
+    fi
+}
+
 wrapPull() {
     # If there is already data, we should just wait for the data to be ready.
     if [ -e $WORKDIR/data ]; then
@@ -83,6 +175,8 @@ wrapPull() {
 }
 
 pull() {
+    setupAuth
+
     # If data existed and pull policy is IfNotPresent, perform incremental pull.
     if [ -e $WORKDIR/data ] && [ ${PULL_POLICY:=Always} == "IfNotPresent" ]; then
         cd $WORKDIR/data
@@ -91,34 +185,73 @@ pull() {
             echo "Existed data not a valid git repo for ${GIT_URL##*//}"
             exit 1
         }
-
-        echo "Fetch $GIT_REVISION from origin"
-        git fetch -v origin $GIT_REVISION
-        git checkout FETCH_HEAD
     else
         if [ -e $WORKDIR/data ]; then
             echo "Clean old data ($WORKDIR/data) when pull policy is Always"
             rm -rf $WORKDIR/data
         fi
-        cd $WORKDIR
 
-        # Add token to url if provided and clone git repo
-        if [ -z ${GIT_TOKEN+x} ]; then
-            git clone -v -b $GIT_REVISION --single-branch ${GIT_URL} data
-        else
-            git clone -v -b $GIT_REVISION --single-branch ${GIT_URL/\/\//\/\/${GIT_TOKEN}@} data
-        fi
+        git init $WORKDIR/data
+        cd $WORKDIR/data
+        git remote add origin ${GIT_URL}
+    fi
+
+    fetchRevision
+    fetchExtras
+    git log -1 --oneline
+}
+
+# Write the commit SHA of the data resolved as the result, it's recorded in
+# WorkflowRun status by Cyclone. Failure to write it doesn't fail the resolver.
+writeResult() {
+    local revision=$(git -C $WORKDIR/data rev-parse HEAD)
+    echo "{\"revision\":\"${revision}\"$1}" > ${RESULT_PATH:-/dev/termination-log} || echo "Warn: failed to write result"
+}
+
+# Wait until resource data is ready.
+wait_ok() {
+    while [ ! -f ${WORKDIR}/notify/ok ]
+    do
+        sleep 3
+    done
+}
+
+push() {
+    wait_ok
+    setupAuth
+
+    cd $WORKDIR/data
+    git rev-parse --verify HEAD > /dev/null || {
+        echo "No commit found in $WORKDIR/data"
+        exit 1
+    }
+    git remote remove cyclone-push 2> /dev/null || true
+    git remote add cyclone-push ${GIT_URL}
+
+    if [ ! -z "${GIT_PUSH_TAG}" ]; then
+        echo "Push tag $GIT_PUSH_TAG of $(git rev-parse HEAD)"
+        git tag -f $GIT_PUSH_TAG HEAD
+        git push -v cyclone-push refs/tags/$GIT_PUSH_TAG
+        writeResult ",\"ref\":\"refs/tags/${GIT_PUSH_TAG}\""
+    elif [ ! -z "${GIT_PUSH_BRANCH}" ]; then
+        echo "Push $(git rev-parse HEAD) to branch $GIT_PUSH_BRANCH"
+        git push -v cyclone-push HEAD:refs/heads/$GIT_PUSH_BRANCH
+        writeResult ",\"ref\":\"refs/heads/${GIT_PUSH_BRANCH}\""
+    else
+        echo "GIT_PUSH_TAG or GIT_PUSH_BRANCH should be set to push"
+        exit 1
     fi
 }
 
 case $COMMAND in
     pull )
         wrapPull
+        writeResult
         ;;
     push )
-        echo "Not implemented yet"
+        push
         ;;
     * )
         echo "$USAGE"
         ;;
-esac
\ No newline at end of file
+esac
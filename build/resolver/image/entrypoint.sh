@@ -26,6 +26,9 @@ USAGE=$(cat <<-END
 
      IMAGE_FILE is an optional variable, if set, image will be loaded from this tar file.
 
+     RESULT_PATH is the file to write the result, such as the digest of the image, "/dev/termination-log"
+     by default.
+
      You will need to mount /var/run/docker.sock.
 END
 )
@@ -72,6 +75,37 @@ fi
 
 pull() {
     docker pull $IMAGE
+    # Digest of the image pulled, RepoDigests are in format <repo>@<digest>.
+    digest=$(docker inspect --format '{{range .RepoDigests}}{{println .}}{{end}}' $IMAGE | head -n 1)
+    writeResult "${digest##*@}" ""
+}
+
+# Push the image, digest and size of the manifest are got from the last line of the
+# output, in format '<tag>: digest: <digest> size: <size>'.
+push() {
+    docker push $IMAGE > /tmp/push.log || {
+        cat /tmp/push.log
+        exit 1
+    }
+    cat /tmp/push.log
+    last=$(tail -n 1 /tmp/push.log)
+    digest=$(echo "$last" | sed -n -E 's/.*digest: ([^ ]+).*/\1/p')
+    size=$(echo "$last" | sed -n -E 's/.*size: ([0-9]+).*/\1/p')
+    writeResult "$digest" "$size"
+}
+
+# Write the digest and size of the image as the result, it's recorded in WorkflowRun
+# status by Cyclone. Failure to write it doesn't fail the resolver.
+writeResult() {
+    if [ -z "$1" ]; then
+        echo "Warn: digest of image $IMAGE not found"
+        return
+    fi
+    result="\"digest\":\"$1\""
+    if [ ! -z "$2" ]; then
+        result="${result},\"size\":\"$2\""
+    fi
+    echo "{${result}}" > ${RESULT_PATH:-/dev/termination-log} || echo "Warn: failed to write result"
 }
 
 # Wait until resource data is ready.
@@ -92,7 +126,7 @@ case $COMMAND in
             echo "Load images from file ${IMAGE_FILE}"
             docker load -i ${WORKDIR}/data/${IMAGE_FILE}
         fi
-        docker push $IMAGE
+        push
         ;;
     * )
         echo "$USAGE"
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/caicloud/cyclone/pkg/workflow/resolver"
+)
+
+const (
+	// pullCommandParam is the shell command to pull the resource to the data directory.
+	pullCommandParam = "PULL_COMMAND"
+	// pushCommandParam is the shell command to push data in the data directory.
+	pushCommandParam = "PUSH_COMMAND"
+	// envResultFile is the file where commands write result metadata, in lines of
+	// '<key>: <value>'.
+	envResultFile = "RESULT_FILE"
+)
+
+// genericResolver resolves General resources by shell commands given in parameters, so that
+// custom resources can be resolved without building a resolver image.
+type genericResolver struct{}
+
+// Pull ...
+func (r *genericResolver) Pull(ctx *resolver.Context) (resolver.Result, error) {
+	if err := os.MkdirAll(ctx.DataDir(), 0755); err != nil {
+		return nil, err
+	}
+	return r.run(ctx, pullCommandParam)
+}
+
+// Push ...
+func (r *genericResolver) Push(ctx *resolver.Context) (resolver.Result, error) {
+	return r.run(ctx, pushCommandParam)
+}
+
+// run runs the command in the data directory, and reads result written by it.
+func (r *genericResolver) run(ctx *resolver.Context, param string) (resolver.Result, error) {
+	command, err := ctx.RequiredParam(param)
+	if err != nil {
+		return nil, err
+	}
+
+	resultFile, err := ioutil.TempFile("", "result")
+	if err != nil {
+		return nil, err
+	}
+	resultFile.Close()
+	defer os.Remove(resultFile.Name())
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = ctx.DataDir()
+	cmd.Env = append(os.Environ(), envResultFile+"="+resultFile.Name())
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("run %s error: %v", param, err)
+	}
+
+	return readResult(resultFile.Name())
+}
+
+// readResult reads result in lines of '<key>: <value>', the same format as KV resources.
+func readResult(path string) (resolver.Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := resolver.Result{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return result, scanner.Err()
+}
+
+func main() {
+	resolver.Main(&genericResolver{})
+}
@@ -17,6 +17,7 @@ package main
 
 import (
 	"flag"
+	"time"
 
 	"github.com/caicloud/cyclone/pkg/common"
 	"github.com/caicloud/cyclone/pkg/server/apis"
@@ -27,7 +28,10 @@ import (
 	"github.com/caicloud/cyclone/pkg/server/handler/v1alpha1"
 	"github.com/caicloud/cyclone/pkg/server/version"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 
+	"github.com/caicloud/cyclone/pkg/server/biz/logstore"
+	"github.com/caicloud/cyclone/pkg/server/biz/summarystore"
 	"github.com/caicloud/cyclone/pkg/server/biz/tenants"
 	"github.com/caicloud/nirvana"
 	nconfig "github.com/caicloud/nirvana/config"
@@ -83,7 +87,18 @@ func initialize(opts *Options) {
 		log.Fatalf("Load config from ConfigMap %s error: %s", opts.ConfigMap, err)
 	}
 
-	handler.Init(client)
+	store, err := logstore.New(config.Config.Logs)
+	if err != nil {
+		log.Fatalf("Create log store error: %v", err)
+	}
+	go logstore.RunRetention(store, time.Duration(config.Config.Logs.RetentionDays)*24*time.Hour, time.Hour, wait.NeverStop)
+
+	summaries, err := summarystore.New(config.Config.History)
+	if err != nil {
+		log.Fatalf("Create summary store error: %v", err)
+	}
+
+	handler.Init(client, store, summaries)
 	log.Info("Init handlers succeed.")
 
 	err = v1alpha1.CreateAdminTenant()
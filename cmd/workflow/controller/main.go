@@ -4,20 +4,36 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
 
 	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
 	"github.com/caicloud/cyclone/pkg/common"
 	"github.com/caicloud/cyclone/pkg/common/signals"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/workflow/controller"
 	"github.com/caicloud/cyclone/pkg/workflow/controller/controllers"
+	"github.com/caicloud/cyclone/pkg/workflow/leaderelection"
+	"github.com/caicloud/cyclone/pkg/workflow/metrics"
+	"github.com/caicloud/cyclone/pkg/workflow/trigger"
 )
 
 var kubeConfigPath = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
 var configMap = flag.String("configmap", "workflow-controller-config", "ConfigMap that configures workflow controller")
 var namespace = flag.String("namespace", "default", "Namespace that workflow controller will run in")
+var metricsAddress = flag.String("metrics-address", ":9090", "Address to serve Prometheus metrics on")
+var leaderElect = flag.Bool("leader-elect", true, "Whether to elect a leader among controller replicas, only the leader processes resources")
+var leaderElectLock = flag.String("leader-elect-lock", "workflow-controller-leader", "Name of the resource used as the lock of leader election")
+var leaderElectLockType = flag.String("leader-elect-lock-type", leaderelection.ConfigMapsLock, "Type of the leader election lock, 'configmaps' or 'leases'")
+
+// healthzTolerance is how long the leader can fail to renew leadership beyond the lease duration
+// before it's reported unhealthy.
+const healthzTolerance = 20 * time.Second
 
 func main() {
 	flag.Parse()
@@ -33,6 +49,42 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	signals.GracefulShutdown(cancel)
 
+	// Only the leader runs controllers, so that multiple replicas can run for high availability.
+	var elector *leaderelection.Elector
+	if *leaderElect {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Fatal("Get hostname error: ", err)
+		}
+		config := leaderelection.DefaultConfig(client, *namespace, *leaderElectLock, hostname+"-"+rand.String(5))
+		config.LockType = *leaderElectLockType
+		elector, err = leaderelection.NewElector(config)
+		if err != nil {
+			log.Fatal("Create leader elector error: ", err)
+		}
+	}
+
+	// Serve metrics of the controller, with health checks. It's ready once initialized, no
+	// matter whether it's the leader.
+	var ready int32
+	go func() {
+		healthz := func() error {
+			if elector == nil {
+				return nil
+			}
+			return elector.Check(healthzTolerance)
+		}
+		readyz := func() error {
+			if atomic.LoadInt32(&ready) == 0 {
+				return fmt.Errorf("not initialized")
+			}
+			return nil
+		}
+		if err := metrics.Serve(*metricsAddress, healthz, readyz); err != nil {
+			log.WithField("address", *metricsAddress).Error("Serve metrics error: ", err)
+		}
+	}()
+
 	// Load configuration from ConfigMap.
 	cm, err := client.CoreV1().ConfigMaps(*namespace).Get(*configMap, metav1.GetOptions{})
 	if err != nil {
@@ -52,18 +104,47 @@ func main() {
 	cmController := controllers.NewConfigMapController(client, *namespace, *configMap)
 	go cmController.Run(ctx.Done())
 
+	atomic.StoreInt32(&ready, 1)
+
+	if elector == nil {
+		runControllers(ctx, client)
+		// Wait forever.
+		select {}
+	}
+
+	// Leadership is released when stopped gracefully, so that another replica can take over
+	// without waiting for the lease to expire.
+	elector.Run(ctx, func(ctx context.Context) {
+		runControllers(ctx, client)
+	})
+	select {
+	case <-ctx.Done():
+		log.Info("Workflow controller stopped")
+	default:
+		// In-memory states of controllers are not reliable after leadership lost, exit so that
+		// they are rebuilt when leadership is acquired again after restarted.
+		log.Fatal("Leadership lost, exit")
+	}
+}
+
+// runControllers starts controllers that process resources.
+func runControllers(ctx context.Context, client clientset.Interface) {
 	// Watch workflowTrigger who will start workflowRun on schedule
-	wftController := controllers.NewWorkflowTriggerController(client)
+	wftController := controllers.NewWorkflowTriggerController(client, ctx.Done())
 	go wftController.Run(ctx.Done())
 
+	// Create and start Workflow controller.
+	wfController := controllers.NewWorkflowController(client)
+	go wfController.Run(ctx.Done())
+
 	// Create and start WorkflowRun controller.
-	wfrController := controllers.NewWorkflowRunController(client)
+	wfrController := controllers.NewWorkflowRunController(client, ctx.Done())
 	go wfrController.Run(ctx.Done())
 
 	// Create and start Pod controller.
 	podController := controllers.NewPodController(client)
 	go podController.Run(ctx.Done())
 
-	// Wait forever.
-	select {}
+	// Poll sources of image and SCM poll triggers, each trigger is polled at its own interval.
+	go trigger.NewPoller(client).Run(10*time.Second, ctx.Done())
 }
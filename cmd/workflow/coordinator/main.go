@@ -15,6 +15,9 @@ import (
 
 var kubeConfigPath = flag.String("kubeconfig", "", "Path to kubeconfig. Only required if out-of-cluster.")
 
+// logsCollectTimeout is the maximum time to wait for logs to be pushed before exit.
+const logsCollectTimeout = 30 * time.Second
+
 func main() {
 	flag.Parse()
 
@@ -39,6 +42,11 @@ func main() {
 	}
 
 	defer func() {
+		// Wait logs to be pushed completely, otherwise they would be marked incomplete.
+		if !c.WaitLogsCollected(logsCollectTimeout) {
+			log.Warning("Timeout to wait for logs collected")
+		}
+
 		if err != nil {
 			log.Error(message)
 			// Wait for sending event
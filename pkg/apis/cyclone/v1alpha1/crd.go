@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	"reflect"
+
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -8,6 +10,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
 )
 
 func newKubeExtClient(masterURL, kubeConfigPath string) (apiextensionsclient.Interface, error) {
@@ -38,17 +41,28 @@ func EnsureCRDCreated(masterURL, kubeConfigPath string) {
 
 	createCRD("resource", "resources", "Resource", []string{"rsc"}, client)
 	createCRD("stage", "stages", "Stage", []string{"stg"}, client)
-	createCRD("workflow", "workflows", "Workflow", []string{"wf"}, client)
+	createCRD("workflow", "workflows", "Workflow", []string{"wf"}, client, workflowColumns...)
 	createCRD("workflowrun", "workflowruns", "WorkflowRun", []string{"wfr"}, client)
 	createCRD("workflowtrigger", "workflowtriggers", "WorkflowTrigger", []string{"wft"}, client)
 	createCRD("project", "projects", "Project", []string{"proj"}, client)
 }
 
-func createCRD(singular, plural, kind string, shortNames []string, client apiextensionsclient.Interface) {
+// workflowColumns are columns of workflow health shown in 'kubectl get workflows'.
+var workflowColumns = []v1beta1.CustomResourceColumnDefinition{
+	{Name: "Valid", Type: "string", JSONPath: `.status.conditions[?(@.type=="Valid")].status`},
+	{Name: "Resolved", Type: "string", JSONPath: `.status.conditions[?(@.type=="StagesResolved")].status`},
+	{Name: "Last Run", Type: "string", JSONPath: ".status.lastRun.name"},
+	{Name: "Last Status", Type: "string", JSONPath: ".status.lastRun.status"},
+	{Name: "Success Rate", Type: "integer", JSONPath: ".status.successRate", Description: "Percentage of completed runs in recent terminated runs"},
+	{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+}
+
+func createCRD(singular, plural, kind string, shortNames []string, client apiextensionsclient.Interface, columns ...v1beta1.CustomResourceColumnDefinition) {
 	crdName := plural + "." + GroupName
-	_, err := client.ApiextensionsV1beta1().CustomResourceDefinitions().Get(crdName, metav1.GetOptions{})
+	crd, err := client.ApiextensionsV1beta1().CustomResourceDefinitions().Get(crdName, metav1.GetOptions{})
 	if err == nil {
 		log.WithField("name", crdName).Info("crd already exist")
+		updateCRDColumns(crd, columns, client)
 		return
 	}
 
@@ -78,11 +92,34 @@ func createCRD(singular, plural, kind string, shortNames []string, client apiext
 				Singular:   singular,
 				ShortNames: shortNames,
 			},
+			AdditionalPrinterColumns: columns,
 		},
 	})
 	if err != nil {
 		log.WithField("name", crdName).WithField("error", err).Fatal("create crd error")
 		return
 	}
+}
+
+// updateCRDColumns updates printer columns of the existing CRD if they are changed, so that new
+// columns are shown after upgraded. CRDs without columns given are left as they are, since API
+// server defaults their columns.
+func updateCRDColumns(crd *v1beta1.CustomResourceDefinition, columns []v1beta1.CustomResourceColumnDefinition, client apiextensionsclient.Interface) {
+	if len(columns) == 0 || reflect.DeepEqual(crd.Spec.AdditionalPrinterColumns, columns) {
+		return
+	}
 
+	log.WithField("name", crd.Name).Info("update columns of crd")
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := client.ApiextensionsV1beta1().CustomResourceDefinitions().Get(crd.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest.Spec.AdditionalPrinterColumns = columns
+		_, err = client.ApiextensionsV1beta1().CustomResourceDefinitions().Update(latest)
+		return err
+	})
+	if err != nil {
+		log.WithField("name", crd.Name).WithField("error", err).Warning("update columns of crd error")
+	}
 }
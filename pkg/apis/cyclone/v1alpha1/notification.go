@@ -0,0 +1,88 @@
+package v1alpha1
+
+// NotificationTrigger is a transition of WorkflowRun overall status that triggers notifications.
+type NotificationTrigger string
+
+const (
+	// NotificationTriggerFailed is triggered when a WorkflowRun failed.
+	NotificationTriggerFailed NotificationTrigger = "Failed"
+	// NotificationTriggerFixed is triggered when a WorkflowRun completed while the previous
+	// WorkflowRun of the same Workflow failed.
+	NotificationTriggerFixed NotificationTrigger = "Fixed"
+	// NotificationTriggerCompleted is triggered when a WorkflowRun completed.
+	NotificationTriggerCompleted NotificationTrigger = "Completed"
+	// NotificationTriggerWaiting is triggered when a WorkflowRun is waiting for approval.
+	NotificationTriggerWaiting NotificationTrigger = "Waiting"
+)
+
+// NotificationType is type of notification receivers.
+type NotificationType string
+
+const (
+	// NotificationTypeWebhook sends notifications to a generic HTTP webhook.
+	NotificationTypeWebhook NotificationType = "Webhook"
+	// NotificationTypeSlack sends notifications to a Slack compatible incoming webhook.
+	NotificationTypeSlack NotificationType = "Slack"
+	// NotificationTypeEmail sends notifications by email via SMTP server configured in
+	// Workflow Controller.
+	NotificationTypeEmail NotificationType = "Email"
+)
+
+// NotificationItem describes a notification receiver and when to notify it. Body, text and
+// subject are Go templates executed with information of the WorkflowRun.
+type NotificationItem struct {
+	// Name of the notification.
+	Name string `json:"name"`
+	// Triggers are the status transitions to send notifications on.
+	Triggers []NotificationTrigger `json:"triggers"`
+	// Type of the receiver, Webhook, Slack or Email.
+	Type NotificationType `json:"type"`
+	// Webhook receiver, required for Webhook type.
+	// +optional
+	Webhook *WebhookReceiver `json:"webhook,omitempty"`
+	// Slack receiver, required for Slack type.
+	// +optional
+	Slack *SlackReceiver `json:"slack,omitempty"`
+	// Email receiver, required for Email type.
+	// +optional
+	Email *EmailReceiver `json:"email,omitempty"`
+}
+
+// WebhookReceiver is a generic HTTP webhook.
+type WebhookReceiver struct {
+	// URL of the webhook.
+	URL string `json:"url"`
+	// Method of the request, POST by default.
+	// +optional
+	Method string `json:"method,omitempty"`
+	// Headers of the request.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body template of the request, information of the WorkflowRun in JSON by default.
+	// +optional
+	Body string `json:"body,omitempty"`
+}
+
+// SlackReceiver is a Slack compatible incoming webhook.
+type SlackReceiver struct {
+	// URL of the incoming webhook.
+	URL string `json:"url"`
+	// Channel overrides the default channel of the incoming webhook.
+	// +optional
+	Channel string `json:"channel,omitempty"`
+	// Text template of the message.
+	// +optional
+	Text string `json:"text,omitempty"`
+}
+
+// EmailReceiver sends notifications by email.
+type EmailReceiver struct {
+	// To are the recipients.
+	To []string `json:"to"`
+	// Subject template of the email.
+	// +optional
+	Subject string `json:"subject,omitempty"`
+	// Body template of the email.
+	// +optional
+	Body string `json:"body,omitempty"`
+}
@@ -27,6 +27,10 @@ type ProjectSpec struct {
 	// Quota is the default quota of the workflow under it,
 	// eg map[core_v1.ResourceName]string{"requests.cpu": "2", "requests.memory": "4Gi"}
 	Quota map[core_v1.ResourceName]string `json:"quota"`
+
+	// Notifications are sent for WorkflowRuns of all workflows under the project.
+	// +optional
+	Notifications []NotificationItem `json:"notifications,omitempty"`
 }
 
 // IntegrationItem describes default value of a type of integrations
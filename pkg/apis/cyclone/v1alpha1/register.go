@@ -39,6 +39,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&Stage{},
 		&WorkflowTrigger{},
 		&Project{},
+		&ResourceList{},
+		&WorkflowList{},
+		&WorkflowRunList{},
+		&StageList{},
+		&WorkflowTriggerList{},
+		&ProjectList{},
 	)
 	// Add the watch version that applies
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -17,12 +17,18 @@ type Workflow struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 	// Workflow specification
 	Spec WorkflowSpec `json:"spec"`
+	// Status of the workflow, it's maintained by workflow controller.
+	// +optional
+	Status WorkflowStatus `json:"status,omitempty"`
 }
 
 // WorkflowSpec defines workflow specification.
 type WorkflowSpec struct {
 	Resources *corev1.ResourceRequirements
 	Stages    []StageItem `json:"stages"`
+	// Notifications are sent for WorkflowRuns of the workflow, besides those of the project.
+	// +optional
+	Notifications []NotificationItem `json:"notifications,omitempty"`
 }
 
 // StageItem describes a stage in a workflow.
@@ -35,6 +41,70 @@ type StageItem struct {
 	Depends []string `json:"depends"`
 }
 
+// WorkflowConditionType is type of workflow conditions.
+type WorkflowConditionType string
+
+const (
+	// WorkflowConditionValid indicates whether the workflow spec is valid, for example, stage
+	// names are unique and dependencies between stages have no cycle.
+	WorkflowConditionValid WorkflowConditionType = "Valid"
+	// WorkflowConditionStagesResolved indicates whether all stages referred in the workflow exist.
+	WorkflowConditionStagesResolved WorkflowConditionType = "StagesResolved"
+)
+
+// WorkflowCondition describes a condition of workflow.
+type WorkflowCondition struct {
+	// Type of the condition
+	Type WorkflowConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// The reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// A human readable message indicating details about the transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// WorkflowStatus describes health of a workflow, so that it can be seen without scanning its
+// WorkflowRuns.
+type WorkflowStatus struct {
+	// Conditions of the workflow
+	// +optional
+	Conditions []WorkflowCondition `json:"conditions,omitempty"`
+	// LastRun is the latest created WorkflowRun of the workflow.
+	// +optional
+	LastRun *WorkflowRunRecord `json:"lastRun,omitempty"`
+	// LastSuccessfulRun is the latest created WorkflowRun of the workflow that completed.
+	// +optional
+	LastSuccessfulRun *WorkflowRunRecord `json:"lastSuccessfulRun,omitempty"`
+	// RecentRuns is number of terminated WorkflowRuns in the recent window that success rate is
+	// calculated from.
+	// +optional
+	RecentRuns int `json:"recentRuns,omitempty"`
+	// SuccessRate is percentage of completed WorkflowRuns in the recent terminated ones, it's
+	// meaningless when RecentRuns is 0.
+	// +optional
+	SuccessRate int `json:"successRate,omitempty"`
+}
+
+// WorkflowRunRecord records a WorkflowRun in workflow status.
+type WorkflowRunRecord struct {
+	// Name of the WorkflowRun
+	Name string `json:"name"`
+	// Status of the WorkflowRun, such as Running, Completed
+	Status string `json:"status"`
+	// StartTime is the start time of the WorkflowRun.
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when the WorkflowRun terminated.
+	// +optional
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // WorkflowList describes an array of Workflow instances.
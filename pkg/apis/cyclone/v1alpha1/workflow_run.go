@@ -39,6 +39,48 @@ type WorkflowRunSpec struct {
 	Stages []ParameterConfig `json:"stages"`
 	// Execution context which specifies namespace and PVC used
 	ExecutionContext *ExecutionContext `json:"executionContext"`
+	// TriggeredBy records who or what started the WorkflowRun.
+	// +optional
+	TriggeredBy *TriggeredBy `json:"triggeredBy,omitempty"`
+}
+
+// Trigger types only used to describe provenance of WorkflowRuns, they are not types of WorkflowTrigger.
+const (
+	// TriggerTypeManual indicates the WorkflowRun is started by a user manually.
+	TriggerTypeManual TriggerType = "Manual"
+	// TriggerTypeRerun indicates the WorkflowRun is a rerun of another WorkflowRun.
+	TriggerTypeRerun TriggerType = "Rerun"
+)
+
+// TriggeredBy describes provenance of a WorkflowRun, only fields relevant to the type are set.
+type TriggeredBy struct {
+	// Type of the source that started the WorkflowRun
+	Type TriggerType `json:"type"`
+	// User who started the WorkflowRun manually
+	// +optional
+	User string `json:"user,omitempty"`
+	// Trigger is name of the WorkflowTrigger that started the WorkflowRun
+	// +optional
+	Trigger string `json:"trigger,omitempty"`
+	// ScheduleTime is the time the cron trigger was scheduled to fire
+	// +optional
+	ScheduleTime *metav1.Time `json:"scheduleTime,omitempty"`
+	// EventID is ID of the webhook event, for example, the delivery ID of a GitHub webhook
+	// +optional
+	EventID string `json:"eventId,omitempty"`
+	// Commit is the commit SHA the webhook event is about, or the revision found by the SCM
+	// poll trigger
+	// +optional
+	Commit string `json:"commit,omitempty"`
+	// ParentRun is name of the WorkflowRun that this one reruns
+	// +optional
+	ParentRun string `json:"parentRun,omitempty"`
+	// UpstreamRun is name of the WorkflowRun of the upstream workflow that fired the trigger
+	// +optional
+	UpstreamRun string `json:"upstreamRun,omitempty"`
+	// Image is the pushed image that fired the image trigger, for example, 'docker.io/library/golang:1.12'
+	// +optional
+	Image string `json:"image,omitempty"`
 }
 
 // ParameterConfig configures parameters of a resource or a stage.
@@ -68,6 +110,31 @@ type WorkflowRunStatus struct {
 	Overall Status `json:"overall"`
 	// Whether gc is performed on this WorkflowRun, such as deleting pods.
 	Cleaned bool `json:"cleaned"`
+	// Notified is the overall status that notifications have been sent for, it avoids sending
+	// notifications repeatedly on the same status.
+	// +optional
+	Notified string `json:"notified,omitempty"`
+	// Chained is the overall status that WorkflowRun triggers of downstream workflows have
+	// been fired for, it avoids starting downstream WorkflowRuns repeatedly on the same status.
+	// +optional
+	Chained string `json:"chained,omitempty"`
+	// CommitStatus records commit statuses reported to SCM, it's empty if commit statuses
+	// are not applicable, for example, no git resource or SCM integration found.
+	// +optional
+	CommitStatus *CommitStatus `json:"commitStatus,omitempty"`
+}
+
+// CommitStatus records commit statuses of a WorkflowRun reported to SCM.
+type CommitStatus struct {
+	// Repo is URL of the git repository.
+	// +optional
+	Repo string `json:"repo,omitempty"`
+	// SHA of the commit that statuses are reported for.
+	// +optional
+	SHA string `json:"sha,omitempty"`
+	// States reported, keyed by context of the statuses, such as 'cyclone/<workflow>/<stage>'.
+	// +optional
+	States map[string]string `json:"states,omitempty"`
 }
 
 // StageStatus describes status of a stage execution.
@@ -78,6 +145,93 @@ type StageStatus struct {
 	Status Status `json:"status"`
 	// Key-value outputs of this stage
 	Outputs []KeyValue `json:"outputs"`
+	// Logs describes why logs of this stage may be incomplete, it's nil if logs are
+	// collected by coordinator completely.
+	// +optional
+	Logs *LogsStatus `json:"logs,omitempty"`
+	// Diagnostics helps to figure out why the stage failed or got stuck.
+	// +optional
+	Diagnostics *StageDiagnostics `json:"diagnostics,omitempty"`
+	// Resources are metadata reported by resolvers of resources used in the stage, such as
+	// commit SHA of Git resources pulled and digest of images pushed.
+	// +optional
+	Resources []ResourceStatus `json:"resources,omitempty"`
+}
+
+// ResourceStatus describes a resource resolved in a stage.
+type ResourceStatus struct {
+	// Name of the resource.
+	Name string `json:"name"`
+	// Direction is Input if the resource is pulled, or Output if it's pushed.
+	Direction string `json:"direction"`
+	// Results reported by the resolver, for example, 'revision' for Git resources and 'digest'
+	// for Image resources.
+	// +optional
+	Results map[string]string `json:"results,omitempty"`
+}
+
+const (
+	// ResourceDirectionInput means the resource is an input resource pulled by resolver.
+	ResourceDirectionInput = "Input"
+	// ResourceDirectionOutput means the resource is an output resource pushed by resolver.
+	ResourceDirectionOutput = "Output"
+)
+
+// StageDiagnostics describes problems of a stage pod, such as scheduling failure and failed containers.
+type StageDiagnostics struct {
+	// Reason of the pod failure, for example, Evicted, DeadlineExceeded.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message of the pod failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Scheduling is the message of scheduling failure, for example, insufficient resources.
+	// +optional
+	Scheduling string `json:"scheduling,omitempty"`
+	// Containers are status of containers in the stage pod, init containers included.
+	// +optional
+	Containers []ContainerDiagnostics `json:"containers,omitempty"`
+}
+
+// ContainerDiagnostics describes status of a container in stage pod.
+type ContainerDiagnostics struct {
+	// Name of the container.
+	Name string `json:"name"`
+	// Init indicates whether it's an init container, such as input resource resolver.
+	// +optional
+	Init bool `json:"init,omitempty"`
+	// State of the container, Waiting, Running or Terminated.
+	State string `json:"state"`
+	// ExitCode of the container if it's terminated.
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// Reason of the state, for example, OOMKilled, Error, DeadlineExceeded, ErrImagePull.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message of the state.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastLines are the last lines of output of a failed container.
+	// +optional
+	LastLines []string `json:"lastLines,omitempty"`
+}
+
+const (
+	// ContainerStateWaiting means the container is waiting to start.
+	ContainerStateWaiting = "Waiting"
+	// ContainerStateRunning means the container is running.
+	ContainerStateRunning = "Running"
+	// ContainerStateTerminated means the container has terminated.
+	ContainerStateTerminated = "Terminated"
+)
+
+// LogsStatus describes logs of a stage that are not collected by coordinator completely.
+type LogsStatus struct {
+	// Reason why logs may be incomplete, for example, coordinator failed or pod deleted.
+	Reason string `json:"reason"`
+	// Message gives details, such as containers whose logs are recovered or lost.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 const (
@@ -125,6 +279,16 @@ type Status struct {
 
 	// StartTime is the start time of processing stage/workflowrun
 	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// RunningTime is the time when containers of the stage start running, for workflowrun, it's
+	// the running time of its first stage. Time between StartTime and RunningTime is spent queued,
+	// for example, waiting for the pod to be scheduled and images to be pulled.
+	// +optional
+	RunningTime metav1.Time `json:"runningTime,omitempty"`
+
+	// CompletionTime is the time when the stage/workflowrun terminated, that is Completed or Error.
+	// +optional
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -7,7 +7,8 @@ import (
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
-// WorkflowTrigger describes trigger of an workflow, time schedule and webhook supported.
+// WorkflowTrigger describes trigger of an workflow, time schedule, webhook, WorkflowRuns of
+// other workflows, image pushes and SCM polling supported.
 type WorkflowTrigger struct {
 	// Metadata for the resource, like kind and apiversion
 	metav1.TypeMeta `json:",inline"`
@@ -19,7 +20,8 @@ type WorkflowTrigger struct {
 	Status WorkflowTriggerStatus `json:"status"`
 }
 
-// TriggerType defines type of workflow trigger
+// TriggerType defines type of workflow trigger, it's also used to describe the source that
+// started a WorkflowRun.
 type TriggerType string
 
 const (
@@ -28,16 +30,34 @@ const (
 
 	// TriggerTypeWebhook indicates webhook trigger
 	TriggerTypeWebhook TriggerType = "Webhook"
+
+	// TriggerTypeWorkflowRun indicates trigger by WorkflowRuns of another workflow
+	TriggerTypeWorkflowRun TriggerType = "WorkflowRun"
+
+	// TriggerTypeImage indicates trigger by images pushed to registry
+	TriggerTypeImage TriggerType = "Image"
+
+	// TriggerTypeSCMPoll indicates trigger by polling head revision of a SCM repository
+	TriggerTypeSCMPoll TriggerType = "SCMPoll"
 )
 
 // WorkflowTriggerSpec defines workflow trigger definition.
 type WorkflowTriggerSpec struct {
-	// Type of this trigger, Cron or Webhook
+	// Type of this trigger, Cron, Webhook, WorkflowRun, Image or SCMPoll
 	Type TriggerType `json:"type"`
 	// Parameters of the trigger to run workflow
 	Parameters []ParameterItem `json:"parameters"`
 	// CronTrigger represents cron trigger config.
 	Cron CronTrigger `json:"cron,omitempty"`
+	// WorkflowRun represents config of trigger by WorkflowRuns of another workflow.
+	// +optional
+	WorkflowRun WorkflowRunTrigger `json:"workflowRun,omitempty"`
+	// Image represents config of trigger by images pushed to registry.
+	// +optional
+	Image ImageTrigger `json:"image,omitempty"`
+	// SCMPoll represents config of trigger by polling head revision of a SCM repository.
+	// +optional
+	SCMPoll SCMPollTrigger `json:"scmPoll,omitempty"`
 	// Whether this trigger is disabled, if set to true, no workflow will be triggered
 	Disabled bool `json:"disabled"`
 	// Spec to run the workflow
@@ -47,12 +67,158 @@ type WorkflowTriggerSpec struct {
 // CronTrigger represents the cron trigger policy.
 type CronTrigger struct {
 	Schedule string `json:"schedule"`
+	// TimeZone is the IANA time zone name the schedule is interpreted in, for example,
+	// 'America/New_York'. Default is the local time zone of workflow controller.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// ConcurrencyPolicy specifies how to treat concurrent WorkflowRuns started by the trigger,
+	// default is Allow.
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// StartingDeadlineSeconds is the deadline in seconds for starting a WorkflowRun if it misses
+	// the scheduled time for any reason, for example, workflow controller is down. Missed runs
+	// exceeding the deadline are skipped, no deadline if not set.
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+}
+
+// WorkflowRunTrigger starts a WorkflowRun when a WorkflowRun of the upstream workflow reaches
+// one of the given statuses, so that workflows can be chained.
+type WorkflowRunTrigger struct {
+	// Workflow is name of the upstream workflow in the same namespace.
+	Workflow string `json:"workflow"`
+	// Statuses of upstream WorkflowRuns to fire the trigger, default is Completed.
+	// +optional
+	Statuses []string `json:"statuses,omitempty"`
+	// Parameters filters upstream WorkflowRuns by their parameters, all of them should be
+	// matched to fire the trigger.
+	// +optional
+	Parameters []ParameterFilter `json:"parameters,omitempty"`
+	// Outputs passes outputs of upstream stages as parameters of the new WorkflowRun.
+	// +optional
+	Outputs []OutputParameter `json:"outputs,omitempty"`
+}
+
+// ParameterFilter matches a parameter of WorkflowRuns.
+type ParameterFilter struct {
+	// Stage the parameter belongs to, parameters of all stages are matched if empty.
+	// +optional
+	Stage string `json:"stage,omitempty"`
+	// Name of the parameter
+	Name string `json:"name"`
+	// Value of the parameter
+	Value string `json:"value"`
+}
+
+// OutputParameter passes an output of an upstream stage as a parameter of a stage in the new
+// WorkflowRun.
+type OutputParameter struct {
+	// Stage is the upstream stage whose output to pass.
+	Stage string `json:"stage"`
+	// Output is key of the output.
+	Output string `json:"output"`
+	// TargetStage is the stage in the new WorkflowRun to set parameter to.
+	TargetStage string `json:"targetStage"`
+	// Parameter is name of the parameter to set, default is key of the output.
+	// +optional
+	Parameter string `json:"parameter,omitempty"`
+}
+
+// ImageTrigger starts a WorkflowRun when a tag is pushed to a repository in the registry of a
+// DockerRegistry integration. Pushes are received from registry notifications, or found by
+// polling tags of the repository.
+type ImageTrigger struct {
+	// Integration is name of the DockerRegistry integration in the same tenant.
+	Integration string `json:"integration"`
+	// Repository is the image repository in the registry, for example, 'library/golang'.
+	Repository string `json:"repository"`
+	// Tag is a regular expression to match pushed tags, all tags are matched if empty.
+	// +optional
+	Tag string `json:"tag,omitempty"`
+	// PollIntervalSeconds is the interval in seconds to poll tags of the repository, polling
+	// is disabled if it's 0, and only registry notifications are received.
+	// +optional
+	PollIntervalSeconds int64 `json:"pollIntervalSeconds,omitempty"`
+	// Resource is name of the Image resource in the new WorkflowRun, its IMAGE and TAG parameters
+	// are set to the pushed image.
+	Resource string `json:"resource"`
 }
 
+// ImageTag is a tag of image repository.
+type ImageTag struct {
+	// Tag name
+	Tag string `json:"tag"`
+	// Digest of the image manifest the tag points to
+	Digest string `json:"digest"`
+}
+
+// SCMPollTrigger starts a WorkflowRun when head revision of a branch in the SCM repository
+// changes, it polls the repository with credentials of a SCM integration. It's used for
+// repositories that can't send webhooks to Cyclone.
+type SCMPollTrigger struct {
+	// Integration is name of the SCM integration in the same tenant.
+	Integration string `json:"integration"`
+	// Repository is URL of the repository, for example, 'https://github.com/caicloud/cyclone.git'
+	// for Git, or 'https://svn.example.com/repos/project' for SVN. Only http, https, ssh, svn and
+	// svn+ssh URLs are supported.
+	Repository string `json:"repository"`
+	// Branch to poll, it's the default branch for Git if empty. For SVN, it's the path in the
+	// repository, for example, 'trunk' or 'branches/v1.0'.
+	// +optional
+	Branch string `json:"branch,omitempty"`
+	// PollIntervalSeconds is the interval in seconds to poll the repository.
+	PollIntervalSeconds int64 `json:"pollIntervalSeconds"`
+	// Resource is name of the Git resource in the new WorkflowRun, its GIT_REVISION parameter is
+	// set to the commit SHA found by polling.
+	// +optional
+	Resource string `json:"resource,omitempty"`
+}
+
+// ConcurrencyPolicy describes how to treat concurrent WorkflowRuns of a cron trigger.
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent allows WorkflowRuns to run concurrently.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+
+	// ForbidConcurrent skips the new WorkflowRun if the previous one hasn't terminated yet.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+
+	// ReplaceConcurrent deletes the running WorkflowRuns and starts the new one.
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
 // WorkflowTriggerStatus describes status of a workflow trigger
 type WorkflowTriggerStatus struct {
-	// How many times this trigger got triggered
+	// How many WorkflowRuns this trigger has created
 	Count int `json:"count"`
+	// LastScheduleTime is the last time the cron trigger was scheduled to start a WorkflowRun,
+	// including the ones skipped by concurrency policy.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// NextScheduleTime is the next time the cron trigger is scheduled to fire, it's not set if
+	// the trigger is disabled.
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+	// LastFireTime is the last time a WorkflowRun was created by the trigger.
+	// +optional
+	LastFireTime *metav1.Time `json:"lastFireTime,omitempty"`
+	// LastWorkflowRun is name of the last WorkflowRun created by the trigger.
+	// +optional
+	LastWorkflowRun string `json:"lastWorkflowRun,omitempty"`
+	// LastError is the error of the last schedule, for example, invalid schedule or failure to
+	// create WorkflowRun. It's cleared once a WorkflowRun is created successfully.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// ImageTags are the latest observed tags matched by the image trigger, pushes of new tags
+	// or tags pointing to new digests fire the trigger. It's nil before tags are polled the
+	// first time.
+	// +optional
+	ImageTags []ImageTag `json:"imageTags"`
+	// LastRevision is the latest observed head revision of the branch polled by the SCM poll
+	// trigger, changes of it fire the trigger.
+	// +optional
+	LastRevision string `json:"lastRevision,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
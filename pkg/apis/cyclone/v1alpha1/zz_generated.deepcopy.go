@@ -61,9 +61,63 @@ func (in *ArtifactItem) DeepCopy() *ArtifactItem {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommitStatus) DeepCopyInto(out *CommitStatus) {
+	*out = *in
+	if in.States != nil {
+		in, out := &in.States, &out.States
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommitStatus.
+func (in *CommitStatus) DeepCopy() *CommitStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CommitStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerDiagnostics) DeepCopyInto(out *ContainerDiagnostics) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastLines != nil {
+		in, out := &in.LastLines, &out.LastLines
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerDiagnostics.
+func (in *ContainerDiagnostics) DeepCopy() *ContainerDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CronTrigger) DeepCopyInto(out *CronTrigger) {
 	*out = *in
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -77,6 +131,27 @@ func (in *CronTrigger) DeepCopy() *CronTrigger {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailReceiver) DeepCopyInto(out *EmailReceiver) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReceiver.
+func (in *EmailReceiver) DeepCopy() *EmailReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecutionContext) DeepCopyInto(out *ExecutionContext) {
 	*out = *in
@@ -93,6 +168,38 @@ func (in *ExecutionContext) DeepCopy() *ExecutionContext {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageTag) DeepCopyInto(out *ImageTag) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageTag.
+func (in *ImageTag) DeepCopy() *ImageTag {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageTag)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageTrigger) DeepCopyInto(out *ImageTrigger) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageTrigger.
+func (in *ImageTrigger) DeepCopy() *ImageTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Inputs) DeepCopyInto(out *Inputs) {
 	*out = *in
@@ -156,6 +263,74 @@ func (in *KeyValue) DeepCopy() *KeyValue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogsStatus) DeepCopyInto(out *LogsStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogsStatus.
+func (in *LogsStatus) DeepCopy() *LogsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationItem) DeepCopyInto(out *NotificationItem) {
+	*out = *in
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]NotificationTrigger, len(*in))
+		copy(*out, *in)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookReceiver)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackReceiver)
+		**out = **in
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		*out = new(EmailReceiver)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationItem.
+func (in *NotificationItem) DeepCopy() *NotificationItem {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputParameter) DeepCopyInto(out *OutputParameter) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputParameter.
+func (in *OutputParameter) DeepCopy() *OutputParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Outputs) DeepCopyInto(out *Outputs) {
 	*out = *in
@@ -203,6 +378,22 @@ func (in *ParameterConfig) DeepCopy() *ParameterConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterFilter) DeepCopyInto(out *ParameterFilter) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterFilter.
+func (in *ParameterFilter) DeepCopy() *ParameterFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ParameterItem) DeepCopyInto(out *ParameterItem) {
 	*out = *in
@@ -345,6 +536,13 @@ func (in *ProjectSpec) DeepCopyInto(out *ProjectSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]NotificationItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -460,6 +658,61 @@ func (in *ResourceSpec) DeepCopy() *ResourceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceStatus) DeepCopyInto(out *ResourceStatus) {
+	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceStatus.
+func (in *ResourceStatus) DeepCopy() *ResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SCMPollTrigger) DeepCopyInto(out *SCMPollTrigger) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMPollTrigger.
+func (in *SCMPollTrigger) DeepCopy() *SCMPollTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(SCMPollTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackReceiver) DeepCopyInto(out *SlackReceiver) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiver.
+func (in *SlackReceiver) DeepCopy() *SlackReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Stage) DeepCopyInto(out *Stage) {
 	*out = *in
@@ -487,6 +740,29 @@ func (in *Stage) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageDiagnostics) DeepCopyInto(out *StageDiagnostics) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerDiagnostics, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageDiagnostics.
+func (in *StageDiagnostics) DeepCopy() *StageDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(StageDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StageItem) DeepCopyInto(out *StageItem) {
 	*out = *in
@@ -581,6 +857,23 @@ func (in *StageStatus) DeepCopyInto(out *StageStatus) {
 		*out = make([]KeyValue, len(*in))
 		copy(*out, *in)
 	}
+	if in.Logs != nil {
+		in, out := &in.Logs, &out.Logs
+		*out = new(LogsStatus)
+		**out = **in
+	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(StageDiagnostics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -599,6 +892,8 @@ func (in *Status) DeepCopyInto(out *Status) {
 	*out = *in
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.RunningTime.DeepCopyInto(&out.RunningTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
 	return
 }
 
@@ -612,12 +907,56 @@ func (in *Status) DeepCopy() *Status {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggeredBy) DeepCopyInto(out *TriggeredBy) {
+	*out = *in
+	if in.ScheduleTime != nil {
+		in, out := &in.ScheduleTime, &out.ScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggeredBy.
+func (in *TriggeredBy) DeepCopy() *TriggeredBy {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggeredBy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookReceiver) DeepCopyInto(out *WebhookReceiver) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReceiver.
+func (in *WebhookReceiver) DeepCopy() *WebhookReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Workflow) DeepCopyInto(out *Workflow) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -639,6 +978,23 @@ func (in *Workflow) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowCondition) DeepCopyInto(out *WorkflowCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowCondition.
+func (in *WorkflowCondition) DeepCopy() *WorkflowCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowList) DeepCopyInto(out *WorkflowList) {
 	*out = *in
@@ -733,6 +1089,24 @@ func (in *WorkflowRunList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowRunRecord) DeepCopyInto(out *WorkflowRunRecord) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowRunRecord.
+func (in *WorkflowRunRecord) DeepCopy() *WorkflowRunRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowRunRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowRunSpec) DeepCopyInto(out *WorkflowRunSpec) {
 	*out = *in
@@ -770,6 +1144,11 @@ func (in *WorkflowRunSpec) DeepCopyInto(out *WorkflowRunSpec) {
 		*out = new(ExecutionContext)
 		**out = **in
 	}
+	if in.TriggeredBy != nil {
+		in, out := &in.TriggeredBy, &out.TriggeredBy
+		*out = new(TriggeredBy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -802,6 +1181,11 @@ func (in *WorkflowRunStatus) DeepCopyInto(out *WorkflowRunStatus) {
 		}
 	}
 	in.Overall.DeepCopyInto(&out.Overall)
+	if in.CommitStatus != nil {
+		in, out := &in.CommitStatus, &out.CommitStatus
+		*out = new(CommitStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -815,6 +1199,37 @@ func (in *WorkflowRunStatus) DeepCopy() *WorkflowRunStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowRunTrigger) DeepCopyInto(out *WorkflowRunTrigger) {
+	*out = *in
+	if in.Statuses != nil {
+		in, out := &in.Statuses, &out.Statuses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]ParameterFilter, len(*in))
+		copy(*out, *in)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]OutputParameter, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowRunTrigger.
+func (in *WorkflowRunTrigger) DeepCopy() *WorkflowRunTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowRunTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowSpec) DeepCopyInto(out *WorkflowSpec) {
 	*out = *in
@@ -830,6 +1245,13 @@ func (in *WorkflowSpec) DeepCopyInto(out *WorkflowSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]NotificationItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -843,13 +1265,46 @@ func (in *WorkflowSpec) DeepCopy() *WorkflowSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowStatus) DeepCopyInto(out *WorkflowStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]WorkflowCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRun != nil {
+		in, out := &in.LastRun, &out.LastRun
+		*out = new(WorkflowRunRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSuccessfulRun != nil {
+		in, out := &in.LastSuccessfulRun, &out.LastSuccessfulRun
+		*out = new(WorkflowRunRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowStatus.
+func (in *WorkflowStatus) DeepCopy() *WorkflowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowTrigger) DeepCopyInto(out *WorkflowTrigger) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -912,7 +1367,10 @@ func (in *WorkflowTriggerSpec) DeepCopyInto(out *WorkflowTriggerSpec) {
 		*out = make([]ParameterItem, len(*in))
 		copy(*out, *in)
 	}
-	out.Cron = in.Cron
+	in.Cron.DeepCopyInto(&out.Cron)
+	in.WorkflowRun.DeepCopyInto(&out.WorkflowRun)
+	out.Image = in.Image
+	out.SCMPoll = in.SCMPoll
 	in.WorkflowRunSpec.DeepCopyInto(&out.WorkflowRunSpec)
 	return
 }
@@ -930,6 +1388,23 @@ func (in *WorkflowTriggerSpec) DeepCopy() *WorkflowTriggerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowTriggerStatus) DeepCopyInto(out *WorkflowTriggerStatus) {
 	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFireTime != nil {
+		in, out := &in.LastFireTime, &out.LastFireTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ImageTags != nil {
+		in, out := &in.ImageTags, &out.ImageTags
+		*out = make([]ImageTag, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
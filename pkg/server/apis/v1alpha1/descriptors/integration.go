@@ -2,13 +2,20 @@ package descriptors
 
 import (
 	"github.com/caicloud/nirvana/definition"
+	"github.com/caicloud/nirvana/service"
 
+	"github.com/caicloud/cyclone/pkg/server/biz/registry"
 	handler "github.com/caicloud/cyclone/pkg/server/handler/v1alpha1"
 	httputil "github.com/caicloud/cyclone/pkg/util/http"
 )
 
 func init() {
 	register(integration...)
+
+	// Docker Registry v2 notifications are sent with their own content type.
+	if err := service.RegisterConsumer(&registry.DockerEventsConsumer{}); err != nil {
+		panic(err)
+	}
 }
 
 var integration = []definition.Descriptor{
@@ -117,4 +124,38 @@ var integration = []definition.Descriptor{
 			},
 		},
 	},
+	{
+		Path:        "/tenants/{tenant}/integrations/{integration}/registrynotifications",
+		Description: "Registry notification APIs",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Create,
+				Function:    handler.ReceiveRegistryNotification,
+				Description: "Receive notifications from the registry of DockerRegistry integration to fire image triggers",
+				Consumes:    []string{definition.MIMEJSON, registry.DockerEventsMIME},
+				Parameters: []definition.Parameter{
+					{
+						Source:      definition.Path,
+						Name:        httputil.TenantNamePathParameterName,
+						Description: "Name of the tenant",
+					},
+					{
+						Source:      definition.Path,
+						Name:        "integration",
+						Description: "Name of the DockerRegistry integration",
+					},
+					{
+						Source:      definition.Header,
+						Name:        httputil.AuthorizationHeaderName,
+						Description: "notification token of the integration, as 'Bearer <token>' or the token itself",
+					},
+					{
+						Source:      definition.Body,
+						Description: "Docker Registry v2 notification or Harbor webhook",
+					},
+				},
+				Results: definition.DataErrorResults("names of started workflowruns"),
+			},
+		},
+	},
 }
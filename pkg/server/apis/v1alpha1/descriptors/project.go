@@ -117,4 +117,74 @@ var project = []definition.Descriptor{
 			},
 		},
 	},
+	{
+		Path:        "/projects/{project}/stats",
+		Description: "Projects APIs",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Get,
+				Function:    handler.GetProjectStats,
+				Description: "Get statistics of workflowruns of all workflows in the project",
+				Parameters: []definition.Parameter{
+					{
+						Source:      definition.Path,
+						Name:        "project",
+						Description: "Name of the project",
+					},
+					{
+						Source:      definition.Header,
+						Name:        httputil.TenantHeaderName,
+						Description: "Name of the tenant whose project to get statistics",
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.StartTimeQueryParameter,
+						Default:     int64(0),
+						Description: "Start of the time window in unix seconds, workflowruns started before it are excluded",
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.EndTimeQueryParameter,
+						Default:     int64(0),
+						Description: "End of the time window in unix seconds, 0 means now",
+					},
+				},
+				Results: definition.DataErrorResults("statistics of workflowruns"),
+			},
+		},
+	},
+	{
+		Path:        "/projects/{project}/summaries",
+		Description: "Projects APIs",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Get,
+				Function:    handler.ListProjectWorkflowRunSummaries,
+				Description: "List summaries of workflowruns of all workflows in the project, including pruned ones",
+				Parameters: []definition.Parameter{
+					{
+						Source:      definition.Path,
+						Name:        "project",
+						Description: "Name of the project",
+					},
+					{
+						Source:      definition.Header,
+						Name:        httputil.TenantHeaderName,
+						Description: "Name of the tenant whose project to list summaries",
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.StatusQueryParameter,
+						Description: "Status of workflowruns to list, all are listed if not set",
+					},
+					{
+						Source:      definition.Auto,
+						Name:        httputil.PaginationAutoParameter,
+						Description: "pagination",
+					},
+				},
+				Results: definition.DataErrorResults("workflowrun summaries"),
+			},
+		},
+	},
 }
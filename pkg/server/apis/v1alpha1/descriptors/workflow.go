@@ -145,4 +145,42 @@ var workflow = []definition.Descriptor{
 			},
 		},
 	},
+	{
+		Path:        "/projects/{project}/workflows/{workflow}/stats",
+		Description: "workflow APIs",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Get,
+				Function:    handler.GetWorkflowStats,
+				Description: "Get statistics of workflowruns of the workflow",
+				Parameters: []definition.Parameter{
+					{
+						Source: definition.Path,
+						Name:   httputil.ProjectNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowNamePathParameterName,
+					},
+					{
+						Source: definition.Header,
+						Name:   httputil.TenantHeaderName,
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.StartTimeQueryParameter,
+						Default:     int64(0),
+						Description: "Start of the time window in unix seconds, workflowruns started before it are excluded",
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.EndTimeQueryParameter,
+						Default:     int64(0),
+						Description: "End of the time window in unix seconds, 0 means now",
+					},
+				},
+				Results: definition.DataErrorResults("statistics of workflowruns"),
+			},
+		},
+	},
 }
@@ -50,6 +50,11 @@ var workflowrun = []definition.Descriptor{
 						Source: definition.Header,
 						Name:   httputil.TenantHeaderName,
 					},
+					{
+						Source:      definition.Header,
+						Name:        httputil.UserHeaderName,
+						Description: "user who starts the workflowrun",
+					},
 					{
 						Source:      definition.Body,
 						Description: "JSON body to describe the new workflowrun",
@@ -74,6 +79,16 @@ var workflowrun = []definition.Descriptor{
 						Source: definition.Header,
 						Name:   httputil.TenantHeaderName,
 					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.TriggerTypeQueryParameter,
+						Description: "type of the source that started workflowruns, Manual, Cron, Webhook, WorkflowRun, Image, SCMPoll or Rerun",
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.TriggerQueryParameter,
+						Description: "workflowtrigger that started workflowruns",
+					},
 					{
 						Source:      definition.Auto,
 						Name:        httputil.PaginationAutoParameter,
@@ -297,6 +312,11 @@ var workflowrun = []definition.Descriptor{
 						Source: definition.Query,
 						Name:   httputil.ContainerNameQueryParameter,
 					},
+					{
+						Source:      definition.Auto,
+						Name:        httputil.LogQueryAutoParameter,
+						Description: "lines to start the stream from, supports start, tail and since",
+					},
 				},
 				Results: []definition.Result{definition.ErrorResult()},
 			},
@@ -339,6 +359,23 @@ var workflowrun = []definition.Descriptor{
 						Name:      httputil.DownloadQueryParameter,
 						Operators: []definition.Operator{validator.Bool("")},
 					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.OffsetQueryParameter,
+						Default:     int64(0),
+						Description: "Byte offset to read log from",
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.LengthQueryParameter,
+						Default:     int64(0),
+						Description: "Number of bytes to read, 0 means reading to the end",
+					},
+					{
+						Source:      definition.Auto,
+						Name:        httputil.LogQueryAutoParameter,
+						Description: "lines to get, supports start, limit, tail and since",
+					},
 				},
 				Results: []definition.Result{
 					{
@@ -355,4 +392,246 @@ var workflowrun = []definition.Descriptor{
 			},
 		},
 	},
+	{
+		Path: "/projects/{project}/workflows/{workflow}/workflowruns/{workflowrun}/logs/search",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Get,
+				Function:    handler.SearchWorkflowRunLogs,
+				Description: "Search logs of workflowrun",
+				Parameters: []definition.Parameter{
+					{
+						Source: definition.Path,
+						Name:   httputil.ProjectNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowRunNamePathParameterName,
+					},
+					{
+						Source: definition.Header,
+						Name:   httputil.TenantHeaderName,
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.StageNameQueryParameter,
+						Description: "stage to search, all stages are searched if not set",
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.KeywordQueryParameter,
+						Description: "keyword to search",
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.RegexQueryParameter,
+						Default:     false,
+						Description: "whether the keyword is a regular expression",
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.LimitQueryParameter,
+						Default:     1000,
+						Description: "maximum number of matched lines to return",
+					},
+				},
+				Results: definition.DataErrorResults("matched log lines"),
+			},
+		},
+	},
+	{
+		Path: "/projects/{project}/workflows/{workflow}/workflowruns/{workflowrun}/logs/merged",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Get,
+				Function:    handler.GetStageLogs,
+				Description: "Get logs of all containers of a stage merged in time order",
+				Parameters: []definition.Parameter{
+					{
+						Source: definition.Path,
+						Name:   httputil.ProjectNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowRunNamePathParameterName,
+					},
+					{
+						Source: definition.Header,
+						Name:   httputil.TenantHeaderName,
+					},
+					{
+						Source: definition.Query,
+						Name:   httputil.StageNameQueryParameter,
+					},
+					{
+						Source:      definition.Auto,
+						Name:        httputil.LogQueryAutoParameter,
+						Description: "lines to get, supports start, limit, tail and since",
+					},
+				},
+				Results: definition.DataErrorResults("merged log lines"),
+			},
+		},
+	},
+	{
+		Path: "/projects/{project}/workflows/{workflow}/workflowruns/{workflowrun}/durations",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Get,
+				Function:    handler.GetWorkflowRunDurations,
+				Description: "Get durations of workflowrun and its stages",
+				Parameters: []definition.Parameter{
+					{
+						Source: definition.Path,
+						Name:   httputil.ProjectNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowRunNamePathParameterName,
+					},
+					{
+						Source: definition.Header,
+						Name:   httputil.TenantHeaderName,
+					},
+				},
+				Results: definition.DataErrorResults("durations of workflowrun"),
+			},
+		},
+	},
+	{
+		Path: "/projects/{project}/workflows/{workflow}/workflowruns/{workflowrun}/logs/status",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Get,
+				Function:    handler.ListWorkflowRunLogsStatus,
+				Description: "List status of logs of workflowrun",
+				Parameters: []definition.Parameter{
+					{
+						Source: definition.Path,
+						Name:   httputil.ProjectNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowRunNamePathParameterName,
+					},
+					{
+						Source: definition.Header,
+						Name:   httputil.TenantHeaderName,
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.StageNameQueryParameter,
+						Description: "stage to list, all stages are listed if not set",
+					},
+				},
+				Results: definition.DataErrorResults("status of logs"),
+			},
+		},
+	},
+	{
+		Path:        "/projects/{project}/workflows/{workflow}/summaries",
+		Description: "workflowrun summary APIs",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Create,
+				Function:    handler.CreateWorkflowRunSummary,
+				Description: "Archive summary of workflowrun",
+				Parameters: []definition.Parameter{
+					{
+						Source: definition.Path,
+						Name:   httputil.ProjectNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowNamePathParameterName,
+					},
+					{
+						Source: definition.Header,
+						Name:   httputil.TenantHeaderName,
+					},
+					{
+						Source:      definition.Body,
+						Description: "JSON body to describe the workflowrun summary",
+					},
+				},
+				Results: definition.DataErrorResults("workflowrun summary"),
+			},
+			{
+				Method:      definition.Get,
+				Function:    handler.ListWorkflowRunSummaries,
+				Description: "List summaries of workflowruns, including pruned ones",
+				Parameters: []definition.Parameter{
+					{
+						Source: definition.Path,
+						Name:   httputil.ProjectNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowNamePathParameterName,
+					},
+					{
+						Source: definition.Header,
+						Name:   httputil.TenantHeaderName,
+					},
+					{
+						Source:      definition.Query,
+						Name:        httputil.StatusQueryParameter,
+						Description: "status of workflowruns to list, all are listed if not set",
+					},
+					{
+						Source:      definition.Auto,
+						Name:        httputil.PaginationAutoParameter,
+						Description: "pagination",
+					},
+				},
+				Results: definition.DataErrorResults("workflowrun summaries"),
+			},
+		},
+	},
+	{
+		Path:        "/projects/{project}/workflows/{workflow}/summaries/{workflowrun}",
+		Description: "workflowrun summary APIs",
+		Definitions: []definition.Definition{
+			{
+				Method:      definition.Get,
+				Function:    handler.GetWorkflowRunSummary,
+				Description: "Get summary of workflowrun",
+				Parameters: []definition.Parameter{
+					{
+						Source: definition.Path,
+						Name:   httputil.ProjectNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowNamePathParameterName,
+					},
+					{
+						Source: definition.Path,
+						Name:   httputil.WorkflowRunNamePathParameterName,
+					},
+					{
+						Source: definition.Header,
+						Name:   httputil.TenantHeaderName,
+					},
+				},
+				Results: definition.DataErrorResults("workflowrun summary"),
+			},
+		},
+	},
 }
@@ -1,9 +1,13 @@
 package v1alpha1
 
 import (
+	"time"
+
 	core_v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	cmd_api "k8s.io/client-go/tools/clientcmd/api"
+
+	cyclone_v1alpha1 "github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
 )
 
 // Tenant contains information about tenant
@@ -115,6 +119,10 @@ type DockerRegistrySource struct {
 	User string `json:"user"`
 	// Password is the password of the corresponding user.
 	Password string `json:"password"`
+	// NotificationToken is the token that notifications from the registry should carry in the
+	// Authorization header, as 'Bearer <token>' or the token itself. Notifications are rejected
+	// if it's not set.
+	NotificationToken string `json:"notificationToken,omitempty"`
 }
 
 // SCMType defines the type of Source Code Management
@@ -141,7 +149,10 @@ type SCMSource struct {
 	Password string `json:"password"`
 	// Token is the credential to access SCM.
 	Token string `json:"token"`
-	// AuthType is the type of auth way, can be Token or Password
+	// SSHKey is the private key to access SCM with SSH. Git resources can refer it in the
+	// integration secret for their GIT_SSH_KEY parameters.
+	SSHKey string `json:"sshKey,omitempty"`
+	// AuthType is the type of auth way, can be Token, Password or SSH
 	AuthType SCMAuthType `json:"authType"`
 }
 
@@ -153,6 +164,8 @@ const (
 	AuthTypePassword SCMAuthType = "Password"
 	// AuthTypeToken represents using token to auth
 	AuthTypeToken = "Token"
+	// AuthTypeSSH represents using SSH key to auth
+	AuthTypeSSH = "SSH"
 )
 
 // ClusterSource contains info about clusters.
@@ -203,3 +216,161 @@ type TLSClientConfig struct {
 	// CAData takes precedence over CAFile
 	CAData []byte `json:"caData,omitempty" bson:"caData"`
 }
+
+// LogLine is a line of container logs.
+type LogLine struct {
+	// Stage is name of the stage the log belongs to.
+	Stage string `json:"stage"`
+	// Container is name of the container the log belongs to.
+	Container string `json:"container"`
+	// LineNumber is number of the line in the container log, starting from 1.
+	LineNumber int `json:"lineNumber"`
+	// Time is when the line is logged, it's nil if the log has no timestamps.
+	Time *time.Time `json:"time,omitempty"`
+	// Stream is the stream the line is logged to, stdout or stderr.
+	Stream LogStream `json:"stream,omitempty"`
+	// Line is content of the line.
+	Line string `json:"line"`
+}
+
+// LogStream is the output stream of container logs.
+type LogStream string
+
+const (
+	// LogStreamStdout represents the standard output.
+	LogStreamStdout LogStream = "stdout"
+	// LogStreamStderr represents the standard error.
+	LogStreamStderr LogStream = "stderr"
+	// LogStreamCombined represents standard output and error combined, it's used when
+	// the container runtime can't tell them apart.
+	LogStreamCombined LogStream = "combined"
+)
+
+// LogRecord is a framed line of container logs, log collectors push logs to Cyclone server
+// as records.
+type LogRecord struct {
+	// Time is when the line is logged.
+	Time time.Time `json:"time"`
+	// Container is name of the container.
+	Container string `json:"container"`
+	// Stream is the stream the line is logged to.
+	Stream LogStream `json:"stream"`
+	// Line is content of the line without trailing newline.
+	Line string `json:"line"`
+}
+
+// LogState is state of a container log.
+type LogState string
+
+const (
+	// LogStateWriting means the log is being pushed.
+	LogStateWriting LogState = "Writing"
+	// LogStateCompleted means the log has been pushed completely.
+	LogStateCompleted LogState = "Completed"
+	// LogStateIncomplete means pushing of the log broke off, it can be replaced by pushing
+	// the log again.
+	LogStateIncomplete LogState = "Incomplete"
+)
+
+// LogStatus describes status of a container log.
+type LogStatus struct {
+	// Stage is name of the stage the log belongs to.
+	Stage string `json:"stage"`
+	// Container is name of the container the log belongs to.
+	Container string `json:"container"`
+	// State is state of the log.
+	State LogState `json:"state"`
+	// Size is size of the log in bytes.
+	Size int64 `json:"size"`
+}
+
+// Durations describes how long a stage or workflowrun has been queued and running, in seconds.
+// For those not terminated yet, durations are counted up to now.
+type Durations struct {
+	// Queued is the time spent before containers start running, such as waiting for the pod to
+	// be scheduled and images to be pulled.
+	Queued int64 `json:"queued"`
+	// Running is the time spent running containers.
+	Running int64 `json:"running"`
+	// Total is the sum of queued and running time.
+	Total int64 `json:"total"`
+	// Terminated tells whether the stage or workflowrun has terminated.
+	Terminated bool `json:"terminated"`
+}
+
+// WorkflowRunDurations describes durations of a workflowrun and its stages.
+type WorkflowRunDurations struct {
+	Durations
+	// Stages are durations of stages, keyed by stage name.
+	Stages map[string]Durations `json:"stages"`
+}
+
+// WorkflowRunStats describes statistics of workflowruns of a workflow or project in a time window,
+// durations are in seconds.
+type WorkflowRunStats struct {
+	// Total is the number of workflowruns.
+	Total int `json:"total"`
+	// Statuses are numbers of workflowruns by status.
+	Statuses map[string]int `json:"statuses"`
+	// SuccessRate is percentage of completed workflowruns in terminated ones.
+	SuccessRate int `json:"successRate"`
+	// MedianDuration is the median duration of terminated workflowruns.
+	MedianDuration int64 `json:"medianDuration"`
+	// P95Duration is the 95th percentile duration of terminated workflowruns.
+	P95Duration int64 `json:"p95Duration"`
+	// SlowestStages are stages with the longest average durations, slowest first.
+	SlowestStages []StageStats `json:"slowestStages"`
+	// MostFailedStage is the stage failed most frequently, it's empty if no stage failed.
+	MostFailedStage *StageStats `json:"mostFailedStage,omitempty"`
+}
+
+// StageStats describes statistics of a stage in workflowruns.
+type StageStats struct {
+	// Workflow of the stage
+	Workflow string `json:"workflow"`
+	// Stage name
+	Stage string `json:"stage"`
+	// Runs is the number of times the stage terminated.
+	Runs int `json:"runs"`
+	// Failures is the number of times the stage failed.
+	Failures int `json:"failures"`
+	// AverageDuration is the average duration of the stage.
+	AverageDuration int64 `json:"averageDuration"`
+}
+
+// WorkflowRunSummary is a compact record of a workflowrun, it's archived before the workflowrun
+// is pruned, so that an audit trail is kept after the workflowrun is deleted.
+type WorkflowRunSummary struct {
+	// Name of the workflowrun
+	Name string `json:"name"`
+	// Project of the workflowrun
+	Project string `json:"project"`
+	// Workflow of the workflowrun
+	Workflow string `json:"workflow"`
+	// TriggeredBy records who or what started the workflowrun.
+	TriggeredBy *cyclone_v1alpha1.TriggeredBy `json:"triggeredBy,omitempty"`
+	// CreationTime is the time when the workflowrun is created.
+	CreationTime meta_v1.Time `json:"creationTime"`
+	// Resources are resource parameters of the workflowrun.
+	Resources []cyclone_v1alpha1.ParameterConfig `json:"resources,omitempty"`
+	// Parameters are stage parameters of the workflowrun.
+	Parameters []cyclone_v1alpha1.ParameterConfig `json:"parameters,omitempty"`
+	// PVC is the PVC where artifacts of the workflowrun are stored.
+	PVC string `json:"pvc,omitempty"`
+	// Status is the overall status of the workflowrun, including timings.
+	Status cyclone_v1alpha1.Status `json:"status"`
+	// Stages are summaries of stages, keyed by stage name.
+	Stages map[string]StageSummary `json:"stages,omitempty"`
+}
+
+// StageSummary is a compact record of a stage in workflowrun.
+type StageSummary struct {
+	// Status of the stage, including timings.
+	Status cyclone_v1alpha1.Status `json:"status"`
+	// Outputs are key-value outputs of the stage.
+	Outputs []cyclone_v1alpha1.KeyValue `json:"outputs,omitempty"`
+	// Artifacts are paths of output artifacts of the stage in the PVC.
+	Artifacts []string `json:"artifacts,omitempty"`
+	// Logs are API paths to get logs of containers of the stage.
+	Logs []string `json:"logs,omitempty"`
+}
@@ -0,0 +1,266 @@
+package logstore
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+// maxTimestampLength is the maximum length of a RFC3339Nano timestamp.
+const maxTimestampLength = len("2006-01-02T15:04:05.999999999-07:00")
+
+// LineQuery selects lines of a log. Since is applied first, then Tail or Start and Limit
+// are applied to the remaining lines.
+type LineQuery struct {
+	// Start is the number of lines to skip.
+	Start int
+	// Limit is the maximum number of lines to return, 0 means no limit.
+	Limit int
+	// Tail returns only the last Tail lines if it's positive, Start is ignored in this case.
+	Tail int
+	// Since returns only lines with timestamps not before it if it's not zero. Lines without
+	// timestamp follow the line before them, and leading lines without timestamp are dropped.
+	Since time.Time
+	// Timestamps indicates whether to keep time and stream of lines stored from records.
+	Timestamps bool
+}
+
+// SelectsAll checks whether the query selects all lines.
+func (q LineQuery) SelectsAll() bool {
+	return q.Start <= 0 && q.Limit <= 0 && q.Tail <= 0 && q.Since.IsZero()
+}
+
+// Output returns the line to output according to the query.
+func (q LineQuery) Output(line []byte) []byte {
+	if q.Timestamps {
+		return line
+	}
+	return StripLine(line)
+}
+
+// FilterLines returns a reader of lines selected by the query, r would be closed once all
+// lines are read, reading fails or the returned reader is closed, so that r is released even
+// if the caller only reads to the end without closing.
+func FilterLines(r io.ReadCloser, q LineQuery) io.ReadCloser {
+	if q.SelectsAll() && q.Timestamps {
+		return &closingReader{reader: r, source: r}
+	}
+
+	pr, pw := io.Pipe()
+	cr := &closingReader{reader: pr, source: r}
+	go func() {
+		defer cr.closeSource()
+		pw.CloseWithError(q.filter(r, pw))
+	}()
+	return cr
+}
+
+// closingReader reads from reader, and closes source once reading ends or it's closed.
+type closingReader struct {
+	reader io.Reader
+	source io.Closer
+	once   sync.Once
+	err    error
+}
+
+// Read reads from the reader, the source is closed at EOF or on error.
+func (c *closingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	if err != nil {
+		c.closeSource()
+	}
+	return n, err
+}
+
+// Close closes the reader and the source, closing the source also stops filtering.
+func (c *closingReader) Close() error {
+	if closer, ok := c.reader.(io.Closer); ok && closer != c.source {
+		closer.Close()
+	}
+	return c.closeSource()
+}
+
+func (c *closingReader) closeSource() error {
+	c.once.Do(func() {
+		c.err = c.source.Close()
+	})
+	return c.err
+}
+
+func (q LineQuery) filter(r io.Reader, w io.Writer) error {
+	var tail [][]byte
+	selected := 0
+	included := q.Since.IsZero()
+	err := eachLine(r, func(_ int, line []byte) (bool, error) {
+		if !q.Since.IsZero() {
+			if t, ok := ParseTimestamp(line); ok {
+				included = !t.Before(q.Since)
+			}
+		}
+		if !included {
+			return true, nil
+		}
+
+		// Keep the last lines in a sliding window, they are written after all lines are read.
+		if q.Tail > 0 {
+			if len(tail) == q.Tail {
+				tail = tail[1:]
+			}
+			tail = append(tail, append([]byte(nil), line...))
+			return true, nil
+		}
+
+		selected++
+		if selected <= q.Start {
+			return true, nil
+		}
+		if _, err := w.Write(q.Output(line)); err != nil {
+			return false, err
+		}
+		return q.Limit <= 0 || selected-q.Start < q.Limit, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if q.Limit > 0 && len(tail) > q.Limit {
+		tail = tail[:q.Limit]
+	}
+	for _, line := range tail {
+		if _, err := w.Write(q.Output(line)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Offset gets the byte offset of the first line selected by the query in r, Limit is ignored.
+// The length of r is returned if no line is selected.
+func (q LineQuery) Offset(r io.Reader) (int64, error) {
+	var offset int64
+	var tail []int64
+	selected := 0
+	found := int64(-1)
+	included := q.Since.IsZero()
+	err := eachLine(r, func(_ int, line []byte) (bool, error) {
+		start := offset
+		offset += int64(len(line))
+		if !q.Since.IsZero() {
+			if t, ok := ParseTimestamp(line); ok {
+				included = !t.Before(q.Since)
+			}
+		}
+		if !included {
+			return true, nil
+		}
+
+		if q.Tail > 0 {
+			if len(tail) == q.Tail {
+				tail = tail[1:]
+			}
+			tail = append(tail, start)
+			return true, nil
+		}
+
+		selected++
+		if selected <= q.Start {
+			return true, nil
+		}
+		found = start
+		return false, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tail) > 0 {
+		return tail[0], nil
+	}
+	if found >= 0 {
+		return found, nil
+	}
+	return offset, nil
+}
+
+// eachLine calls fn with every line and its line number starting from 1, line passed to fn
+// includes the trailing newline. It stops when fn returns false or error.
+func eachLine(r io.Reader, fn func(number int, line []byte) (bool, error)) error {
+	buf := bufio.NewReader(r)
+	for number := 1; ; number++ {
+		line, err := buf.ReadBytes('\n')
+		if len(line) > 0 {
+			next, ferr := fn(number, line)
+			if ferr != nil {
+				return ferr
+			}
+			if !next {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ParseTimestamp parses the leading RFC3339Nano timestamp of a line, the timestamp should
+// be separated from the rest of the line by a space.
+func ParseTimestamp(line []byte) (time.Time, bool) {
+	i := bytes.IndexByte(line, ' ')
+	if i <= 0 || i > maxTimestampLength {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, string(line[:i]))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Search searches lines matched in logs of a workflowrun, only logs of the given stage are
+// searched if stage is not empty. At most limit lines are returned if limit is positive.
+// Lines stored from records are matched without time and stream.
+func Search(store Store, namespace, workflowrun, stage string, match func(line []byte) bool, limit int) ([]api.LogLine, error) {
+	keys, err := store.List(namespace, workflowrun)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]api.LogLine, 0)
+	for _, key := range keys {
+		if stage != "" && key.Stage != stage {
+			continue
+		}
+
+		r, err := store.Open(key, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		err = eachLine(r, func(number int, line []byte) (bool, error) {
+			if !match(StripLine(line)) {
+				return true, nil
+			}
+			results = append(results, toLogLine(key, number, line))
+			return limit <= 0 || len(results) < limit, nil
+		})
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+
+	return results, nil
+}
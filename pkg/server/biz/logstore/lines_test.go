@@ -0,0 +1,153 @@
+package logstore
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const timestampedLog = `2019-01-01T00:00:01Z line1
+2019-01-01T00:00:02.5Z line2
+continued
+2019-01-01T00:00:03Z line3
+2019-01-01T00:00:04Z line4
+`
+
+func TestFilterLines(t *testing.T) {
+	since, _ := time.Parse(time.RFC3339, "2019-01-01T00:00:02Z")
+	cases := map[string]struct {
+		query  LineQuery
+		expect string
+	}{
+		"all": {
+			LineQuery{},
+			timestampedLog,
+		},
+		"start and limit": {
+			LineQuery{Start: 1, Limit: 2},
+			"2019-01-01T00:00:02.5Z line2\ncontinued\n",
+		},
+		"start beyond": {
+			LineQuery{Start: 10},
+			"",
+		},
+		"tail": {
+			LineQuery{Tail: 2},
+			"2019-01-01T00:00:03Z line3\n2019-01-01T00:00:04Z line4\n",
+		},
+		"tail with limit": {
+			LineQuery{Tail: 3, Limit: 1},
+			"continued\n",
+		},
+		"since": {
+			LineQuery{Since: since},
+			"2019-01-01T00:00:02.5Z line2\ncontinued\n2019-01-01T00:00:03Z line3\n2019-01-01T00:00:04Z line4\n",
+		},
+		"since and start": {
+			LineQuery{Since: since, Start: 2, Limit: 1},
+			"2019-01-01T00:00:03Z line3\n",
+		},
+	}
+
+	for name, c := range cases {
+		r := FilterLines(ioutil.NopCloser(strings.NewReader(timestampedLog)), c.query)
+		data, err := ioutil.ReadAll(r)
+		assert.Nil(t, err, name)
+		assert.Equal(t, c.expect, string(data), name)
+	}
+}
+
+type trackedCloser struct {
+	io.Reader
+	closed int
+}
+
+func (c *trackedCloser) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestFilterLinesClose(t *testing.T) {
+	for _, q := range []LineQuery{{Timestamps: true}, {Tail: 1}} {
+		// Source is closed once all lines are read, even if the reader is not closed.
+		source := &trackedCloser{Reader: strings.NewReader(timestampedLog)}
+		r := FilterLines(source, q)
+		_, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, source.closed)
+		assert.Nil(t, r.Close())
+		assert.Equal(t, 1, source.closed)
+
+		// Source is closed if the reader is closed before reading to the end.
+		source = &trackedCloser{Reader: strings.NewReader(timestampedLog)}
+		r = FilterLines(source, q)
+		assert.Nil(t, r.Close())
+		assert.Equal(t, 1, source.closed)
+	}
+}
+
+func TestLineQueryOffset(t *testing.T) {
+	since, _ := time.Parse(time.RFC3339, "2019-01-01T00:00:03Z")
+	cases := map[string]struct {
+		query  LineQuery
+		expect int64
+	}{
+		"start":        {LineQuery{Start: 1}, int64(strings.Index(timestampedLog, "2019-01-01T00:00:02.5Z"))},
+		"tail":         {LineQuery{Tail: 1}, int64(strings.Index(timestampedLog, "2019-01-01T00:00:04Z"))},
+		"since":        {LineQuery{Since: since}, int64(strings.Index(timestampedLog, "2019-01-01T00:00:03Z"))},
+		"not selected": {LineQuery{Start: 10}, int64(len(timestampedLog))},
+	}
+
+	for name, c := range cases {
+		offset, err := c.query.Offset(strings.NewReader(timestampedLog))
+		assert.Nil(t, err, name)
+		assert.Equal(t, c.expect, offset, name)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	ts, ok := ParseTimestamp([]byte("2019-01-01T00:00:01.123456789+08:00 stdout hello"))
+	assert.True(t, ok)
+	assert.Equal(t, 123456789, ts.Nanosecond())
+
+	_, ok = ParseTimestamp([]byte("hello world"))
+	assert.False(t, ok)
+	_, ok = ParseTimestamp([]byte("2019-01-01T00:00:01Z"))
+	assert.False(t, ok)
+}
+
+func TestSearch(t *testing.T) {
+	root, err := ioutil.TempDir("", "logstore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	store := newLocalStore(root, 0, false)
+	writeLog(t, store, Key{Namespace: "ns", WorkflowRun: "wfr", Stage: "build", Container: "main"}, "ok\nerror: a\nok\n")
+	writeLog(t, store, Key{Namespace: "ns", WorkflowRun: "wfr", Stage: "test", Container: "main"}, "error: b\n")
+	match := func(line []byte) bool {
+		return bytes.Contains(line, []byte("error"))
+	}
+
+	lines, err := Search(store, "ns", "wfr", "", match, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(lines))
+	assert.Equal(t, "build", lines[0].Stage)
+	assert.Equal(t, 2, lines[0].LineNumber)
+	assert.Equal(t, "error: a", lines[0].Line)
+	assert.Equal(t, "test", lines[1].Stage)
+
+	lines, err = Search(store, "ns", "wfr", "test", match, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(lines))
+	assert.Equal(t, "error: b", lines[0].Line)
+
+	lines, err = Search(store, "ns", "wfr", "", match, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(lines))
+}
@@ -0,0 +1,445 @@
+package logstore
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caicloud/nirvana/log"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+const (
+	// logsFolderName is the folder name for logs files.
+	logsFolderName = "logs"
+
+	// compressedSuffix is the file suffix of compressed logs.
+	compressedSuffix = ".gz"
+
+	// sizeExtraID is ID of the gzip header extra subfield that records the uncompressed size.
+	sizeExtraID = "CS"
+
+	// stateSuffix is the file suffix of files recording state of finished logs.
+	stateSuffix = ".state"
+)
+
+// localStore stores logs in local file system, logs are organized as
+// <root>/<namespace>/<workflowrun>/<stage>/logs/<container>. State of a finished log is
+// recorded in <container>.state, a log neither being written nor finished is regarded as
+// incomplete, for example, Cyclone server restarted while receiving it.
+type localStore struct {
+	root     string
+	maxSize  int64
+	compress bool
+
+	// lock protects active, which records logs being written.
+	lock   sync.Mutex
+	active map[Key]bool
+}
+
+var _ Store = (*localStore)(nil)
+
+func newLocalStore(root string, maxSize int64, compress bool) *localStore {
+	return &localStore{
+		root:     root,
+		maxSize:  maxSize,
+		compress: compress,
+		active:   make(map[Key]bool),
+	}
+}
+
+func (s *localStore) runDir(namespace, workflowrun string) string {
+	return filepath.Join(s.root, namespace, workflowrun)
+}
+
+func (s *localStore) path(key Key) string {
+	return filepath.Join(s.runDir(key.Namespace, key.WorkflowRun), key.Stage, logsFolderName, key.Container)
+}
+
+// Create ...
+func (s *localStore) Create(key Key) (Writer, error) {
+	return s.create(key, s.finish)
+}
+
+// create creates the log file, finish would be called with the file path once the writer is
+// closed or aborted. Incomplete log is removed before creating.
+func (s *localStore) create(key Key, finish func(key Key, path string, size int64, state api.LogState) error) (Writer, error) {
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.active[key] {
+		return nil, ErrExists
+	}
+
+	path := s.path(key)
+	info, err := s.stat(key)
+	switch err {
+	case nil:
+		if info.State == api.LogStateCompleted {
+			return nil, ErrExists
+		}
+		log.Infof("Replace incomplete log %s", key)
+		if err := s.remove(key); err != nil {
+			return nil, err
+		}
+	case ErrNotFound:
+	default:
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, ErrExists
+		}
+		return nil, err
+	}
+	s.active[key] = true
+
+	return &fileWriter{
+		limitedWriter: limitedWriter{w: file, max: s.maxSize},
+		file:          file,
+		finish: func(size int64, state api.LogState) error {
+			defer func() {
+				s.lock.Lock()
+				delete(s.active, key)
+				s.lock.Unlock()
+			}()
+			return finish(key, path, size, state)
+		},
+	}, nil
+}
+
+// finish compresses the finished log if compression is enabled, and records its state.
+func (s *localStore) finish(key Key, path string, size int64, state api.LogState) error {
+	if s.compress {
+		if err := compressFile(path, path+compressedSuffix, size); err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(path+stateSuffix, []byte(state), 0666)
+}
+
+// remove removes files of the log.
+func (s *localStore) remove(key Key) error {
+	path := s.path(key)
+	for _, f := range []string{path, path + compressedSuffix, path + stateSuffix} {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open ...
+func (s *localStore) Open(key Key, offset, length int64) (io.ReadCloser, error) {
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+
+	path := s.path(key)
+	file, err := os.Open(path)
+	if err == nil {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &readCloser{Reader: limitReader(file, length), closer: file.Close}, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// The plain log file is removed only after it has been compressed, so try the compressed one.
+	file, err = os.Open(path + compressedSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	r, err := openCompressed(file, offset, length)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &readCloser{Reader: r, closer: file.Close}, nil
+}
+
+// Stat ...
+func (s *localStore) Stat(key Key) (*Info, error) {
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.stat(key)
+}
+
+// stat gets information of the log, lock should be held by caller.
+func (s *localStore) stat(key Key) (*Info, error) {
+	path := s.path(key)
+	fi, err := os.Stat(path)
+	if err == nil {
+		return &Info{Size: fi.Size(), ModTime: fi.ModTime(), State: s.state(key, false)}, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	file, err := os.Open(path + compressedSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	fi, err = file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{Size: uncompressedSize(gz.Header), Compressed: true, ModTime: fi.ModTime(), State: s.state(key, true)}, nil
+}
+
+// state gets state of the log, lock should be held by caller. Logs compressed without state
+// recorded are regarded as completed, since logs are compressed only when finished.
+func (s *localStore) state(key Key, compressed bool) api.LogState {
+	if s.active[key] {
+		return api.LogStateWriting
+	}
+
+	data, err := ioutil.ReadFile(s.path(key) + stateSuffix)
+	if err == nil {
+		return api.LogState(data)
+	}
+	if compressed {
+		return api.LogStateCompleted
+	}
+	return api.LogStateIncomplete
+}
+
+// List ...
+func (s *localStore) List(namespace, workflowrun string) ([]Key, error) {
+	files, err := filepath.Glob(filepath.Join(s.runDir(namespace, workflowrun), "*", logsFolderName, "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	seen := make(map[Key]bool)
+	for _, f := range files {
+		container := filepath.Base(f)
+		if strings.HasSuffix(container, ".tmp") || strings.HasSuffix(container, stateSuffix) {
+			continue
+		}
+		key := Key{
+			Namespace:   namespace,
+			WorkflowRun: workflowrun,
+			Stage:       filepath.Base(filepath.Dir(filepath.Dir(f))),
+			Container:   strings.TrimSuffix(container, compressedSuffix),
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sortKeys(keys)
+
+	return keys, nil
+}
+
+// DeleteRun ...
+func (s *localStore) DeleteRun(namespace, workflowrun string) error {
+	if namespace == "" || workflowrun == "" {
+		return nil
+	}
+	return os.RemoveAll(s.runDir(namespace, workflowrun))
+}
+
+// Prune ...
+func (s *localStore) Prune(before time.Time) error {
+	runs, err := filepath.Glob(filepath.Join(s.root, "*", "*"))
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		// Skip files not managed by the store, for example, other data stored in the root.
+		if !isRunDir(run) {
+			continue
+		}
+
+		latest, err := latestModTime(run)
+		if err != nil {
+			log.Warningf("Get modification time of logs in %s error: %v", run, err)
+			continue
+		}
+
+		if latest.Before(before) {
+			log.Infof("Delete expired logs %s, last written at %s", run, latest)
+			if err := os.RemoveAll(run); err != nil {
+				log.Warningf("Delete expired logs %s error: %v", run, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isRunDir checks whether the path is a run directory, which contains folders of stage logs.
+func isRunDir(path string) bool {
+	dirs, err := filepath.Glob(filepath.Join(path, "*", logsFolderName))
+	if err != nil {
+		return false
+	}
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// latestModTime gets the latest modification time of all files under the folder.
+func latestModTime(dir string) (time.Time, error) {
+	var latest time.Time
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest, err
+}
+
+// fileWriter writes log to a file, finish is called after the file is closed.
+type fileWriter struct {
+	limitedWriter
+	file   *os.File
+	finish func(size int64, state api.LogState) error
+}
+
+// Close ...
+func (w *fileWriter) Close() error {
+	return w.close(api.LogStateCompleted)
+}
+
+// Abort ...
+func (w *fileWriter) Abort() error {
+	return w.close(api.LogStateIncomplete)
+}
+
+// close closes the file and finishes the log, the log is incomplete if the file can't be closed.
+func (w *fileWriter) close(state api.LogState) error {
+	err := w.file.Close()
+	if err != nil {
+		state = api.LogStateIncomplete
+	}
+
+	if ferr := w.finish(w.written, state); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// compressFile gzips the src file to dst, the uncompressed size is recorded in the gzip header.
+func compressFile(src, dst string, size int64) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	gz := gzip.NewWriter(out)
+	gz.Header.Extra = sizeExtra(size)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, dst)
+}
+
+// openCompressed reads range [offset, offset+length) of the uncompressed content.
+func openCompressed(r io.Reader, offset, length int64) (io.Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gzip doesn't support seeking, so content before offset is decompressed and discarded.
+	if offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, gz, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+	return limitReader(gz, length), nil
+}
+
+// sizeExtra builds the gzip header extra field to record the uncompressed size, it's
+// formatted as a subfield described in RFC 1952.
+func sizeExtra(size int64) []byte {
+	extra := make([]byte, 12)
+	copy(extra, sizeExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], 8)
+	binary.LittleEndian.PutUint64(extra[4:], uint64(size))
+	return extra
+}
+
+// uncompressedSize gets the uncompressed size recorded in gzip header, -1 is returned if not found.
+func uncompressedSize(header gzip.Header) int64 {
+	extra := header.Extra
+	for len(extra) >= 4 {
+		n := int(binary.LittleEndian.Uint16(extra[2:]))
+		if len(extra) < 4+n {
+			break
+		}
+		if string(extra[:2]) == sizeExtraID && n == 8 {
+			return int64(binary.LittleEndian.Uint64(extra[4:]))
+		}
+		extra = extra[4+n:]
+	}
+	return -1
+}
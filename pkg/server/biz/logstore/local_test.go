@@ -0,0 +1,219 @@
+package logstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/common"
+)
+
+var testKey = Key{
+	Namespace:   "cyclone--test",
+	WorkflowRun: "wfr1",
+	Stage:       "build",
+	Container:   "main",
+}
+
+func writeLog(t *testing.T, store Store, key Key, content string) {
+	w, err := store.Create(key)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte(content))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+}
+
+func readLog(t *testing.T, store Store, key Key, offset, length int64) string {
+	r, err := store.Open(key, offset, length)
+	assert.Nil(t, err)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	return string(data)
+}
+
+func TestLocalStore(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		root, err := ioutil.TempDir("", "logstore")
+		assert.Nil(t, err)
+		defer os.RemoveAll(root)
+
+		store := newLocalStore(root, 0, compress)
+		_, err = store.Stat(testKey)
+		assert.Equal(t, ErrNotFound, err)
+
+		writeLog(t, store, testKey, "line1\nline2\nline3\n")
+		_, err = store.Create(testKey)
+		assert.Equal(t, ErrExists, err)
+
+		info, err := store.Stat(testKey)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(18), info.Size)
+		assert.Equal(t, compress, info.Compressed)
+
+		assert.Equal(t, "line1\nline2\nline3\n", readLog(t, store, testKey, 0, 0))
+		assert.Equal(t, "line2\n", readLog(t, store, testKey, 6, 6))
+		assert.Equal(t, "line3\n", readLog(t, store, testKey, 12, 100))
+		assert.Equal(t, "", readLog(t, store, testKey, 100, 0))
+
+		assert.Nil(t, store.DeleteRun(testKey.Namespace, testKey.WorkflowRun))
+		_, err = store.Stat(testKey)
+		assert.Equal(t, ErrNotFound, err)
+	}
+}
+
+func TestLocalStoreReadWhileWriting(t *testing.T) {
+	root, err := ioutil.TempDir("", "logstore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	store := newLocalStore(root, 0, true)
+	w, err := store.Create(testKey)
+	assert.Nil(t, err)
+	w.Write([]byte("line1\n"))
+	assert.Equal(t, "line1\n", readLog(t, store, testKey, 0, 0))
+
+	w.Write([]byte("line2\n"))
+	assert.Equal(t, "line2\n", readLog(t, store, testKey, 6, 0))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "line1\nline2\n", readLog(t, store, testKey, 0, 0))
+}
+
+func TestLocalStoreState(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		root, err := ioutil.TempDir("", "logstore")
+		assert.Nil(t, err)
+		defer os.RemoveAll(root)
+
+		store := newLocalStore(root, 0, compress)
+		w, err := store.Create(testKey)
+		assert.Nil(t, err)
+		w.Write([]byte("partial\n"))
+		info, err := store.Stat(testKey)
+		assert.Nil(t, err)
+		assert.Equal(t, api.LogStateWriting, info.State)
+		_, err = store.Create(testKey)
+		assert.Equal(t, ErrExists, err)
+
+		assert.Nil(t, w.Abort())
+		info, err = store.Stat(testKey)
+		assert.Nil(t, err)
+		assert.Equal(t, api.LogStateIncomplete, info.State)
+		keys, err := store.List(testKey.Namespace, testKey.WorkflowRun)
+		assert.Nil(t, err)
+		assert.Equal(t, []Key{testKey}, keys)
+
+		// Incomplete log is replaced.
+		writeLog(t, store, testKey, "line1\nline2\n")
+		info, err = store.Stat(testKey)
+		assert.Nil(t, err)
+		assert.Equal(t, api.LogStateCompleted, info.State)
+		assert.Equal(t, "line1\nline2\n", readLog(t, store, testKey, 0, 0))
+
+		status, err := ListStatus(store, testKey.Namespace, testKey.WorkflowRun, testKey.Stage)
+		assert.Nil(t, err)
+		assert.Equal(t, []api.LogStatus{{Stage: "build", Container: "main", State: api.LogStateCompleted, Size: 12}}, status)
+		status, err = ListStatus(store, testKey.Namespace, testKey.WorkflowRun, "test")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(status))
+	}
+
+	// Logs left by a restarted server are incomplete.
+	root, err := ioutil.TempDir("", "logstore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+	w, err := newLocalStore(root, 0, false).Create(testKey)
+	assert.Nil(t, err)
+	w.Write([]byte("partial\n"))
+	info, err := newLocalStore(root, 0, false).Stat(testKey)
+	assert.Nil(t, err)
+	assert.Equal(t, api.LogStateIncomplete, info.State)
+}
+
+func TestLimitedWriter(t *testing.T) {
+	root, err := ioutil.TempDir("", "logstore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	store := newLocalStore(root, 10, false)
+	w, err := store.Create(testKey)
+	assert.Nil(t, err)
+	n, err := w.Write([]byte("12345678"))
+	assert.Nil(t, err)
+	assert.Equal(t, 8, n)
+	n, err = w.Write([]byte("abcdef"))
+	assert.Nil(t, err)
+	assert.Equal(t, 6, n)
+	n, err = w.Write([]byte("dropped"))
+	assert.Nil(t, err)
+	assert.Equal(t, 7, n)
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, "12345678ab"+fmt.Sprintf(TruncatedMarkerFormat, 10), readLog(t, store, testKey, 0, 0))
+}
+
+func TestLocalStorePrune(t *testing.T) {
+	root, err := ioutil.TempDir("", "logstore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	store := newLocalStore(root, 0, false)
+	expired := Key{Namespace: "ns", WorkflowRun: "expired", Stage: "s", Container: "c"}
+	active := Key{Namespace: "ns", WorkflowRun: "active", Stage: "s", Container: "c"}
+	writeLog(t, store, expired, "old")
+	writeLog(t, store, active, "new")
+
+	old := time.Now().Add(-48 * time.Hour)
+	err = filepath.Walk(store.runDir("ns", "expired"), func(path string, info os.FileInfo, err error) error {
+		return os.Chtimes(path, old, old)
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.Prune(time.Now().Add(-24*time.Hour)))
+	_, err = store.Stat(expired)
+	assert.Equal(t, ErrNotFound, err)
+	_, err = store.Stat(active)
+	assert.Nil(t, err)
+}
+
+func TestLocalStorePruneSkipsOtherData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logstore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// Summaries stored in the default history root, and in the history root nested in logs root
+	// used by earlier versions.
+	root := filepath.Join(dir, common.CycloneHome)
+	histories := []string{
+		filepath.Join(dir, common.DefaultHistoryRoot, "ns", "wf.db"),
+		filepath.Join(root, "history", "ns", "wf.db"),
+		filepath.Join(root, "ns", "wf.db"),
+	}
+	store := newLocalStore(root, 0, false)
+	expired := Key{Namespace: "ns", WorkflowRun: "expired", Stage: "s", Container: "c"}
+	writeLog(t, store, expired, "old")
+	for _, h := range histories {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(h), 0755))
+		assert.Nil(t, ioutil.WriteFile(h, []byte("summaries"), 0644))
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		return os.Chtimes(path, old, old)
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.Prune(time.Now().Add(-24*time.Hour)))
+	_, err = store.Stat(expired)
+	assert.Equal(t, ErrNotFound, err)
+	for _, h := range histories {
+		_, err = os.Stat(h)
+		assert.Nil(t, err, h)
+	}
+}
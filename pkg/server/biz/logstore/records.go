@@ -0,0 +1,215 @@
+package logstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+// Logs pushed as records are stored line by line in format '<time> <stream> <line>', time is in
+// RFC3339Nano format. Logs pushed as raw text are stored as they are.
+
+// ParseRecord parses a message pushed by log collectors, ok is false if it's not a record.
+func ParseRecord(message []byte) (*api.LogRecord, bool) {
+	message = bytes.TrimSpace(message)
+	if len(message) == 0 || message[0] != '{' {
+		return nil, false
+	}
+
+	record := &api.LogRecord{}
+	if err := json.Unmarshal(message, record); err != nil || record.Time.IsZero() {
+		return nil, false
+	}
+	return record, true
+}
+
+// FormatRecord formats a record to the line stored.
+func FormatRecord(record *api.LogRecord) []byte {
+	stream := record.Stream
+	if stream == "" {
+		stream = api.LogStreamCombined
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(record.Time.UTC().Format(time.RFC3339Nano))
+	buf.WriteByte(' ')
+	buf.WriteString(string(stream))
+	buf.WriteByte(' ')
+	buf.WriteString(record.Line)
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// ParseLine parses a stored line formatted from record, ok is false if the line isn't.
+func ParseLine(line []byte) (t time.Time, stream api.LogStream, content []byte, ok bool) {
+	t, ok = ParseTimestamp(line)
+	if !ok {
+		return t, "", line, false
+	}
+
+	rest := line[bytes.IndexByte(line, ' ')+1:]
+	i := bytes.IndexByte(rest, ' ')
+	if i < 0 {
+		return t, "", line, false
+	}
+	switch s := api.LogStream(rest[:i]); s {
+	case api.LogStreamStdout, api.LogStreamStderr, api.LogStreamCombined:
+		return t, s, rest[i+1:], true
+	}
+	return t, "", line, false
+}
+
+// StripLine strips time and stream from the line if it's formatted from record.
+func StripLine(line []byte) []byte {
+	_, _, content, _ := ParseLine(line)
+	return content
+}
+
+// toLogLine converts a stored line to LogLine, trailing newline is removed.
+func toLogLine(key Key, number int, line []byte) api.LogLine {
+	l := api.LogLine{
+		Stage:      key.Stage,
+		Container:  key.Container,
+		LineNumber: number,
+	}
+
+	t, stream, content, ok := ParseLine(line)
+	if ok {
+		l.Time = &t
+		l.Stream = stream
+	} else if t, ok := ParseTimestamp(line); ok {
+		l.Time = &t
+	}
+	l.Line = string(bytes.TrimRight(content, "\r\n"))
+	return l
+}
+
+// mergeSource is a log to merge.
+type mergeSource struct {
+	key    Key
+	reader io.ReadCloser
+	buf    *bufio.Reader
+	number int
+	last   time.Time
+	head   *api.LogLine
+}
+
+// next reads the next line to head, head is nil if all lines are read.
+func (s *mergeSource) next() error {
+	s.head = nil
+	line, err := s.buf.ReadBytes('\n')
+	if len(line) == 0 {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+
+	s.number++
+	l := toLogLine(s.key, s.number, line)
+	// Lines without timestamp follow the line before them.
+	if l.Time != nil {
+		s.last = *l.Time
+	} else if !s.last.IsZero() {
+		t := s.last
+		l.Time = &t
+	}
+	s.head = &l
+
+	if err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// Merge merges logs of all containers of a stage into one view ordered by time, lines are
+// then selected by the query. Timestamps of the query is ignored, time and stream are always
+// returned separately.
+func Merge(store Store, namespace, workflowrun, stage string, q LineQuery) ([]api.LogLine, error) {
+	keys, err := store.List(namespace, workflowrun)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []*mergeSource
+	defer func() {
+		for _, s := range sources {
+			s.reader.Close()
+		}
+	}()
+	for _, key := range keys {
+		if key.Stage != stage {
+			continue
+		}
+
+		r, err := store.Open(key, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		s := &mergeSource{key: key, reader: r, buf: bufio.NewReader(r)}
+		sources = append(sources, s)
+		if err := s.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]api.LogLine, 0)
+	selected := 0
+	for {
+		// Pick the earliest line among all sources, lines without time come first.
+		var earliest *mergeSource
+		for _, s := range sources {
+			if s.head == nil {
+				continue
+			}
+			if earliest == nil || lineTime(s.head).Before(lineTime(earliest.head)) {
+				earliest = s
+			}
+		}
+		if earliest == nil {
+			break
+		}
+
+		line := *earliest.head
+		if err := earliest.next(); err != nil {
+			return nil, err
+		}
+
+		if !q.Since.IsZero() && lineTime(&line).Before(q.Since) {
+			continue
+		}
+
+		if q.Tail > 0 {
+			if len(results) == q.Tail {
+				results = results[1:]
+			}
+			results = append(results, line)
+			continue
+		}
+
+		selected++
+		if selected <= q.Start {
+			continue
+		}
+		results = append(results, line)
+		if q.Limit > 0 && len(results) >= q.Limit {
+			break
+		}
+	}
+
+	if q.Tail > 0 && q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
+
+func lineTime(l *api.LogLine) time.Time {
+	if l.Time == nil {
+		return time.Time{}
+	}
+	return *l.Time
+}
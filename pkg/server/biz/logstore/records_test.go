@@ -0,0 +1,88 @@
+package logstore
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+func TestRecord(t *testing.T) {
+	record, ok := ParseRecord([]byte(`{"time":"2019-01-01T00:00:01.5Z","container":"main","stream":"stderr","line":"oops"}`))
+	assert.True(t, ok)
+	assert.Equal(t, "2019-01-01T00:00:01.5Z stderr oops\n", string(FormatRecord(record)))
+
+	_, ok = ParseRecord([]byte("plain text line\n"))
+	assert.False(t, ok)
+	_, ok = ParseRecord([]byte(`{"key": "json output of user"}`))
+	assert.False(t, ok)
+
+	ts, stream, content, ok := ParseLine([]byte("2019-01-01T00:00:01.5Z stderr oops\n"))
+	assert.True(t, ok)
+	assert.Equal(t, 500000000, ts.Nanosecond())
+	assert.Equal(t, api.LogStreamStderr, stream)
+	assert.Equal(t, "oops\n", string(content))
+
+	_, _, content, ok = ParseLine([]byte("2019-01-01T00:00:01.5Z raw line\n"))
+	assert.False(t, ok)
+	assert.Equal(t, "2019-01-01T00:00:01.5Z raw line\n", string(content))
+}
+
+func TestFilterRecordLines(t *testing.T) {
+	content := "2019-01-01T00:00:01Z stdout a1\n2019-01-01T00:00:03Z stderr a3\n"
+	r := FilterLines(ioutil.NopCloser(strings.NewReader(content)), LineQuery{Start: 1})
+	data, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "a3\n", string(data))
+
+	r = FilterLines(ioutil.NopCloser(strings.NewReader(content)), LineQuery{Start: 1, Timestamps: true})
+	data, err = ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "2019-01-01T00:00:03Z stderr a3\n", string(data))
+}
+
+func TestMerge(t *testing.T) {
+	root, err := ioutil.TempDir("", "logstore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	store := newLocalStore(root, 0, false)
+	writeLog(t, store, Key{Namespace: "ns", WorkflowRun: "wfr", Stage: "build", Container: "main"},
+		"2019-01-01T00:00:01Z stdout a1\n2019-01-01T00:00:03Z stderr a3\n")
+	writeLog(t, store, Key{Namespace: "ns", WorkflowRun: "wfr", Stage: "build", Container: "sidecar"},
+		"2019-01-01T00:00:02Z stdout b2\nb2 continued\n2019-01-01T00:00:04Z stdout b4\n")
+	writeLog(t, store, Key{Namespace: "ns", WorkflowRun: "wfr", Stage: "test", Container: "main"},
+		"2019-01-01T00:00:00Z stdout other stage\n")
+
+	contents := func(lines []api.LogLine) []string {
+		var result []string
+		for _, l := range lines {
+			result = append(result, l.Container+":"+l.Line)
+		}
+		return result
+	}
+
+	lines, err := Merge(store, "ns", "wfr", "build", LineQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"main:a1", "sidecar:b2", "sidecar:b2 continued", "main:a3", "sidecar:b4"}, contents(lines))
+	assert.Equal(t, api.LogStreamStderr, lines[3].Stream)
+	assert.Equal(t, 2, lines[3].LineNumber)
+
+	lines, err = Merge(store, "ns", "wfr", "build", LineQuery{Start: 1, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sidecar:b2", "sidecar:b2 continued"}, contents(lines))
+
+	lines, err = Merge(store, "ns", "wfr", "build", LineQuery{Tail: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"main:a3", "sidecar:b4"}, contents(lines))
+
+	since, _ := time.Parse(time.RFC3339, "2019-01-01T00:00:03Z")
+	lines, err = Merge(store, "ns", "wfr", "build", LineQuery{Since: since})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"main:a3", "sidecar:b4"}, contents(lines))
+}
@@ -0,0 +1,303 @@
+package logstore
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caicloud/nirvana/log"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/config"
+)
+
+const (
+	// metaLogSize is the object metadata key to record the uncompressed size of logs.
+	metaLogSize = "Log-Size"
+
+	// metaLogState is the object metadata key to record state of logs.
+	metaLogState = "Log-State"
+)
+
+// s3Store stores logs in S3 compatible object storage. Logs being received are spooled in
+// local file system, and uploaded to the bucket once they are finished. Objects are organized
+// as <prefix>/<namespace>/<workflowrun>/<stage>/<container>[.gz].
+type s3Store struct {
+	client *s3Client
+	spool  *localStore
+	prefix string
+}
+
+var _ Store = (*s3Store)(nil)
+
+func newS3Store(cfg config.S3Config, spool *localStore) (*s3Store, error) {
+	client, err := newS3Client(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Store{
+		client: client,
+		spool:  spool,
+		prefix: strings.Trim(cfg.Prefix, "/"),
+	}, nil
+}
+
+// runPrefix is the key prefix of all objects of a workflowrun.
+func (s *s3Store) runPrefix(namespace, workflowrun string) string {
+	return path.Join(s.prefix, namespace, workflowrun) + "/"
+}
+
+func (s *s3Store) objectKey(key Key, compressed bool) string {
+	k := s.runPrefix(key.Namespace, key.WorkflowRun) + key.Stage + "/" + key.Container
+	if compressed {
+		k += compressedSuffix
+	}
+	return k
+}
+
+// Create ...
+func (s *s3Store) Create(key Key) (Writer, error) {
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+
+	uploaded, err := s.stat(key)
+	if err == nil && uploaded.State == api.LogStateCompleted {
+		return nil, ErrExists
+	}
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+
+	w, err := s.spool.create(key, s.upload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the incomplete log uploaded, it would be replaced once the new one is finished.
+	if uploaded != nil {
+		log.Infof("Replace incomplete log %s", key)
+		for _, compressed := range []bool{false, true} {
+			if err := s.client.delete(s.objectKey(key, compressed)); err != nil && err != ErrNotFound {
+				log.Warningf("Delete incomplete log %s error: %v", key, err)
+			}
+		}
+	}
+	return w, nil
+}
+
+// upload uploads the finished log to the bucket and removes the spooled files.
+func (s *s3Store) upload(key Key, file string, size int64, state api.LogState) error {
+	compressed := s.spool.compress
+	if compressed {
+		if err := compressFile(file, file+compressedSuffix, size); err != nil {
+			return err
+		}
+		defer os.Remove(file + compressedSuffix)
+		file += compressedSuffix
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	meta := map[string]string{
+		metaLogSize:  strconv.FormatInt(size, 10),
+		metaLogState: string(state),
+	}
+	if err := s.client.put(s.objectKey(key, compressed), f, fi.Size(), meta); err != nil {
+		return fmt.Errorf("upload log %s error: %v", key, err)
+	}
+	log.Infof("Log %s uploaded", key)
+
+	return os.Remove(s.spool.path(key))
+}
+
+// Open ...
+func (s *s3Store) Open(key Key, offset, length int64) (io.ReadCloser, error) {
+	r, err := s.spool.Open(key, offset, length)
+	if err != ErrNotFound {
+		return r, err
+	}
+
+	info, err := s.stat(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Compressed {
+		body, err := s.client.get(s.objectKey(key, true), "")
+		if err != nil {
+			return nil, err
+		}
+		r, err := openCompressed(body, offset, length)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		return &readCloser{Reader: r, closer: body.Close}, nil
+	}
+
+	// Range beyond the object would get 416 error, return empty content instead.
+	if offset >= info.Size {
+		return ioutil.NopCloser(strings.NewReader("")), nil
+	}
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+	return s.client.get(s.objectKey(key, false), byteRange)
+}
+
+// Stat ...
+func (s *s3Store) Stat(key Key) (*Info, error) {
+	info, err := s.spool.Stat(key)
+	if err != ErrNotFound {
+		return info, err
+	}
+	return s.stat(key)
+}
+
+// stat gets information of the uploaded log.
+func (s *s3Store) stat(key Key) (*Info, error) {
+	for _, compressed := range []bool{false, true} {
+		header, err := s.client.head(s.objectKey(key, compressed))
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Logs uploaded without state recorded are regarded as completed.
+		info := &Info{Compressed: compressed, Size: -1, State: api.LogStateCompleted}
+		if state := header.Get(metaPrefix + metaLogState); state != "" {
+			info.State = api.LogState(state)
+		}
+		if size, err := strconv.ParseInt(header.Get(metaPrefix+metaLogSize), 10, 64); err == nil {
+			info.Size = size
+		} else if !compressed {
+			info.Size, _ = strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+		}
+		info.ModTime, _ = http.ParseTime(header.Get("Last-Modified"))
+		return info, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+// List ...
+func (s *s3Store) List(namespace, workflowrun string) ([]Key, error) {
+	keys, err := s.spool.List(namespace, workflowrun)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := s.runPrefix(namespace, workflowrun)
+	objects, err := s.client.list(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[Key]bool)
+	for _, k := range keys {
+		seen[k] = true
+	}
+	for _, o := range objects {
+		parts := strings.SplitN(strings.TrimPrefix(o.Key, prefix), "/", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		key := Key{
+			Namespace:   namespace,
+			WorkflowRun: workflowrun,
+			Stage:       parts[0],
+			Container:   strings.TrimSuffix(parts[1], compressedSuffix),
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sortKeys(keys)
+
+	return keys, nil
+}
+
+// DeleteRun ...
+func (s *s3Store) DeleteRun(namespace, workflowrun string) error {
+	if namespace == "" || workflowrun == "" {
+		return nil
+	}
+
+	objects, err := s.client.list(s.runPrefix(namespace, workflowrun))
+	if err != nil {
+		return err
+	}
+	for _, o := range objects {
+		if err := s.client.delete(o.Key); err != nil {
+			return err
+		}
+	}
+
+	return s.spool.DeleteRun(namespace, workflowrun)
+}
+
+// Prune ...
+func (s *s3Store) Prune(before time.Time) error {
+	if err := s.spool.Prune(before); err != nil {
+		log.Warningf("Prune spooled logs error: %v", err)
+	}
+
+	prefix := ""
+	if s.prefix != "" {
+		prefix = s.prefix + "/"
+	}
+	objects, err := s.client.list(prefix)
+	if err != nil {
+		return err
+	}
+
+	// Group objects by workflowrun, a workflowrun expires only when all its logs expire.
+	runs := make(map[string][]s3Object)
+	latest := make(map[string]time.Time)
+	for _, o := range objects {
+		parts := strings.SplitN(strings.TrimPrefix(o.Key, prefix), "/", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		run := parts[0] + "/" + parts[1]
+		runs[run] = append(runs[run], o)
+		if o.LastModified.After(latest[run]) {
+			latest[run] = o.LastModified
+		}
+	}
+
+	for run, objects := range runs {
+		if !latest[run].Before(before) {
+			continue
+		}
+
+		log.Infof("Delete expired logs %s, last written at %s", run, latest[run])
+		for _, o := range objects {
+			if err := s.client.delete(o.Key); err != nil {
+				log.Warningf("Delete expired log %s error: %v", o.Key, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package logstore
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/config"
+)
+
+type fakeObject struct {
+	data     []byte
+	header   http.Header
+	modified time.Time
+}
+
+// fakeS3 is a in-memory S3 server supporting APIs used by s3Client.
+type fakeS3 struct {
+	lock    sync.Mutex
+	bucket  string
+	objects map[string]*fakeObject
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=ak/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+f.bucket), "/")
+	if key == "" && r.Method == http.MethodGet {
+		result := listBucketResult{}
+		for k, o := range f.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				result.Contents = append(result.Contents, s3Object{Key: k, LastModified: o.modified, Size: int64(len(o.data))})
+			}
+		}
+		xml.NewEncoder(w).Encode(result)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := ioutil.ReadAll(r.Body)
+		f.objects[key] = &fakeObject{data: data, header: r.Header, modified: time.Now()}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodHead, http.MethodGet:
+		o, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for k, v := range o.header {
+			if strings.HasPrefix(k, metaPrefix) {
+				w.Header()[k] = v
+			}
+		}
+		w.Header().Set("Last-Modified", o.modified.UTC().Format(http.TimeFormat))
+		data := o.data
+		var start, end int
+		if n, _ := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); n > 0 {
+			if n == 1 || end >= len(data) {
+				end = len(data) - 1
+			}
+			data = data[start : end+1]
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		fake := &fakeS3{bucket: "logs", objects: make(map[string]*fakeObject)}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		root, err := ioutil.TempDir("", "logstore")
+		assert.Nil(t, err)
+		defer os.RemoveAll(root)
+
+		store, err := newS3Store(config.S3Config{
+			Endpoint:  server.URL,
+			Region:    "us-east-1",
+			Bucket:    "logs",
+			Prefix:    "cyclone",
+			AccessKey: "ak",
+			SecretKey: "sk",
+		}, newLocalStore(root, 0, compress))
+		assert.Nil(t, err)
+
+		w, err := store.Create(testKey)
+		assert.Nil(t, err)
+		w.Write([]byte("line1\nline2\n"))
+		// Logs being written are read from the spool.
+		assert.Equal(t, "line2\n", readLog(t, store, testKey, 6, 0))
+		assert.Nil(t, w.Close())
+
+		assert.Equal(t, 1, len(fake.objects))
+		_, err = os.Stat(store.spool.path(testKey))
+		assert.True(t, os.IsNotExist(err))
+
+		info, err := store.Stat(testKey)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(12), info.Size)
+		assert.Equal(t, compress, info.Compressed)
+
+		_, err = store.Create(testKey)
+		assert.Equal(t, ErrExists, err)
+
+		assert.Equal(t, "line1\nline2\n", readLog(t, store, testKey, 0, 0))
+		assert.Equal(t, "line2\n", readLog(t, store, testKey, 6, 6))
+		assert.Equal(t, "ine1", readLog(t, store, testKey, 1, 4))
+		assert.Equal(t, "", readLog(t, store, testKey, 20, 0))
+
+		assert.Nil(t, store.Prune(time.Now().Add(-time.Hour)))
+		assert.Equal(t, 1, len(fake.objects))
+		assert.Nil(t, store.Prune(time.Now().Add(time.Hour)))
+		assert.Equal(t, 0, len(fake.objects))
+
+		// Incomplete log is uploaded with its state, and replaced when pushed again.
+		w, err = store.Create(testKey)
+		assert.Nil(t, err)
+		w.Write([]byte("partial\n"))
+		assert.Nil(t, w.Abort())
+		info, err = store.Stat(testKey)
+		assert.Nil(t, err)
+		assert.Equal(t, api.LogStateIncomplete, info.State)
+		writeLog(t, store, testKey, "line1\n")
+		info, err = store.Stat(testKey)
+		assert.Nil(t, err)
+		assert.Equal(t, api.LogStateCompleted, info.State)
+		assert.Equal(t, "line1\n", readLog(t, store, testKey, 0, 0))
+
+		assert.Nil(t, store.DeleteRun(testKey.Namespace, testKey.WorkflowRun))
+		_, err = store.Stat(testKey)
+		assert.Equal(t, ErrNotFound, err)
+	}
+}
+
+func TestURIEncode(t *testing.T) {
+	assert.Equal(t, "/bucket/a%20b/c~d", uriEncode("/bucket/a b/c~d", false))
+	assert.Equal(t, "a%2Fb%3D", uriEncode("a/b=", true))
+}
@@ -0,0 +1,322 @@
+package logstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/caicloud/cyclone/pkg/server/config"
+)
+
+const (
+	// amzDateFormat is the time format used in AWS signature version 4.
+	amzDateFormat = "20060102T150405Z"
+
+	// unsignedPayload is used as payload hash to skip signing the request body.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+
+	// metaPrefix is the header prefix of user defined object metadata.
+	metaPrefix = "X-Amz-Meta-"
+)
+
+// s3Object is an object listed in bucket.
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	Size         int64     `xml:"Size"`
+}
+
+// listBucketResult is result of the ListObjectsV2 API.
+type listBucketResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+// s3Error is the error response of S3 APIs.
+type s3Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+// Error ...
+func (e *s3Error) Error() string {
+	return fmt.Sprintf("s3 error, status code: %d, code: %s, message: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// s3Client is a minimal client of S3 compatible object storage, requests are signed
+// with AWS signature version 4.
+type s3Client struct {
+	endpoint    *url.URL
+	region      string
+	bucket      string
+	accessKey   string
+	secretKey   string
+	virtualHost bool
+	client      *http.Client
+	now         func() time.Time
+}
+
+func newS3Client(cfg config.S3Config) (*s3Client, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 endpoint and bucket must be configured")
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint %s: %v", cfg.Endpoint, err)
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %s: scheme and host are required", cfg.Endpoint)
+	}
+
+	return &s3Client{
+		endpoint:    endpoint,
+		region:      cfg.Region,
+		bucket:      cfg.Bucket,
+		accessKey:   cfg.AccessKey,
+		secretKey:   cfg.SecretKey,
+		virtualHost: cfg.VirtualHostStyle,
+		client:      http.DefaultClient,
+		now:         time.Now,
+	}, nil
+}
+
+// objectURL builds URL of the object, key can be empty to get URL of the bucket.
+func (c *s3Client) objectURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	if c.virtualHost {
+		u.Host = c.bucket + "." + u.Host
+		u.Path = "/" + key
+	} else {
+		u.Path = "/" + c.bucket + "/" + key
+	}
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+// do sends a signed request, response with non 2xx status code is converted to *s3Error.
+func (c *s3Client) do(method, key string, query url.Values, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.objectURL(key, query).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	c.sign(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		e := &s3Error{StatusCode: resp.StatusCode}
+		data, _ := ioutil.ReadAll(resp.Body)
+		xml.Unmarshal(data, e)
+		return nil, e
+	}
+
+	return resp, nil
+}
+
+// sign signs the request with AWS signature version 4, payload is not signed.
+func (c *s3Client) sign(req *http.Request) {
+	now := c.now().UTC()
+	amzDate := now.Format(amzDateFormat)
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	// Sign host and all 'x-amz-*' headers.
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	var names []string
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	scope := strings.Join([]string{date, c.region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), date)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+// put uploads an object with the given metadata.
+func (c *s3Client) put(key string, body io.Reader, size int64, meta map[string]string) error {
+	header := http.Header{}
+	for k, v := range meta {
+		header.Set(metaPrefix+k, v)
+	}
+	resp, err := c.do(http.MethodPut, key, nil, header, body, size)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// head gets header of an object, ErrNotFound is returned if it doesn't exist.
+func (c *s3Client) head(key string) (http.Header, error) {
+	resp, err := c.do(http.MethodHead, key, nil, nil, nil, 0)
+	if err != nil {
+		if e, ok := err.(*s3Error); ok && e.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp.Header, nil
+}
+
+// get gets content of an object, byteRange is value of the Range header, it can be empty.
+func (c *s3Client) get(key, byteRange string) (io.ReadCloser, error) {
+	header := http.Header{}
+	if byteRange != "" {
+		header.Set("Range", byteRange)
+	}
+	resp, err := c.do(http.MethodGet, key, nil, header, nil, 0)
+	if err != nil {
+		if e, ok := err.(*s3Error); ok && e.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// delete deletes an object.
+func (c *s3Client) delete(key string) error {
+	resp, err := c.do(http.MethodDelete, key, nil, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// list lists all objects with the given prefix.
+func (c *s3Client) list(prefix string) ([]s3Object, error) {
+	var objects []s3Object
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := c.do(http.MethodGet, "", query, nil, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		result := &listBucketResult{}
+		err = xml.NewDecoder(resp.Body).Decode(result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// canonicalQuery builds the canonical query string, parameters are sorted by name.
+func canonicalQuery(query url.Values) string {
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode encodes the string as required by AWS signature version 4, '/' is encoded
+// only when encodeSlash is true.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+			ch == '_' || ch == '-' || ch == '~' || ch == '.' || (ch == '/' && !encodeSlash) {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,237 @@
+package logstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/caicloud/nirvana/log"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/server/config"
+)
+
+var (
+	// ErrNotFound is returned when the requested log doesn't exist.
+	ErrNotFound = errors.New("log not found")
+
+	// ErrExists is returned when creating a log that already exists.
+	ErrExists = errors.New("log already exists")
+)
+
+// TruncatedMarkerFormat is the format of the marker appended to a log when it exceeds the size limit.
+const TruncatedMarkerFormat = "\n[cyclone] log truncated: exceeded the maximum size of %d bytes\n"
+
+// Key identifies log of a container within a workflowrun stage.
+type Key struct {
+	Namespace   string
+	WorkflowRun string
+	Stage       string
+	Container   string
+}
+
+// Validate checks whether all fields of the key are set.
+func (k Key) Validate() error {
+	if k.Namespace == "" || k.WorkflowRun == "" || k.Stage == "" || k.Container == "" {
+		return fmt.Errorf("workflowrun/stage/container/namespace can not be empty")
+	}
+	return nil
+}
+
+// String ...
+func (k Key) String() string {
+	return fmt.Sprintf("%s/%s/%s/%s", k.Namespace, k.WorkflowRun, k.Stage, k.Container)
+}
+
+// Info describes a stored log.
+type Info struct {
+	// Size is size of the log content in bytes, for compressed log, it's the uncompressed size.
+	Size int64
+	// Compressed indicates whether the log has been compressed.
+	Compressed bool
+	// ModTime is the last time the log is written.
+	ModTime time.Time
+	// State is state of the log.
+	State api.LogState
+}
+
+// Writer writes content to a log.
+type Writer interface {
+	io.Writer
+
+	// Close finishes the log as completed.
+	Close() error
+
+	// Abort finishes the log as incomplete, it's used when the log stream breaks off.
+	Abort() error
+}
+
+// Store stores container logs of workflowruns.
+type Store interface {
+	// Create creates a log and returns a writer to write content to it. Content beyond the
+	// size limit are dropped with a truncation marker appended. Closing or aborting the writer
+	// finishes the log, it would be compressed if configured. An incomplete log is replaced,
+	// ErrExists is returned if the log is being written or has been completed.
+	Create(key Key) (Writer, error)
+
+	// Open reads the log content in range [offset, offset+length), length <= 0 means reading
+	// to the end. Offset is always counted on the uncompressed content. Logs being written
+	// can also be read, the content read is what have been written so far.
+	Open(key Key, offset, length int64) (io.ReadCloser, error)
+
+	// Stat gets information of the log, ErrNotFound is returned if it doesn't exist.
+	Stat(key Key) (*Info, error)
+
+	// List lists keys of all logs of a workflowrun, sorted by stage and container.
+	List(namespace, workflowrun string) ([]Key, error)
+
+	// DeleteRun deletes all logs of a workflowrun.
+	DeleteRun(namespace, workflowrun string) error
+
+	// Prune deletes logs of workflowruns that haven't been written since the given time.
+	Prune(before time.Time) error
+}
+
+// New creates a log store according to the logs configuration.
+func New(cfg config.LogsConfig) (Store, error) {
+	local := newLocalStore(cfg.Root, cfg.MaxSizeMB*1024*1024, cfg.Compress)
+	switch cfg.Backend {
+	case common.LogsBackendLocal, "":
+		return local, nil
+	case common.LogsBackendS3:
+		return newS3Store(cfg.S3, local)
+	default:
+		return nil, fmt.Errorf("unsupported logs backend '%s'", cfg.Backend)
+	}
+}
+
+// ListStatus lists status of logs of a workflowrun, only logs of the given stage are listed if
+// stage is not empty.
+func ListStatus(store Store, namespace, workflowrun, stage string) ([]api.LogStatus, error) {
+	keys, err := store.List(namespace, workflowrun)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]api.LogStatus, 0)
+	for _, key := range keys {
+		if stage != "" && key.Stage != stage {
+			continue
+		}
+
+		info, err := store.Stat(key)
+		if err != nil {
+			if err == ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		results = append(results, api.LogStatus{
+			Stage:     key.Stage,
+			Container: key.Container,
+			State:     info.State,
+			Size:      info.Size,
+		})
+	}
+
+	return results, nil
+}
+
+// sortKeys sorts keys by stage and container.
+func sortKeys(keys []Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Stage != keys[j].Stage {
+			return keys[i].Stage < keys[j].Stage
+		}
+		return keys[i].Container < keys[j].Container
+	})
+}
+
+// RunRetention prunes expired logs periodically until stopCh is closed, logs not written
+// for more than retention are deleted. It does nothing if retention is not positive.
+func RunRetention(store Store, retention, interval time.Duration, stopCh <-chan struct{}) {
+	if retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := store.Prune(time.Now().Add(-retention)); err != nil {
+			log.Warningf("Prune expired logs error: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// limitedWriter writes at most max bytes to the underlying writer, content beyond that is
+// dropped and a truncation marker is written instead. written counts all bytes written
+// including the marker.
+type limitedWriter struct {
+	w         io.Writer
+	max       int64
+	written   int64
+	truncated bool
+}
+
+// Write ...
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.max <= 0 {
+		n, err := l.w.Write(p)
+		l.written += int64(n)
+		return n, err
+	}
+
+	if l.truncated {
+		return len(p), nil
+	}
+
+	remain := l.max - l.written
+	if int64(len(p)) <= remain {
+		n, err := l.w.Write(p)
+		l.written += int64(n)
+		return n, err
+	}
+
+	n, err := l.w.Write(p[:remain])
+	l.written += int64(n)
+	if err != nil {
+		return n, err
+	}
+	l.truncated = true
+	m, err := fmt.Fprintf(l.w, TruncatedMarkerFormat, l.max)
+	l.written += int64(m)
+	if err != nil {
+		return n, err
+	}
+
+	// Report all content written to not break the log stream, the rest is dropped.
+	return len(p), nil
+}
+
+// readCloser combines a reader with a separate closer.
+type readCloser struct {
+	io.Reader
+	closer func() error
+}
+
+// Close ...
+func (r *readCloser) Close() error {
+	return r.closer()
+}
+
+// limitReader limits the reader to length bytes if length is positive.
+func limitReader(r io.Reader, length int64) io.Reader {
+	if length <= 0 {
+		return r
+	}
+	return io.LimitReader(r, length)
+}
@@ -0,0 +1,221 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+// manifestMIMEs are accepted media types of image manifests, the digest of a tag depends on
+// the media type of the manifest returned.
+var manifestMIMEs = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}
+
+// Client is a client of Docker Registry HTTP API V2. Basic auth and token auth are supported.
+type Client struct {
+	// endpoint is the base URL of the registry, for example, 'https://registry-1.docker.io'.
+	endpoint   string
+	user       string
+	password   string
+	httpClient *http.Client
+}
+
+// NewClient creates a registry client for the DockerRegistry integration.
+func NewClient(source *api.DockerRegistrySource) *Client {
+	return &Client{
+		endpoint:   Endpoint(source.Server),
+		user:       source.User,
+		password:   source.Password,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Endpoint gets the base URL of the registry server, HTTPS is used if scheme not specified.
+func Endpoint(server string) string {
+	server = strings.TrimSuffix(server, "/")
+	if !strings.HasPrefix(server, "http://") && !strings.HasPrefix(server, "https://") {
+		server = "https://" + server
+	}
+	// Docker Hub serves registry API on a different host.
+	if server == "https://docker.io" || server == "https://index.docker.io" {
+		server = "https://registry-1.docker.io"
+	}
+	return server
+}
+
+// Host gets host of the registry server used in image names, for example, 'docker.io'.
+func Host(server string) string {
+	server = strings.TrimPrefix(server, "http://")
+	server = strings.TrimPrefix(server, "https://")
+	return strings.TrimSuffix(server, "/")
+}
+
+// Tags lists tags of the repository.
+func (c *Client) Tags(repository string) ([]string, error) {
+	var tags []string
+	next := fmt.Sprintf("%s/v2/%s/tags/list", c.endpoint, repository)
+	for next != "" {
+		resp, err := c.do(http.MethodGet, next, repository, nil)
+		if err != nil {
+			return nil, err
+		}
+		result := struct {
+			Tags []string `json:"tags"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode tags of %s error: %v", repository, err)
+		}
+		tags = append(tags, result.Tags...)
+
+		next, err = c.nextPage(next, resp.Header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// linkPattern matches the next page in Link header, for example, '</v2/foo/tags/list?n=100&last=b>; rel="next"'.
+var linkPattern = regexp.MustCompile(`^\s*<([^>]+)>;\s*rel="next"`)
+
+func (c *Client) nextPage(current, link string) (string, error) {
+	m := linkPattern.FindStringSubmatch(link)
+	if m == nil {
+		return "", nil
+	}
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	next, err := base.Parse(m[1])
+	if err != nil {
+		return "", err
+	}
+	return next.String(), nil
+}
+
+// Digest gets digest of the manifest the tag points to.
+func (c *Client) Digest(repository, tag string) (string, error) {
+	header := http.Header{"Accept": []string{strings.Join(manifestMIMEs, ",")}}
+	resp, err := c.do(http.MethodHead, fmt.Sprintf("%s/v2/%s/manifests/%s", c.endpoint, repository, tag), repository, header)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("no digest returned for %s:%s", repository, tag)
+	}
+	return digest, nil
+}
+
+// do sends the request with basic auth, if the registry requires token auth, a token with pull
+// scope of the repository is requested and the request is sent again.
+func (c *Client) do(method, u, repository string, header http.Header) (*http.Response, error) {
+	send := func(auth string) (*http.Response, error) {
+		req, err := http.NewRequest(method, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		} else if c.user != "" {
+			req.SetBasicAuth(c.user, c.password)
+		}
+		return c.httpClient.Do(req)
+	}
+
+	resp, err := send("")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			return nil, fmt.Errorf("%s %s unauthorized", method, u)
+		}
+		token, err := c.token(challenge, repository)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = send("Bearer " + token); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s error: %s %s", method, u, resp.Status, body)
+	}
+	return resp, nil
+}
+
+// challengeParam matches parameters of auth challenges, for example, 'realm="https://auth.docker.io/token"'.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// token requests a token from the auth server in the bearer challenge.
+func (c *Client) token(challenge, repository string) (string, error) {
+	params := make(map[string]string)
+	for _, m := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid auth challenge '%s'", challenge)
+	}
+
+	query := realm.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", repository))
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("request token from %s error: %s", realm.Host, resp.Status)
+	}
+
+	result := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.Token != "" {
+		return result.Token, nil
+	}
+	return result.AccessToken, nil
+}
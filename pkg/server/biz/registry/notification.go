@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/caicloud/nirvana/service"
+)
+
+// DockerEventsMIME is the content type of Docker Registry v2 notifications.
+const DockerEventsMIME = "application/vnd.docker.distribution.events.v1+json"
+
+// Push is an image tag pushed to registry.
+type Push struct {
+	// Repository of the image, for example, 'library/golang'.
+	Repository string
+	// Tag pushed
+	Tag string
+	// Digest of the image manifest
+	Digest string
+	// EventID is ID of the notification event, it's empty if not provided by the registry.
+	EventID string
+}
+
+// Notification is a notification sent by registries on events, both Docker Registry v2
+// notifications and Harbor webhooks are supported.
+type Notification struct {
+	// Events are events of Docker Registry v2 notifications.
+	Events []DockerEvent `json:"events,omitempty"`
+	// Type is the event type of Harbor webhooks, for example, 'pushImage' or 'PUSH_ARTIFACT'.
+	Type string `json:"type,omitempty"`
+	// EventData is the event data of Harbor webhooks.
+	EventData *HarborEventData `json:"event_data,omitempty"`
+}
+
+// DockerEvent is an event of Docker Registry v2 notifications.
+type DockerEvent struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Target struct {
+		MediaType  string `json:"mediaType"`
+		Digest     string `json:"digest"`
+		Repository string `json:"repository"`
+		Tag        string `json:"tag"`
+	} `json:"target"`
+}
+
+// HarborEventData is the event data of Harbor webhooks.
+type HarborEventData struct {
+	Resources []struct {
+		Digest string `json:"digest"`
+		Tag    string `json:"tag"`
+	} `json:"resources"`
+	Repository struct {
+		RepoFullName string `json:"repo_full_name"`
+	} `json:"repository"`
+}
+
+// harborPushTypes are event types of image pushes in Harbor webhooks, for Harbor 1.x and 2.x.
+var harborPushTypes = map[string]bool{
+	"pushImage":     true,
+	"PUSH_ARTIFACT": true,
+}
+
+// Pushes gets pushes of tagged images in the notification, other events, such as pulls and
+// pushes of layers, are ignored.
+func (n *Notification) Pushes() []Push {
+	var pushes []Push
+	for _, e := range n.Events {
+		if e.Action != "push" || e.Target.Tag == "" {
+			continue
+		}
+		pushes = append(pushes, Push{
+			Repository: e.Target.Repository,
+			Tag:        e.Target.Tag,
+			Digest:     e.Target.Digest,
+			EventID:    e.ID,
+		})
+	}
+
+	if n.EventData != nil && harborPushTypes[n.Type] {
+		for _, r := range n.EventData.Resources {
+			if r.Tag == "" {
+				continue
+			}
+			pushes = append(pushes, Push{
+				Repository: n.EventData.Repository.RepoFullName,
+				Tag:        r.Tag,
+				Digest:     r.Digest,
+			})
+		}
+	}
+	return pushes
+}
+
+// DockerEventsConsumer consumes Docker Registry v2 notifications, whose content type is not
+// plain JSON.
+type DockerEventsConsumer struct{}
+
+var _ service.Consumer = (*DockerEventsConsumer)(nil)
+
+// ContentType ...
+func (c *DockerEventsConsumer) ContentType() string {
+	return DockerEventsMIME
+}
+
+// Consume ...
+func (c *DockerEventsConsumer) Consume(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+func TestPushes(t *testing.T) {
+	cases := map[string]struct {
+		body   string
+		pushes []Push
+	}{
+		"docker": {
+			body: `{"events": [
+				{"id": "e1", "action": "push", "target": {"mediaType": "application/octet-stream", "digest": "sha256:layer", "repository": "library/golang"}},
+				{"id": "e2", "action": "push", "target": {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "digest": "sha256:a", "repository": "library/golang", "tag": "1.12"}},
+				{"id": "e3", "action": "pull", "target": {"digest": "sha256:a", "repository": "library/golang", "tag": "1.12"}}
+			]}`,
+			pushes: []Push{{Repository: "library/golang", Tag: "1.12", Digest: "sha256:a", EventID: "e2"}},
+		},
+		"harbor": {
+			body: `{"type": "pushImage", "event_data": {
+				"resources": [{"digest": "sha256:b", "tag": "v1"}],
+				"repository": {"name": "nginx", "namespace": "library", "repo_full_name": "library/nginx"}
+			}}`,
+			pushes: []Push{{Repository: "library/nginx", Tag: "v1", Digest: "sha256:b"}},
+		},
+		"harbor delete": {
+			body: `{"type": "deleteImage", "event_data": {
+				"resources": [{"digest": "sha256:b", "tag": "v1"}],
+				"repository": {"repo_full_name": "library/nginx"}
+			}}`,
+		},
+	}
+
+	for name, c := range cases {
+		n := &Notification{}
+		assert.Nil(t, json.Unmarshal([]byte(c.body), n), name)
+		assert.Equal(t, c.pushes, n.Pushes(), name)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	assert.Equal(t, "https://registry-1.docker.io", Endpoint("docker.io"))
+	assert.Equal(t, "http://registry.local:5000", Endpoint("http://registry.local:5000/"))
+	assert.Equal(t, "registry.local:5000", Host("http://registry.local:5000/"))
+}
+
+func TestClient(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			user, password, _ := r.BasicAuth()
+			if user != "u" || password != "p" || r.URL.Query().Get("scope") != "repository:library/golang:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"token": "t"}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer t" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/library/golang/tags/list":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/library/golang/tags/list?n=2&last=1.11>; rel="next"`)
+				w.Write([]byte(`{"tags": ["1.10", "1.11"]}`))
+			} else {
+				w.Write([]byte(`{"tags": ["1.12"]}`))
+			}
+		case "/v2/library/golang/manifests/1.12":
+			w.Header().Set("Docker-Content-Digest", "sha256:a")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(&api.DockerRegistrySource{Server: server.URL, User: "u", Password: "p"})
+	tags, err := c.Tags("library/golang")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.10", "1.11", "1.12"}, tags)
+
+	digest, err := c.Digest("library/golang", "1.12")
+	assert.Nil(t, err)
+	assert.Equal(t, "sha256:a", digest)
+
+	_, err = c.Digest("library/golang", "1.13")
+	assert.NotNil(t, err)
+
+	c = NewClient(&api.DockerRegistrySource{Server: server.URL, User: "u", Password: "wrong"})
+	_, err = c.Tags("library/golang")
+	assert.NotNil(t, err)
+}
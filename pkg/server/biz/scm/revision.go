@@ -0,0 +1,137 @@
+package scm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+// commandTimeout is the timeout of commands to get head revisions.
+const commandTimeout = time.Minute
+
+// gitCredentialHelper is the Git credential helper to provide credentials from environment
+// variables, so that credentials are not passed in command arguments, which are visible to all
+// users on the node.
+const gitCredentialHelper = `!f() { test "$1" = get || return 0; echo "username=${SCM_USERNAME}"; echo "password=${SCM_PASSWORD}"; }; f`
+
+// ValidateRepository checks the repository is a URL to get head revisions from, only http, https,
+// ssh, svn and svn+ssh URLs are supported. Other values, such as local paths and values starting
+// with '-', are rejected, since they may be interpreted as options of git or svn commands.
+func ValidateRepository(repository string) error {
+	u, err := url.Parse(repository)
+	if err != nil {
+		return fmt.Errorf("invalid repository URL '%s': %v", repository, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "ssh", "svn", "svn+ssh":
+	default:
+		return fmt.Errorf("unsupported repository '%s', it should be a http, https, ssh, svn or svn+ssh URL", repository)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host of repository URL '%s' is empty", repository)
+	}
+	return nil
+}
+
+// HeadRevision gets head revision of the branch in the repository with credentials of the SCM,
+// it's the commit SHA for Git, and the last changed revision number for SVN.
+func HeadRevision(source *api.SCMSource, repository, branch string) (string, error) {
+	if err := ValidateRepository(repository); err != nil {
+		return "", err
+	}
+	if source.Type == api.SVN {
+		return svnHeadRevision(source, repository, branch)
+	}
+	return gitHeadRevision(source, repository, branch)
+}
+
+// gitHeadRevision gets the commit SHA of the branch by 'git ls-remote', the default branch is
+// used if branch is empty.
+func gitHeadRevision(source *api.SCMSource, repository, branch string) (string, error) {
+	ref := "HEAD"
+	if branch != "" {
+		ref = "refs/heads/" + branch
+	}
+
+	var args, env []string
+	if user, password, ok := gitCredentials(source); ok {
+		args = append(args, "-c", "credential.helper=", "-c", "credential.helper="+gitCredentialHelper)
+		env = append(env, "SCM_USERNAME="+user, "SCM_PASSWORD="+password)
+	}
+	out, err := run(env, "", "git", append(args, "ls-remote", "--", repository, ref)...)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("ref %s not found in %s", ref, repository)
+}
+
+// gitCredentials gets the user and password to access HTTP(S) repositories of the SCM.
+func gitCredentials(source *api.SCMSource) (string, string, bool) {
+	switch {
+	case source.AuthType == api.AuthTypeToken && source.Token != "":
+		if source.Type == api.GitLab {
+			return "oauth2", source.Token, true
+		}
+		return source.Token, "", true
+	case source.User != "":
+		return source.User, source.Password, true
+	default:
+		return "", "", false
+	}
+}
+
+// svnHeadRevision gets the last changed revision of the branch path by 'svn info'.
+func svnHeadRevision(source *api.SCMSource, repository, branch string) (string, error) {
+	target := strings.TrimSuffix(repository, "/")
+	if branch != "" {
+		target += "/" + strings.TrimPrefix(branch, "/")
+	}
+
+	args := []string{"info", "--show-item", "last-changed-revision", "--non-interactive", "--no-auth-cache", "--trust-server-cert"}
+	var stdin string
+	if source.User != "" {
+		// Password is read from stdin to keep it out of command arguments.
+		args = append(args, "--username", source.User, "--password-from-stdin")
+		stdin = source.Password
+	}
+	out, err := run(nil, stdin, "svn", append(args, "--", target)...)
+	if err != nil {
+		return "", err
+	}
+	revision := strings.TrimSpace(out)
+	if revision == "" {
+		return "", fmt.Errorf("no revision found for %s", target)
+	}
+	return revision, nil
+}
+
+// run runs the command with the additional environment variables and stdin, and returns its
+// output. Interactive prompts for credentials are disabled.
+func run(env []string, stdin string, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s error: %v, %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
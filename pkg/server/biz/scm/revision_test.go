@@ -0,0 +1,132 @@
+package scm
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@cyclone.dev",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@cyclone.dev")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v error: %v, %s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestGitHeadRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir, err := ioutil.TempDir("", "scm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	remote := filepath.Join(dir, "remote.git")
+	work := filepath.Join(dir, "work")
+	git(t, dir, "init", "--bare", remote)
+	git(t, dir, "clone", remote, work)
+	git(t, work, "checkout", "-b", "master")
+	git(t, work, "commit", "--allow-empty", "-m", "first")
+	git(t, work, "push", "origin", "master")
+	git(t, work, "symbolic-ref", "HEAD", "refs/heads/master")
+
+	// Local repositories are only supported internally, HeadRevision requires URLs.
+	source := &api.SCMSource{Type: api.GitLab}
+	_, err = HeadRevision(source, remote, "master")
+	assert.NotNil(t, err)
+	revision, err := gitHeadRevision(source, remote, "master")
+	assert.Nil(t, err)
+	assert.Equal(t, git(t, work, "rev-parse", "HEAD")[:40], revision)
+
+	git(t, work, "commit", "--allow-empty", "-m", "second")
+	git(t, work, "push", "origin", "master")
+	latest, err := gitHeadRevision(source, remote, "master")
+	assert.Nil(t, err)
+	assert.NotEqual(t, revision, latest)
+	assert.Equal(t, git(t, work, "rev-parse", "HEAD")[:40], latest)
+
+	_, err = gitHeadRevision(source, remote, "missing")
+	assert.NotNil(t, err)
+	_, err = gitHeadRevision(source, filepath.Join(dir, "missing.git"), "master")
+	assert.NotNil(t, err)
+
+	// Repository is never interpreted as an option.
+	injected := filepath.Join(dir, "injected")
+	_, err = gitHeadRevision(source, "--upload-pack=touch "+injected, "master")
+	assert.NotNil(t, err)
+	_, err = os.Stat(injected)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestValidateRepository(t *testing.T) {
+	cases := map[string]bool{
+		"https://github.com/caicloud/cyclone.git": true,
+		"http://gitlab.example.com/a/b.git":       true,
+		"ssh://git@github.com/caicloud/cyclone":   true,
+		"svn://svn.example.com/repo":              true,
+		"svn+ssh://svn.example.com/repo":          true,
+		"--upload-pack=touch /tmp/x":              false,
+		"/tmp/repo.git":                           false,
+		"file:///tmp/repo.git":                    false,
+		"ext::sh -c touch% /tmp/x":                false,
+		"git@github.com:caicloud/cyclone.git":     false,
+		"https:///caicloud/cyclone.git":           false,
+		"":                                        false,
+	}
+	for repository, valid := range cases {
+		assert.Equal(t, valid, ValidateRepository(repository) == nil, repository)
+	}
+
+	_, err := HeadRevision(&api.SCMSource{Type: api.GitLab}, "--upload-pack=touch /tmp/x", "master")
+	assert.NotNil(t, err)
+	_, err = HeadRevision(&api.SCMSource{Type: api.SVN}, "--config-option=x", "trunk")
+	assert.NotNil(t, err)
+}
+
+func TestGitCredentials(t *testing.T) {
+	cases := []struct {
+		source   *api.SCMSource
+		user     string
+		password string
+		ok       bool
+	}{
+		{&api.SCMSource{Type: api.GitHub, AuthType: api.AuthTypeToken, Token: "t"}, "t", "", true},
+		{&api.SCMSource{Type: api.GitLab, AuthType: api.AuthTypeToken, Token: "t"}, "oauth2", "t", true},
+		{&api.SCMSource{Type: api.GitLab, AuthType: api.AuthTypePassword, User: "u", Password: "p"}, "u", "p", true},
+		{&api.SCMSource{Type: api.GitLab}, "", "", false},
+	}
+	for _, c := range cases {
+		user, password, ok := gitCredentials(c.source)
+		assert.Equal(t, c.user, user)
+		assert.Equal(t, c.password, password)
+		assert.Equal(t, c.ok, ok)
+	}
+}
+
+func TestGitCredentialHelper(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	cmd := exec.Command("git", "-c", "credential.helper=", "-c", "credential.helper="+gitCredentialHelper, "credential", "fill")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "SCM_USERNAME=u", "SCM_PASSWORD=p")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=github.com\n\n")
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(out))
+	assert.Contains(t, string(out), "username=u\n")
+	assert.Contains(t, string(out), "password=p\n")
+}
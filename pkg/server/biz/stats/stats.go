@@ -0,0 +1,116 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/history"
+)
+
+// slowestStages is the number of slowest stages to return.
+const slowestStages = 5
+
+// Compute computes statistics of WorkflowRuns started in the time window from their summaries,
+// summaries of WorkflowRuns not deleted yet should be merged with archived ones by history.Merge.
+// Zero start or end time means the window is unbounded on that side.
+func Compute(summaries []api.WorkflowRunSummary, start, end time.Time) *api.WorkflowRunStats {
+	in := func(t time.Time) bool {
+		return (start.IsZero() || !t.Before(start)) && (end.IsZero() || !t.After(end))
+	}
+
+	stats := &api.WorkflowRunStats{
+		Statuses:      make(map[string]int),
+		SlowestStages: []api.StageStats{},
+	}
+
+	var durations []int64
+	completed := 0
+	stages := make(map[string]*stageStats)
+	for i := range summaries {
+		s := &summaries[i]
+		if !in(s.Status.StartTime.Time) {
+			continue
+		}
+		stats.Total++
+		stats.Statuses[s.Status.Status]++
+		if !history.Terminated(s.Status.Status) {
+			continue
+		}
+		if s.Status.Status == v1alpha1.StatusCompleted {
+			completed++
+		}
+		durations = append(durations, history.Duration(&s.Status))
+
+		for name, stage := range s.Stages {
+			if !history.Terminated(stage.Status.Status) {
+				continue
+			}
+			key := s.Workflow + "/" + name
+			if _, ok := stages[key]; !ok {
+				stages[key] = &stageStats{StageStats: api.StageStats{Workflow: s.Workflow, Stage: name}}
+			}
+			stages[key].add(&stage.Status)
+		}
+	}
+
+	if len(durations) > 0 {
+		stats.SuccessRate = completed * 100 / len(durations)
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		stats.MedianDuration = percentile(durations, 0.5)
+		stats.P95Duration = percentile(durations, 0.95)
+	}
+
+	var results []api.StageStats
+	for _, s := range stages {
+		results = append(results, s.result())
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].AverageDuration != results[j].AverageDuration {
+			return results[i].AverageDuration > results[j].AverageDuration
+		}
+		return results[i].Workflow+"/"+results[i].Stage < results[j].Workflow+"/"+results[j].Stage
+	})
+	for i, s := range results {
+		if i < slowestStages {
+			stats.SlowestStages = append(stats.SlowestStages, s)
+		}
+		if s.Failures > 0 && (stats.MostFailedStage == nil || s.Failures > stats.MostFailedStage.Failures) {
+			failed := s
+			stats.MostFailedStage = &failed
+		}
+	}
+
+	return stats
+}
+
+// percentile gets the percentile of sorted values with nearest rank method.
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// stageStats accumulates statistics of a stage.
+type stageStats struct {
+	api.StageStats
+	total int64
+}
+
+func (s *stageStats) add(status *v1alpha1.Status) {
+	s.Runs++
+	if status.Status == v1alpha1.StatusError {
+		s.Failures++
+	}
+	s.total += history.Duration(status)
+}
+
+func (s *stageStats) result() api.StageStats {
+	result := s.StageStats
+	result.AverageDuration = s.total / int64(s.Runs)
+	return result
+}
@@ -0,0 +1,93 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/history"
+)
+
+func status(status string, start, duration int64) v1alpha1.Status {
+	return v1alpha1.Status{
+		Status:         status,
+		StartTime:      metav1.Time{Time: time.Unix(start, 0)},
+		CompletionTime: metav1.Time{Time: time.Unix(start+duration, 0)},
+	}
+}
+
+func summary(name, s string, start, duration int64, stages map[string]api.StageSummary) api.WorkflowRunSummary {
+	return api.WorkflowRunSummary{
+		Name:         name,
+		Workflow:     "wf",
+		CreationTime: metav1.Time{Time: time.Unix(start, 0)},
+		Status:       status(s, start, duration),
+		Stages:       stages,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	archived := []api.WorkflowRunSummary{
+		summary("wf-1", v1alpha1.StatusCompleted, 100, 10, map[string]api.StageSummary{
+			"build": {Status: status(v1alpha1.StatusCompleted, 100, 6)},
+			"test":  {Status: status(v1alpha1.StatusCompleted, 106, 4)},
+		}),
+		summary("wf-2", v1alpha1.StatusError, 200, 20, map[string]api.StageSummary{
+			"build": {Status: status(v1alpha1.StatusCompleted, 200, 8)},
+			"test":  {Status: status(v1alpha1.StatusError, 208, 12)},
+		}),
+		summary("wf-3", v1alpha1.StatusError, 300, 30, map[string]api.StageSummary{
+			"build": {Status: status(v1alpha1.StatusError, 300, 30)},
+		}),
+		summary("wf-4", v1alpha1.StatusError, 400, 40, map[string]api.StageSummary{
+			"build": {Status: status(v1alpha1.StatusCompleted, 400, 10)},
+			"test":  {Status: status(v1alpha1.StatusError, 410, 30)},
+		}),
+		// Outside the window.
+		summary("wf-0", v1alpha1.StatusCompleted, 10, 1000, nil),
+	}
+	wfrs := []v1alpha1.WorkflowRun{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "wf-4", CreationTimestamp: metav1.Time{Time: time.Unix(400, 0)}},
+			Spec:       v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: "wf"}},
+			Status: v1alpha1.WorkflowRunStatus{
+				Overall: status(v1alpha1.StatusCompleted, 400, 40),
+				Stages: map[string]*v1alpha1.StageStatus{
+					"build": {Status: status(v1alpha1.StatusCompleted, 400, 10)},
+					"test":  {Status: status(v1alpha1.StatusCompleted, 410, 30)},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "wf-5", CreationTimestamp: metav1.Time{Time: time.Unix(500, 0)}},
+			Spec:       v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: "wf"}},
+			Status:     v1alpha1.WorkflowRunStatus{Overall: v1alpha1.Status{Status: v1alpha1.StatusRunning}},
+		},
+	}
+
+	// WorkflowRuns not deleted yet take precedence over archived summaries.
+	stats := Compute(history.Merge(archived, wfrs), time.Unix(50, 0), time.Time{})
+	assert.Equal(t, 5, stats.Total)
+	assert.Equal(t, map[string]int{
+		v1alpha1.StatusCompleted: 2,
+		v1alpha1.StatusError:     2,
+		v1alpha1.StatusRunning:   1,
+	}, stats.Statuses)
+	assert.Equal(t, 50, stats.SuccessRate)
+	assert.Equal(t, int64(20), stats.MedianDuration)
+	assert.Equal(t, int64(40), stats.P95Duration)
+	assert.Equal(t, []api.StageStats{
+		{Workflow: "wf", Stage: "test", Runs: 3, Failures: 1, AverageDuration: 15},
+		{Workflow: "wf", Stage: "build", Runs: 4, Failures: 1, AverageDuration: 13},
+	}, stats.SlowestStages)
+	assert.Equal(t, "test", stats.MostFailedStage.Stage)
+
+	stats = Compute(nil, time.Time{}, time.Time{})
+	assert.Equal(t, 0, stats.Total)
+	assert.Nil(t, stats.MostFailedStage)
+}
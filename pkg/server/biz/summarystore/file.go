@@ -0,0 +1,275 @@
+package summarystore
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caicloud/nirvana/log"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+// dbExtension is the extension of database files.
+const dbExtension = ".db"
+
+// fileStore is an embedded database storing summaries in local files. Summaries of a workflow
+// are appended to one file as JSON lines, '<root>/<namespace>/<workflow>.db', the later one
+// of a workflowrun overrides the former. When a summary is put, the file is compacted if more
+// than half of its lines are superseded, expired or broken, or summaries are beyond the limit.
+type fileStore struct {
+	root string
+	// maxSummaries is the maximum number of summaries kept for a workflow, oldest ones are
+	// dropped first. 0 means no limit.
+	maxSummaries int
+	// retention is how long summaries are kept after workflowruns are created, 0 means forever.
+	retention time.Duration
+	lock      sync.RWMutex
+}
+
+func newFileStore(root string, maxSummaries int, retention time.Duration) *fileStore {
+	return &fileStore{
+		root:         root,
+		maxSummaries: maxSummaries,
+		retention:    retention,
+	}
+}
+
+func (s *fileStore) path(namespace, workflow string) (string, error) {
+	for _, name := range []string{namespace, workflow} {
+		if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return "", fmt.Errorf("invalid namespace or workflow name '%s'", name)
+		}
+	}
+	return filepath.Join(s.root, namespace, workflow+dbExtension), nil
+}
+
+// Put ...
+func (s *fileStore) Put(namespace string, summary *api.WorkflowRunSummary) error {
+	if summary.Name == "" {
+		return fmt.Errorf("workflowrun name can not be empty")
+	}
+	path, err := s.path(namespace, summary.Workflow)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	summaries, lines, err := s.read(path)
+	if err != nil {
+		return err
+	}
+	summaries[summary.Name] = summary
+	if lines+1 > len(summaries)*2 || (s.maxSummaries > 0 && len(summaries) > s.maxSummaries) {
+		return s.compact(path, summaries)
+	}
+
+	return appendLine(path, data)
+}
+
+// compact rewrites the database file with the newest summaries within the limit. It's written
+// to a temporary file first and then renamed, so that the file won't be broken if the server
+// crashed in the middle.
+func (s *fileStore) compact(path string, summaries map[string]*api.WorkflowRunSummary) error {
+	sorted := sortSummaries(summaries)
+	if s.maxSummaries > 0 && len(sorted) > s.maxSummaries {
+		sorted = sorted[:s.maxSummaries]
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	// Oldest first, as if they were appended in order.
+	w := bufio.NewWriter(tmp)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		data, err := json.Marshal(&sorted[i])
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// appendLine appends a JSON line to the database file.
+func appendLine(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	// Terminate the partially written line if any, so that it won't break the new one.
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Get ...
+func (s *fileStore) Get(namespace, workflow, workflowrun string) (*api.WorkflowRunSummary, error) {
+	path, err := s.path(namespace, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	summaries, _, err := s.read(path)
+	if err != nil {
+		return nil, err
+	}
+	summary, ok := summaries[workflowrun]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return summary, nil
+}
+
+// List ...
+func (s *fileStore) List(namespace string, filter Filter) ([]api.WorkflowRunSummary, error) {
+	var paths []string
+	if filter.Workflow != "" {
+		path, err := s.path(namespace, filter.Workflow)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	} else {
+		dir, err := s.path(namespace, "-")
+		if err != nil {
+			return nil, err
+		}
+		files, err := ioutil.ReadDir(filepath.Dir(dir))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, f := range files {
+			if !f.IsDir() && strings.HasSuffix(f.Name(), dbExtension) {
+				paths = append(paths, filepath.Join(filepath.Dir(dir), f.Name()))
+			}
+		}
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	results := make([]api.WorkflowRunSummary, 0)
+	for _, path := range paths {
+		summaries, _, err := s.read(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range summaries {
+			if filter.Match(summary) {
+				results = append(results, *summary)
+			}
+		}
+	}
+
+	sortByCreation(results)
+	return results, nil
+}
+
+// sortSummaries gets summaries sorted by creation time, newest first.
+func sortSummaries(summaries map[string]*api.WorkflowRunSummary) []api.WorkflowRunSummary {
+	results := make([]api.WorkflowRunSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		results = append(results, *summary)
+	}
+	sortByCreation(results)
+	return results
+}
+
+func sortByCreation(summaries []api.WorkflowRunSummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		if !summaries[i].CreationTime.Equal(&summaries[j].CreationTime) {
+			return summaries[j].CreationTime.Before(&summaries[i].CreationTime)
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+}
+
+// read reads summaries in the database file keyed by workflowrun name, and the number of lines
+// in the file, an empty map is returned if the file doesn't exist. Expired summaries and broken
+// lines, for example, partially written ones when the server crashed, are skipped.
+func (s *fileStore) read(path string) (map[string]*api.WorkflowRunSummary, int, error) {
+	summaries := make(map[string]*api.WorkflowRunSummary)
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return summaries, 0, nil
+		}
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	var expiration time.Time
+	if s.retention > 0 {
+		expiration = time.Now().Add(-s.retention)
+	}
+	lines := 0
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lines++
+		}
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			summary := &api.WorkflowRunSummary{}
+			if e := json.Unmarshal(line, summary); e != nil {
+				log.Warningf("Skip broken summary in %s: %v", path, e)
+			} else if summary.CreationTime.Time.Before(expiration) {
+				delete(summaries, summary.Name)
+			} else {
+				summaries[summary.Name] = summary
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return summaries, lines, nil
+}
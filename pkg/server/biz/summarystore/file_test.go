@@ -0,0 +1,136 @@
+package summarystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/config"
+)
+
+func summary(name, project, workflow, status string, created int64) *api.WorkflowRunSummary {
+	return &api.WorkflowRunSummary{
+		Name:         name,
+		Project:      project,
+		Workflow:     workflow,
+		CreationTime: metav1.Time{Time: time.Unix(created, 0)},
+		Status:       v1alpha1.Status{Status: status},
+	}
+}
+
+func names(summaries []api.WorkflowRunSummary) []string {
+	var results []string
+	for _, s := range summaries {
+		results = append(results, s.Name)
+	}
+	return results
+}
+
+func TestFileStore(t *testing.T) {
+	root, err := ioutil.TempDir("", "summarystore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	store, err := New(config.HistoryConfig{})
+	assert.Nil(t, err)
+	assert.NotNil(t, store)
+	store = newFileStore(root, 0, 0)
+
+	_, err = store.Get("ns", "wf1", "wfr1")
+	assert.Equal(t, ErrNotFound, err)
+	summaries, err := store.List("ns", Filter{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(summaries))
+
+	assert.Nil(t, store.Put("ns", summary("wfr1", "p1", "wf1", v1alpha1.StatusRunning, 1)))
+	assert.Nil(t, store.Put("ns", summary("wfr2", "p1", "wf1", v1alpha1.StatusError, 2)))
+	assert.Nil(t, store.Put("ns", summary("wfr3", "p1", "wf2", v1alpha1.StatusCompleted, 3)))
+	assert.Nil(t, store.Put("ns", summary("wfr4", "p2", "wf3", v1alpha1.StatusCompleted, 4)))
+	// Replaces the former summary of wfr1.
+	assert.Nil(t, store.Put("ns", summary("wfr1", "p1", "wf1", v1alpha1.StatusCompleted, 1)))
+
+	s, err := store.Get("ns", "wf1", "wfr1")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha1.StatusCompleted, s.Status.Status)
+
+	summaries, err = store.List("ns", Filter{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"wfr4", "wfr3", "wfr2", "wfr1"}, names(summaries))
+	summaries, err = store.List("ns", Filter{Project: "p1", Status: v1alpha1.StatusCompleted})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"wfr3", "wfr1"}, names(summaries))
+	summaries, err = store.List("ns", Filter{Workflow: "wf1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"wfr2", "wfr1"}, names(summaries))
+
+	// Partially written line is skipped.
+	f, err := os.OpenFile(filepath.Join(root, "ns", "wf1"+dbExtension), os.O_WRONLY|os.O_APPEND, 0644)
+	assert.Nil(t, err)
+	_, err = f.Write([]byte(`{"name": "wfr5"`))
+	assert.Nil(t, err)
+	f.Close()
+	summaries, err = store.List("ns", Filter{Workflow: "wf1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"wfr2", "wfr1"}, names(summaries))
+	assert.Nil(t, store.Put("ns", summary("wfr6", "p1", "wf1", v1alpha1.StatusCompleted, 6)))
+	summaries, err = store.List("ns", Filter{Workflow: "wf1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"wfr6", "wfr2", "wfr1"}, names(summaries))
+
+	assert.NotNil(t, store.Put("ns", summary("wfr7", "p1", "../wf1", v1alpha1.StatusCompleted, 6)))
+	_, err = store.List("..", Filter{})
+	assert.NotNil(t, err)
+}
+
+func lines(t *testing.T, path string) int {
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	return strings.Count(string(data), "\n")
+}
+
+func TestFileStoreCompact(t *testing.T) {
+	root, err := ioutil.TempDir("", "summarystore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+	path := filepath.Join(root, "ns", "wf1"+dbExtension)
+
+	now := time.Now().Unix()
+	store := newFileStore(root, 3, 24*time.Hour)
+	assert.Nil(t, store.Put("ns", summary("wfr1", "p1", "wf1", v1alpha1.StatusRunning, now+1)))
+	assert.Nil(t, store.Put("ns", summary("wfr2", "p1", "wf1", v1alpha1.StatusRunning, now+2)))
+	assert.Equal(t, 2, lines(t, path))
+
+	// Compacted once more than half of lines are superseded.
+	assert.Nil(t, store.Put("ns", summary("wfr1", "p1", "wf1", v1alpha1.StatusRunning, now+1)))
+	assert.Nil(t, store.Put("ns", summary("wfr1", "p1", "wf1", v1alpha1.StatusCompleted, now+1)))
+	assert.Equal(t, 4, lines(t, path))
+	assert.Nil(t, store.Put("ns", summary("wfr1", "p1", "wf1", v1alpha1.StatusError, now+1)))
+	assert.Equal(t, 2, lines(t, path))
+	s, err := store.Get("ns", "wf1", "wfr1")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha1.StatusError, s.Status.Status)
+
+	// Oldest summaries beyond the limit are dropped.
+	assert.Nil(t, store.Put("ns", summary("wfr3", "p1", "wf1", v1alpha1.StatusCompleted, now+3)))
+	assert.Nil(t, store.Put("ns", summary("wfr4", "p1", "wf1", v1alpha1.StatusCompleted, now+4)))
+	summaries, err := store.List("ns", Filter{Workflow: "wf1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"wfr4", "wfr3", "wfr2"}, names(summaries))
+	assert.Equal(t, 3, lines(t, path))
+
+	// Expired summaries are skipped, and dropped once compacted.
+	assert.Nil(t, store.Put("ns", summary("wfr0", "p1", "wf1", v1alpha1.StatusCompleted, now-2*24*3600)))
+	summaries, err = store.List("ns", Filter{Workflow: "wf1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"wfr4", "wfr3", "wfr2"}, names(summaries))
+	_, err = store.Get("ns", "wf1", "wfr0")
+	assert.Equal(t, ErrNotFound, err)
+}
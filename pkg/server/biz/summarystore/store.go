@@ -0,0 +1,50 @@
+package summarystore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/server/config"
+)
+
+// ErrNotFound is returned when the requested summary doesn't exist.
+var ErrNotFound = errors.New("workflowrun summary not found")
+
+// Filter selects summaries to list, empty fields match all.
+type Filter struct {
+	Project  string
+	Workflow string
+	Status   string
+}
+
+// Match checks whether the summary is selected by the filter.
+func (f Filter) Match(s *api.WorkflowRunSummary) bool {
+	return (f.Project == "" || f.Project == s.Project) &&
+		(f.Workflow == "" || f.Workflow == s.Workflow) &&
+		(f.Status == "" || f.Status == s.Status.Status)
+}
+
+// Store stores summaries of workflowruns, summaries are kept after workflowruns are deleted.
+type Store interface {
+	// Put stores summary of a workflowrun, the existing one of the same workflowrun is replaced.
+	Put(namespace string, summary *api.WorkflowRunSummary) error
+
+	// Get gets summary of a workflowrun, ErrNotFound is returned if it doesn't exist.
+	Get(namespace, workflow, workflowrun string) (*api.WorkflowRunSummary, error)
+
+	// List lists summaries selected by the filter, sorted by creation time, newest first.
+	List(namespace string, filter Filter) ([]api.WorkflowRunSummary, error)
+}
+
+// New creates a summary store according to the history configuration.
+func New(cfg config.HistoryConfig) (Store, error) {
+	switch cfg.Backend {
+	case common.HistoryBackendFile, "":
+		return newFileStore(cfg.Root, cfg.MaxSummaries, time.Duration(cfg.RetentionDays)*24*time.Hour), nil
+	default:
+		return nil, fmt.Errorf("unsupported history backend '%s'", cfg.Backend)
+	}
+}
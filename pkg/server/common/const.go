@@ -24,6 +24,12 @@ const (
 	// LabelWorkflowName is the label key used to indicate the workflow which the resources belongs to
 	LabelWorkflowName = "cyclone.io/workflow-name"
 
+	// LabelWorkflowTriggerName is the label key used to indicate the workflowtrigger that started the workflowrun
+	LabelWorkflowTriggerName = "cyclone.io/workflowtrigger-name"
+
+	// LabelTriggerType is the label key used to indicate type of the source that started the workflowrun
+	LabelTriggerType = "cyclone.io/trigger-type"
+
 	// LabelIntegrationType is the label key used to indicate type of integration
 	LabelIntegrationType = "cyclone.io/integration-type"
 
@@ -77,4 +83,23 @@ const (
 
 	// ControlClusterName is the name of control cluster
 	ControlClusterName = "control-cluster"
+
+	// CycloneHome is the home folder for Cyclone.
+	CycloneHome = "/var/lib/cyclone"
+
+	// LogsBackendLocal represents storing logs in local file system
+	LogsBackendLocal = "local"
+	// LogsBackendS3 represents storing logs in S3 compatible object storage
+	LogsBackendS3 = "s3"
+
+	// HistoryBackendFile represents storing workflowrun summaries in files of an embedded database
+	HistoryBackendFile = "file"
+	// DefaultHistoryRoot is the default folder to store workflowrun summaries, it's out of
+	// CycloneHome, which is the default root of logs, so that summaries are not pruned with logs.
+	DefaultHistoryRoot = "/var/lib/cyclone-history"
+	// DefaultHistoryMaxSummaries is the default maximum number of summaries kept for a workflow
+	DefaultHistoryMaxSummaries = 1000
+
+	// DefaultS3Region is the default region of S3 bucket
+	DefaultS3Region = "us-east-1"
 )
@@ -3,6 +3,8 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/caicloud/nirvana/log"
 	core_v1 "k8s.io/api/core/v1"
@@ -31,6 +33,73 @@ type CycloneServerConfig struct {
 	// WorkerNamespaceQuota describes the resource quota of the namespace which will be used to run workflows,
 	// eg map[core_v1.ResourceName]string{"cpu": "2", "memory": "4Gi"}
 	WorkerNamespaceQuota map[core_v1.ResourceName]string `json:"worker_namespace_quota"`
+
+	// Logs configures how workflowrun logs are stored
+	Logs LogsConfig `json:"logs"`
+
+	// History configures how summaries of workflowruns are stored after workflowruns are pruned
+	History HistoryConfig `json:"history"`
+}
+
+// LogsConfig configures the storage of workflowrun logs.
+type LogsConfig struct {
+	// Backend is the storage backend of logs, supports 'local' and 's3', default is 'local'.
+	Backend string `json:"backend"`
+
+	// Root is the local folder logs are stored in, for 's3' backend, it's used to spool logs
+	// being received. Default is '/var/lib/cyclone'.
+	Root string `json:"root"`
+
+	// MaxSizeMB is the maximum size of one container log in MB, content beyond it will be
+	// dropped and a truncation marker appended. 0 means no limit.
+	MaxSizeMB int64 `json:"max_size_mb"`
+
+	// Compress indicates whether to gzip logs once they are finished.
+	Compress bool `json:"compress"`
+
+	// RetentionDays is how many days logs of a workflowrun are kept after its last write,
+	// 0 means logs are kept forever.
+	RetentionDays int `json:"retention_days"`
+
+	// S3 configures the S3 compatible storage, only used when backend is 's3'.
+	S3 S3Config `json:"s3"`
+}
+
+// S3Config configures an S3 compatible object storage.
+type S3Config struct {
+	// Endpoint is address of the storage, for example, 'https://s3.us-east-1.amazonaws.com' or 'http://minio:9000'.
+	Endpoint string `json:"endpoint"`
+	// Region is the region of the bucket, default is 'us-east-1'.
+	Region string `json:"region"`
+	// Bucket is name of the bucket to store logs.
+	Bucket string `json:"bucket"`
+	// Prefix is the key prefix of log objects in the bucket.
+	Prefix string `json:"prefix"`
+	// AccessKey is the access key id.
+	AccessKey string `json:"access_key"`
+	// SecretKey is the secret access key.
+	SecretKey string `json:"secret_key"`
+	// VirtualHostStyle indicates whether to access bucket as '<bucket>.<endpoint>', otherwise path
+	// style '<endpoint>/<bucket>' is used.
+	VirtualHostStyle bool `json:"virtual_host_style"`
+}
+
+// HistoryConfig configures the storage of workflowrun summaries.
+type HistoryConfig struct {
+	// Backend is the storage backend of summaries, supports 'file', default is 'file'.
+	Backend string `json:"backend"`
+
+	// Root is the local folder summaries are stored in for 'file' backend. Default is
+	// '/var/lib/cyclone-history'. It can not be in or contain the root folder of logs.
+	Root string `json:"root"`
+
+	// MaxSummaries is the maximum number of summaries kept for a workflow, oldest ones are
+	// dropped first. Default is 1000.
+	MaxSummaries int `json:"max_summaries"`
+
+	// RetentionDays is how many days summaries are kept after workflowruns are created,
+	// 0 means summaries are kept forever.
+	RetentionDays int `json:"retention_days"`
 }
 
 // PVCConfig contains the PVC information
@@ -64,6 +133,9 @@ func LoadConfig(cm *core_v1.ConfigMap) error {
 	}
 
 	modifier(&Config)
+	if err := validate(&Config); err != nil {
+		return err
+	}
 
 	log.Infof("cyclone server config: %v", Config)
 	return nil
@@ -96,4 +168,54 @@ func modifier(config *CycloneServerConfig) {
 		}
 	}
 
+	if config.Logs.Backend == "" {
+		log.Warning("Logs.Backend not configured, will use default value 'local'")
+		config.Logs.Backend = common.LogsBackendLocal
+	}
+
+	if config.Logs.Root == "" {
+		log.Warningf("Logs.Root not configured, will use default value '%s'", common.CycloneHome)
+		config.Logs.Root = common.CycloneHome
+	}
+
+	if config.History.Backend == "" {
+		log.Warning("History.Backend not configured, will use default value 'file'")
+		config.History.Backend = common.HistoryBackendFile
+	}
+
+	if config.History.Root == "" {
+		log.Warningf("History.Root not configured, will use default value '%s'", common.DefaultHistoryRoot)
+		config.History.Root = common.DefaultHistoryRoot
+	}
+
+	if config.History.MaxSummaries <= 0 {
+		log.Warningf("History.MaxSummaries not configured, will use default value %d", common.DefaultHistoryMaxSummaries)
+		config.History.MaxSummaries = common.DefaultHistoryMaxSummaries
+	}
+
+	if config.Logs.S3.Region == "" {
+		config.Logs.S3.Region = common.DefaultS3Region
+	}
+}
+
+// validate validates the config, local folders of logs and summaries can not be nested, otherwise
+// one's files would be regarded as the other's, for example, summaries are deleted when pruning
+// expired logs.
+func validate(config *CycloneServerConfig) error {
+	if config.Logs.Backend != common.LogsBackendLocal || config.History.Backend != common.HistoryBackendFile {
+		return nil
+	}
+	if nested(config.Logs.Root, config.History.Root) || nested(config.History.Root, config.Logs.Root) {
+		return fmt.Errorf("logs root '%s' and history root '%s' can not be nested", config.Logs.Root, config.History.Root)
+	}
+	return nil
+}
+
+// nested checks whether path is the same as or in the folder dir.
+func nested(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
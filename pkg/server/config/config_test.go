@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core_v1 "k8s.io/api/core/v1"
+
+	"github.com/caicloud/cyclone/pkg/server/common"
+)
+
+func TestLoadConfigDefaultRoots(t *testing.T) {
+	cm := &core_v1.ConfigMap{Data: map[string]string{ConfigFileKey: "{}"}}
+	assert.Nil(t, LoadConfig(cm))
+	assert.Equal(t, common.CycloneHome, Config.Logs.Root)
+	assert.Equal(t, common.DefaultHistoryRoot, Config.History.Root)
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		logs    string
+		history string
+		valid   bool
+	}{
+		{"/var/lib/cyclone", "/var/lib/cyclone-history", true},
+		{"/var/lib/cyclone/logs", "/var/lib/cyclone/history", true},
+		{"/var/lib/cyclone", "/var/lib/cyclone/history", false},
+		{"/var/lib/cyclone/logs", "/var/lib/cyclone", false},
+		{"/var/lib/cyclone", "/var/lib/cyclone/", false},
+	}
+	for _, c := range cases {
+		config := &CycloneServerConfig{
+			Logs:    LogsConfig{Backend: common.LogsBackendLocal, Root: c.logs},
+			History: HistoryConfig{Backend: common.HistoryBackendFile, Root: c.history},
+		}
+		assert.Equal(t, c.valid, validate(config) == nil, "%s %s", c.logs, c.history)
+	}
+
+	config := &CycloneServerConfig{
+		Logs:    LogsConfig{Backend: common.LogsBackendS3, Root: "/var/lib/cyclone"},
+		History: HistoryConfig{Backend: common.HistoryBackendFile, Root: "/var/lib/cyclone/history"},
+	}
+	assert.Nil(t, validate(config))
+}
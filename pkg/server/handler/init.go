@@ -4,16 +4,26 @@ import (
 	"encoding/json"
 
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	"github.com/caicloud/cyclone/pkg/server/biz/logstore"
+	"github.com/caicloud/cyclone/pkg/server/biz/summarystore"
 )
 
 var (
 	// K8sClient is used to operate k8s resources
 	K8sClient clientset.Interface
+
+	// LogStore is used to store workflowrun logs
+	LogStore logstore.Store
+
+	// SummaryStore is used to store summaries of workflowruns
+	SummaryStore summarystore.Store
 )
 
 // Init initializes the server resources handlers.
-func Init(c clientset.Interface) {
+func Init(c clientset.Interface, store logstore.Store, summaries summarystore.Store) {
 	K8sClient = c
+	LogStore = store
+	SummaryStore = summaries
 }
 
 // BuildPatch builds string patch from input p map,
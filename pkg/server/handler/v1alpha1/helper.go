@@ -2,42 +2,53 @@ package v1alpha1
 
 import (
 	"fmt"
-	"os"
-	"strings"
+	"time"
 
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
 	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/logstore"
 	"github.com/caicloud/cyclone/pkg/server/common"
 	"github.com/caicloud/cyclone/pkg/server/config"
 	"github.com/caicloud/cyclone/pkg/server/handler"
+	"github.com/caicloud/cyclone/pkg/server/types"
 	"github.com/caicloud/cyclone/pkg/util/cerr"
 	"github.com/caicloud/cyclone/pkg/util/slugify"
 )
 
-const (
-	// cycloneHome is the home folder for Cyclone.
-	cycloneHome = "/var/lib/cyclone"
-
-	// logsFolderName is the folder name for logs files.
-	logsFolderName = "logs"
-)
+// logKey builds the key of a container log in log store.
+func logKey(workflowrun, stage, container, namespace string) logstore.Key {
+	return logstore.Key{
+		Namespace:   namespace,
+		WorkflowRun: workflowrun,
+		Stage:       stage,
+		Container:   container,
+	}
+}
 
-func getLogFilePath(workflowrun, stage, container, namespace string) (string, error) {
-	if workflowrun == "" || stage == "" || container == "" {
-		return "", fmt.Errorf("workflowrun/stage/container/namespace can not be empty")
+// lineQuery converts the log query in request to line query of log store.
+func lineQuery(query *types.LogQuery) (logstore.LineQuery, error) {
+	q := logstore.LineQuery{}
+	if query == nil {
+		return q, nil
 	}
 
-	rf, _ := getLogFolder(workflowrun, stage, namespace)
-	return strings.Join([]string{rf, container}, string(os.PathSeparator)), nil
-}
+	if query.Start < 0 || query.Limit < 0 || query.Tail < 0 {
+		return q, cerr.ErrorParamTypeError.Error("start/limit/tail", "non-negative integer", "negative integer")
+	}
+	q.Start, q.Limit, q.Tail = query.Start, query.Limit, query.Tail
+	q.Timestamps = query.Timestamps
 
-func getLogFolder(workflowrun, stage, namespace string) (string, error) {
-	if workflowrun == "" || stage == "" || namespace == "" {
-		return "", fmt.Errorf("workflowrun/stage/namespace can not be empty")
+	if query.Since != "" {
+		since, err := time.Parse(time.RFC3339Nano, query.Since)
+		if err != nil {
+			return q, cerr.ErrorParamTypeError.Error("since", "RFC3339 time", query.Since)
+		}
+		q.Since = since
 	}
-	return strings.Join([]string{cycloneHome, namespace, workflowrun, stage, logsFolderName}, string(os.PathSeparator)), nil
+
+	return q, nil
 }
 
 // GetMetadata gets metadata of a type of k8s resources
@@ -0,0 +1,94 @@
+package v1alpha1
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/caicloud/nirvana/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/registry"
+	"github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/server/handler"
+	"github.com/caicloud/cyclone/pkg/util/cerr"
+	"github.com/caicloud/cyclone/pkg/workflow/trigger"
+)
+
+// bearerPrefix is the prefix of bearer tokens in the Authorization header.
+const bearerPrefix = "Bearer "
+
+// ReceiveRegistryNotification receives notifications from the registry of the DockerRegistry
+// integration, and fires image triggers matching the pushed tags. Notifications should carry the
+// notification token of the integration. Names of started workflowruns are returned.
+func ReceiveRegistryNotification(ctx context.Context, tenant, integration, authorization string, n *registry.Notification) ([]string, error) {
+	in, err := getIntegration(tenant, integration)
+	if err != nil {
+		return nil, err
+	}
+	if in.Spec.Type != api.DockerRegistry || in.Spec.DockerRegistry == nil {
+		return nil, cerr.ErrorValidationFailed.Error("integration", fmt.Sprintf("type of integration '%s' is not %s", integration, api.DockerRegistry))
+	}
+	if !authorized(in.Spec.DockerRegistry.NotificationToken, authorization) {
+		log.Warningf("Reject unauthorized registry notification for integration %s/%s", tenant, integration)
+		return nil, cerr.ErrorAuthenticationRequired.Error()
+	}
+
+	pushes := n.Pushes()
+	if len(pushes) == 0 {
+		return []string{}, nil
+	}
+
+	wfts, err := handler.K8sClient.CycloneV1alpha1().WorkflowTriggers(common.TenantNamespace(tenant)).List(metav1.ListOptions{})
+	if err != nil {
+		log.Errorf("List workflowtriggers of tenant %s error: %v", tenant, err)
+		return nil, err
+	}
+
+	host := registry.Host(in.Spec.DockerRegistry.Server)
+	started := []string{}
+	for i := range wfts.Items {
+		wft := &wfts.Items[i]
+		for _, push := range pushes {
+			if !matchImageTrigger(wft, integration, push) {
+				continue
+			}
+			name, err := trigger.FireImageTrigger(handler.K8sClient, wft, host, push)
+			if err != nil {
+				log.Errorf("Fire image trigger %s by %s:%s error: %v", wft.Name, push.Repository, push.Tag, err)
+				return nil, err
+			}
+			started = append(started, name)
+		}
+	}
+
+	return started, nil
+}
+
+// authorized checks whether the Authorization header carries the token, either as a bearer token
+// or the token itself. Nothing is authorized if the token is not set.
+func authorized(token, authorization string) bool {
+	if token == "" {
+		return false
+	}
+	authorization = strings.TrimSpace(authorization)
+	if len(authorization) > len(bearerPrefix) && strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		authorization = strings.TrimSpace(authorization[len(bearerPrefix):])
+	}
+	return subtle.ConstantTimeCompare([]byte(authorization), []byte(token)) == 1
+}
+
+// matchImageTrigger checks whether the image trigger should be fired by the push.
+func matchImageTrigger(wft *v1alpha1.WorkflowTrigger, integration string, push registry.Push) bool {
+	spec := wft.Spec.Image
+	if wft.Spec.Type != v1alpha1.TriggerTypeImage || wft.Spec.Disabled {
+		return false
+	}
+	if spec.Integration != integration || spec.Repository != push.Repository {
+		return false
+	}
+	return trigger.MatchTag(spec.Tag, push.Tag)
+}
@@ -0,0 +1,41 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/registry"
+)
+
+func TestMatchImageTrigger(t *testing.T) {
+	wft := &v1alpha1.WorkflowTrigger{
+		Spec: v1alpha1.WorkflowTriggerSpec{
+			Type: v1alpha1.TriggerTypeImage,
+			Image: v1alpha1.ImageTrigger{
+				Integration: "hub",
+				Repository:  "library/golang",
+				Tag:         `1\.\d+`,
+			},
+		},
+	}
+
+	assert.True(t, matchImageTrigger(wft, "hub", registry.Push{Repository: "library/golang", Tag: "1.12"}))
+	assert.False(t, matchImageTrigger(wft, "hub", registry.Push{Repository: "library/golang", Tag: "1.12-alpine"}))
+	assert.False(t, matchImageTrigger(wft, "hub", registry.Push{Repository: "library/nginx", Tag: "1.12"}))
+	assert.False(t, matchImageTrigger(wft, "other", registry.Push{Repository: "library/golang", Tag: "1.12"}))
+
+	wft.Spec.Disabled = true
+	assert.False(t, matchImageTrigger(wft, "hub", registry.Push{Repository: "library/golang", Tag: "1.12"}))
+}
+
+func TestAuthorized(t *testing.T) {
+	assert.True(t, authorized("s3cret", "Bearer s3cret"))
+	assert.True(t, authorized("s3cret", "bearer  s3cret"))
+	assert.True(t, authorized("s3cret", "s3cret"))
+	assert.False(t, authorized("s3cret", "Bearer other"))
+	assert.False(t, authorized("s3cret", ""))
+	assert.False(t, authorized("", ""))
+	assert.False(t, authorized("", "Bearer "))
+}
@@ -0,0 +1,101 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/caicloud/nirvana/log"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/summarystore"
+	"github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/server/handler"
+	"github.com/caicloud/cyclone/pkg/server/types"
+	"github.com/caicloud/cyclone/pkg/util/cerr"
+	httputil "github.com/caicloud/cyclone/pkg/util/http"
+)
+
+// CreateWorkflowRunSummary archives summary of a workflowrun, it's called by workflow controller
+// before the workflowrun is pruned. Paths of logs collected for the workflowrun are filled in.
+func CreateWorkflowRunSummary(ctx context.Context, project, workflow, tenant string, summary *api.WorkflowRunSummary) (*api.WorkflowRunSummary, error) {
+	namespace := common.TenantNamespace(tenant)
+	summary.Project = project
+	summary.Workflow = workflow
+
+	keys, err := handler.LogStore.List(namespace, summary.Name)
+	if err != nil {
+		log.Warningf("List logs of workflowrun %s/%s error: %v", namespace, summary.Name, err)
+	}
+	for _, key := range keys {
+		stage := summary.Stages[key.Stage]
+		stage.Logs = append(stage.Logs, logPath(project, workflow, key.WorkflowRun, key.Stage, key.Container))
+		if summary.Stages == nil {
+			summary.Stages = make(map[string]api.StageSummary)
+		}
+		summary.Stages[key.Stage] = stage
+	}
+
+	if err := handler.SummaryStore.Put(namespace, summary); err != nil {
+		log.Errorf("Store summary of workflowrun %s/%s error: %v", namespace, summary.Name, err)
+		return nil, cerr.ErrorCreateFailed.Error(summary.Name, err)
+	}
+
+	return summary, nil
+}
+
+// logPath is the API path to get log of the container.
+func logPath(project, workflow, workflowrun, stage, container string) string {
+	query := url.Values{}
+	query.Set(httputil.StageNameQueryParameter, stage)
+	query.Set(httputil.ContainerNameQueryParameter, container)
+	return fmt.Sprintf("%s/projects/%s/workflows/%s/workflowruns/%s/logs?%s",
+		httputil.APIVersion, project, workflow, workflowrun, query.Encode())
+}
+
+// ListWorkflowRunSummaries lists summaries of workflowruns of the workflow, including pruned ones,
+// newest first. Only summaries in the given status are listed if status is not empty.
+func ListWorkflowRunSummaries(ctx context.Context, project, workflow, tenant, status string, pagination *types.Pagination) (*types.ListResponse, error) {
+	return listWorkflowRunSummaries(tenant, summarystore.Filter{Project: project, Workflow: workflow, Status: status}, pagination)
+}
+
+// ListProjectWorkflowRunSummaries lists summaries of workflowruns of all workflows in the project.
+func ListProjectWorkflowRunSummaries(ctx context.Context, project, tenant, status string, pagination *types.Pagination) (*types.ListResponse, error) {
+	return listWorkflowRunSummaries(tenant, summarystore.Filter{Project: project, Status: status}, pagination)
+}
+
+func listWorkflowRunSummaries(tenant string, filter summarystore.Filter, pagination *types.Pagination) (*types.ListResponse, error) {
+	namespace := common.TenantNamespace(tenant)
+	summaries, err := handler.SummaryStore.List(namespace, filter)
+	if err != nil {
+		log.Errorf("List workflowrun summaries in %s error: %v", namespace, err)
+		return nil, cerr.ErrorListFailed.Error("workflowrun summaries", err)
+	}
+
+	size := int64(len(summaries))
+	if pagination.Start >= size {
+		return types.NewListResponse(int(size), []api.WorkflowRunSummary{}), nil
+	}
+
+	end := pagination.Start + pagination.Limit
+	if end > size {
+		end = size
+	}
+
+	return types.NewListResponse(int(size), summaries[pagination.Start:end]), nil
+}
+
+// GetWorkflowRunSummary gets summary of a workflowrun, it's available after the workflowrun is pruned.
+func GetWorkflowRunSummary(ctx context.Context, project, workflow, workflowrun, tenant string) (*api.WorkflowRunSummary, error) {
+	namespace := common.TenantNamespace(tenant)
+	summary, err := handler.SummaryStore.Get(namespace, workflow, workflowrun)
+	if err == summarystore.ErrNotFound || (err == nil && summary.Project != project) {
+		return nil, cerr.ErrorContentNotFound.Error(fmt.Sprintf("summary of workflowrun %s", workflowrun))
+	}
+	if err != nil {
+		log.Errorf("Get summary of workflowrun %s/%s error: %v", namespace, workflowrun, err)
+		return nil, cerr.ErrorGetFailed.Error("workflowrun summary", err)
+	}
+
+	return summary, nil
+}
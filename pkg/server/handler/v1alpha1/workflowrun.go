@@ -2,33 +2,49 @@ package v1alpha1
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/caicloud/nirvana/log"
 	"github.com/gorilla/websocket"
 	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8s_types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/util/retry"
 
 	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/logstore"
+	"github.com/caicloud/cyclone/pkg/server/biz/stats"
+	"github.com/caicloud/cyclone/pkg/server/biz/summarystore"
 	"github.com/caicloud/cyclone/pkg/server/common"
 	"github.com/caicloud/cyclone/pkg/server/handler"
 	"github.com/caicloud/cyclone/pkg/server/types"
 	"github.com/caicloud/cyclone/pkg/util/cerr"
 	contextutil "github.com/caicloud/cyclone/pkg/util/context"
-	fileutil "github.com/caicloud/cyclone/pkg/util/file"
 	httputil "github.com/caicloud/cyclone/pkg/util/http"
 	websocketutil "github.com/caicloud/cyclone/pkg/util/websocket"
+	"github.com/caicloud/cyclone/pkg/workflow/history"
+)
+
+const (
+	// logReopenInterval is the minimal interval to reopen a log to get newly written content.
+	logReopenInterval = time.Second
+
+	// defaultMergedLogLines is the default number of lines to get for merged stage logs.
+	defaultMergedLogLines = 1000
 )
 
 // CreateWorkflowRun ...
-func CreateWorkflowRun(ctx context.Context, project, workflow, tenant string, wfr *v1alpha1.WorkflowRun) (*v1alpha1.WorkflowRun, error) {
+func CreateWorkflowRun(ctx context.Context, project, workflow, tenant, user string, wfr *v1alpha1.WorkflowRun) (*v1alpha1.WorkflowRun, error) {
 	modifiers := []CreationModifier{GenerateNameModifier, InjectProjectLabelModifier, WorkflowRunModifier}
 	for _, modifier := range modifiers {
 		err := modifier(tenant, project, workflow, wfr)
@@ -37,6 +53,10 @@ func CreateWorkflowRun(ctx context.Context, project, workflow, tenant string, wf
 		}
 	}
 
+	if err := injectTriggeredBy(tenant, workflow, user, wfr); err != nil {
+		return nil, err
+	}
+
 	injectWfRef(tenant, workflow, wfr)
 	return handler.K8sClient.CycloneV1alpha1().WorkflowRuns(common.TenantNamespace(tenant)).Create(wfr)
 }
@@ -52,10 +72,81 @@ func injectWfRef(tenant, workflow string, wfr *v1alpha1.WorkflowRun) {
 	}
 }
 
-// ListWorkflowRuns ...
-func ListWorkflowRuns(ctx context.Context, project, workflow, tenant string, pagination *types.Pagination) (*types.ListResponse, error) {
+// injectTriggeredBy records provenance of the workflowrun. Workflowruns created by the API are
+// started by the user manually, or rerun from an existing workflowrun of the workflow. Other types
+// of provenance are recorded by Cyclone itself when triggers fire, they are rejected here so that
+// they can't be forged.
+func injectTriggeredBy(tenant, workflow, user string, wfr *v1alpha1.WorkflowRun) error {
+	triggeredBy := &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeManual, User: user}
+	if requested := wfr.Spec.TriggeredBy; requested != nil {
+		switch requested.Type {
+		case v1alpha1.TriggerTypeManual, "":
+		case v1alpha1.TriggerTypeRerun:
+			if requested.ParentRun == "" {
+				return cerr.ErrorValidationFailed.Error("triggeredBy", "parent run is required for rerun")
+			}
+			if err := checkParentRun(tenant, workflow, requested.ParentRun); err != nil {
+				return err
+			}
+			triggeredBy.Type = v1alpha1.TriggerTypeRerun
+			triggeredBy.ParentRun = requested.ParentRun
+		default:
+			return cerr.ErrorValidationFailed.Error("triggeredBy", fmt.Sprintf("trigger type '%s' is not allowed, only %s and %s are",
+				requested.Type, v1alpha1.TriggerTypeManual, v1alpha1.TriggerTypeRerun))
+		}
+	}
+
+	wfr.Spec.TriggeredBy = triggeredBy
+	wfr.Labels[common.LabelTriggerType] = string(triggeredBy.Type)
+	delete(wfr.Labels, common.LabelWorkflowTriggerName)
+	return nil
+}
+
+// checkParentRun checks that the workflowrun to rerun exists in the workflow, it's looked up in
+// summaries if it has been deleted.
+func checkParentRun(tenant, workflow, parent string) error {
+	namespace := common.TenantNamespace(tenant)
+	wfr, err := handler.K8sClient.CycloneV1alpha1().WorkflowRuns(namespace).Get(parent, metav1.GetOptions{})
+	if err == nil {
+		if wfr.Spec.WorkflowRef == nil || wfr.Spec.WorkflowRef.Name != workflow {
+			return cerr.ErrorValidationFailed.Error("triggeredBy", fmt.Sprintf("parent run %s doesn't belong to workflow %s", parent, workflow))
+		}
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		log.Errorf("Get parent run %s/%s error: %v", namespace, parent, err)
+		return cerr.ErrorGetFailed.Error("parent run", err)
+	}
+
+	_, err = handler.SummaryStore.Get(namespace, workflow, parent)
+	if err == summarystore.ErrNotFound {
+		return cerr.ErrorValidationFailed.Error("triggeredBy", fmt.Sprintf("parent run %s not found in workflow %s", parent, workflow))
+	}
+	if err != nil {
+		log.Errorf("Get summary of parent run %s/%s error: %v", namespace, parent, err)
+		return cerr.ErrorGetFailed.Error("workflowrun summary", err)
+	}
+	return nil
+}
+
+// ListWorkflowRuns lists workflowruns of the workflow, they can be filtered by type of the source
+// that started them, and the workflowtrigger.
+func ListWorkflowRuns(ctx context.Context, project, workflow, tenant, triggerType, trigger string, pagination *types.Pagination) (*types.ListResponse, error) {
+	for name, value := range map[string]string{"triggerType": triggerType, "trigger": trigger} {
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, cerr.ErrorValidationFailed.Error(name, strings.Join(errs, "; "))
+		}
+	}
+
+	selector := common.ProjectSelector(project) + "," + common.WorkflowSelector(workflow)
+	if triggerType != "" {
+		selector += "," + common.LabelTriggerType + "=" + triggerType
+	}
+	if trigger != "" {
+		selector += "," + common.LabelWorkflowTriggerName + "=" + trigger
+	}
 	workflowruns, err := handler.K8sClient.CycloneV1alpha1().WorkflowRuns(common.TenantNamespace(tenant)).List(metav1.ListOptions{
-		LabelSelector: common.ProjectSelector(project) + "," + common.WorkflowSelector(workflow),
+		LabelSelector: selector,
 	})
 	if err != nil {
 		log.Errorf("Get workflowruns from k8s with tenant %s, project %s error: %v", tenant, project, err)
@@ -90,6 +181,8 @@ func UpdateWorkflowRun(ctx context.Context, project, workflow, workflowrun, tena
 		}
 		newWfr := origin.DeepCopy()
 		newWfr.Spec = wfr.Spec
+		// Provenance is recorded when the workflowrun is created, it can't be changed.
+		newWfr.Spec.TriggeredBy = origin.Spec.TriggeredBy
 		newWfr.Annotations = UpdateAnnotations(wfr.Annotations, newWfr.Annotations)
 		injectWfRef(tenant, workflow, wfr)
 		_, err = handler.K8sClient.CycloneV1alpha1().WorkflowRuns(common.TenantNamespace(tenant)).Update(newWfr)
@@ -105,7 +198,16 @@ func UpdateWorkflowRun(ctx context.Context, project, workflow, workflowrun, tena
 
 // DeleteWorkflowRun ...
 func DeleteWorkflowRun(ctx context.Context, project, workflow, workflowrun, tenant string) error {
-	return handler.K8sClient.CycloneV1alpha1().WorkflowRuns(common.TenantNamespace(tenant)).Delete(workflowrun, nil)
+	namespace := common.TenantNamespace(tenant)
+	err := handler.K8sClient.CycloneV1alpha1().WorkflowRuns(namespace).Delete(workflowrun, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := handler.LogStore.DeleteRun(namespace, workflowrun); err != nil {
+		log.Warningf("Delete logs of workflowrun %s/%s error: %v", namespace, workflowrun, err)
+	}
+	return nil
 }
 
 // PauseWorkflowRun updates the workflowrun overall status to Waiting.
@@ -138,7 +240,7 @@ func ReceiveContainerLogStream(ctx context.Context, project, workflow, workflowr
 	//upgrade HTTP rest API --> socket connection
 	ws, err := websocketutil.Upgrader.Upgrade(writer, request, nil)
 	if err != nil {
-		log.Errorf(fmt.Sprintf("Unable to upgrade websocket for err: %v", err))
+		log.Errorf("Unable to upgrade websocket for err: %v", err)
 		return cerr.ErrorUnknownInternal.Error(err)
 	}
 	defer ws.Close()
@@ -153,55 +255,61 @@ func ReceiveContainerLogStream(ctx context.Context, project, workflow, workflowr
 	return nil
 }
 
-// receiveContainerLogStream receives the log stream for
-// one stage of the workflowrun, and stores it into log files.
+// receiveContainerLogStream receives the log stream for one stage of the workflowrun, and
+// stores it into log store. Messages can be log records or raw text. The log is completed
+// only if the stream is closed normally, otherwise it's incomplete and can be pushed again.
 func receiveContainerLogStream(workflowrun, stage, container, namespace string, ws *websocket.Conn) error {
-	logFolder, err := getLogFolder(workflowrun, stage, namespace)
+	key := logKey(workflowrun, stage, container, namespace)
+	writer, err := handler.LogStore.Create(key)
 	if err != nil {
-		log.Errorf("get log folder failed: %v", err)
+		log.Errorf("fail to create log %s as %v", key, err)
 		return err
 	}
 
-	// create log folders.
-	fileutil.CreateDirectory(logFolder)
-
-	logFilePath, err := getLogFilePath(workflowrun, stage, container, namespace)
-	if err != nil {
-		log.Errorf("get log path failed: %v", err)
+	if err := receiveMessages(ws, writer); err != nil {
+		log.Warningf("log %s is incomplete as %v", key, err)
+		if abortErr := writer.Abort(); abortErr != nil {
+			log.Errorf("fail to finish log %s as %v", key, abortErr)
+		}
 		return err
 	}
 
-	if fileutil.Exists(logFilePath) {
-		return fmt.Errorf("log file %s already exists", logFilePath)
-	}
-
-	file, err := os.OpenFile(logFilePath, os.O_RDWR|os.O_CREATE, 0666)
-	if err != nil {
-		log.Errorf("fail to open the log file %s as %v", logFilePath, err)
+	if err := writer.Close(); err != nil {
+		log.Errorf("fail to finish log %s as %v", key, err)
 		return err
 	}
-	defer file.Close()
+	return nil
+}
 
-	var message []byte
+// receiveMessages writes messages received to the log until the stream is closed normally.
+func receiveMessages(ws *websocket.Conn, writer logstore.Writer) error {
 	for {
-		_, message, err = ws.ReadMessage()
+		_, message, err := ws.ReadMessage()
 		if err != nil {
-			if !websocket.IsUnexpectedCloseError(err, websocket.CloseAbnormalClosure) {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
 				return nil
 			}
-
-			log.Infoln(err)
 			return err
 		}
-		_, err = file.Write(message)
-		if err != nil {
+
+		if record, ok := logstore.ParseRecord(message); ok {
+			message = logstore.FormatRecord(record)
+		}
+		if _, err := writer.Write(message); err != nil {
 			return err
 		}
 	}
 }
 
-// GetContainerLogStream gets real-time log of container within stage.
-func GetContainerLogStream(ctx context.Context, project, workflow, workflowrun, tenant, stage, container string) error {
+// GetContainerLogStream gets real-time log of container within stage. The stream starts from
+// the first line selected by the query, limit of the query is ignored.
+func GetContainerLogStream(ctx context.Context, project, workflow, workflowrun, tenant, stage, container string,
+	query *types.LogQuery) error {
+	q, err := lineQuery(query)
+	if err != nil {
+		return err
+	}
+
 	request := contextutil.GetHTTPRequest(ctx)
 	writer := contextutil.GetHTTPResponseWriter(ctx)
 
@@ -214,7 +322,7 @@ func GetContainerLogStream(ctx context.Context, project, workflow, workflowrun,
 	defer ws.Close()
 
 	namespace := common.TenantNamespace(tenant)
-	if err := getContainerLogStream(workflowrun, stage, container, namespace, ws); err != nil {
+	if err := getContainerLogStream(workflowrun, stage, container, namespace, q, ws); err != nil {
 		log.Errorf("Unable to get logstream for %s/%s/%s/%s for err: %s",
 			namespace, workflowrun, stage, container, err)
 		return cerr.ErrorUnknownInternal.Error(err.Error())
@@ -223,28 +331,34 @@ func GetContainerLogStream(ctx context.Context, project, workflow, workflowrun,
 	return nil
 }
 
-// getContainerLogStream watches the log files and sends the content to the log stream.
-func getContainerLogStream(workflowrun, stage, container, namespace string, ws *websocket.Conn) error {
-	logFilePath, err := getLogFilePath(workflowrun, stage, container, namespace)
-	if err != nil {
-		log.Errorf("get log path failed: %v", err)
-		return err
+// getContainerLogStream watches the log and sends the content to the log stream.
+func getContainerLogStream(workflowrun, stage, container, namespace string, query logstore.LineQuery, ws *websocket.Conn) error {
+	key := logKey(workflowrun, stage, container, namespace)
+	if _, err := handler.LogStore.Stat(key); err != nil {
+		return fmt.Errorf("get log %s error: %v", key, err)
 	}
 
-	if !fileutil.Exists(logFilePath) {
-		return fmt.Errorf("log file %s does not exist", logFilePath)
+	offset, err := logOffset(key, query)
+	if err != nil {
+		return fmt.Errorf("locate log %s error: %v", key, err)
 	}
 
 	pingTicker := time.NewTicker(websocketutil.PingPeriod)
 	sendTicker := time.NewTicker(10 * time.Millisecond)
-	file, err := os.Open(logFilePath)
-	if err != nil {
-		log.Errorf("fail to open the log file %s as %s", logFilePath, err.Error())
-		return err
-	}
-	defer file.Close()
+	defer pingTicker.Stop()
+	defer sendTicker.Stop()
+
+	// The log is reopened from the offset of the last sent line when all content has been read,
+	// to get content written since then.
+	var reader io.ReadCloser
+	var buf *bufio.Reader
+	var lastOpen time.Time
+	defer func() {
+		if reader != nil {
+			reader.Close()
+		}
+	}()
 
-	buf := bufio.NewReader(file)
 	var line []byte
 	for {
 		select {
@@ -257,17 +371,34 @@ func getContainerLogStream(workflowrun, stage, container, namespace string, ws *
 				return err
 			}
 		case <-sendTicker.C:
+			if reader == nil {
+				if time.Since(lastOpen) < logReopenInterval {
+					continue
+				}
+				lastOpen = time.Now()
+				reader, err = handler.LogStore.Open(key, offset, 0)
+				if err != nil {
+					ws.WriteMessage(websocket.CloseMessage, []byte("Interval error happens, TERMINATE"))
+					return err
+				}
+				buf = bufio.NewReader(reader)
+			}
+
 			line, err = buf.ReadBytes('\n')
 			if err == io.EOF {
+				// Incomplete line will be read again after reopen.
+				reader.Close()
+				reader = nil
 				continue
 			}
 
 			if err != nil {
 				ws.WriteMessage(websocket.CloseMessage, []byte("Interval error happens, TERMINATE"))
-				break
+				return err
 			}
 
-			err = ws.WriteMessage(websocket.TextMessage, line)
+			offset += int64(len(line))
+			err = ws.WriteMessage(websocket.TextMessage, query.Output(line))
 			if err != nil {
 				if !websocket.IsUnexpectedCloseError(err, websocket.CloseAbnormalClosure) {
 					return nil
@@ -277,47 +408,242 @@ func getContainerLogStream(workflowrun, stage, container, namespace string, ws *
 			ws.SetWriteDeadline(time.Now().Add(websocketutil.WriteWait))
 		}
 	}
+}
+
+// logOffset gets the byte offset of the first line selected by the query.
+func logOffset(key logstore.Key, query logstore.LineQuery) (int64, error) {
+	if query.SelectsAll() {
+		return 0, nil
+	}
 
+	r, err := handler.LogStore.Open(key, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return query.Offset(r)
 }
 
 // GetContainerLogs handles the request to get container logs, only supports finished stage records.
-func GetContainerLogs(ctx context.Context, project, workflow, workflowrun, tenant, stage, container string, download bool) ([]byte, map[string]string, error) {
+// offset and length specify the byte range of logs to get, length 0 means getting to the end. Lines
+// in the range are then selected by the query.
+func GetContainerLogs(ctx context.Context, project, workflow, workflowrun, tenant, stage, container string,
+	download bool, offset, length int64, query *types.LogQuery) (io.ReadCloser, map[string]string, error) {
 	namespace := common.TenantNamespace(tenant)
 
-	logs, err := getContainerLogs(workflowrun, stage, container, namespace)
+	if offset < 0 || length < 0 {
+		return nil, nil, cerr.ErrorParamTypeError.Error("offset/length", "non-negative integer", "negative integer")
+	}
+	q, err := lineQuery(query)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	key := logKey(workflowrun, stage, container, namespace)
+	info, err := handler.LogStore.Stat(key)
+	if err != nil {
+		log.Errorf("get log %s error: %v", key, err)
+		if err == logstore.ErrNotFound {
+			return nil, nil, cerr.ErrorContentNotFound.Error(fmt.Sprintf("log of stage %s container %s", stage, container))
+		}
+		return nil, nil, cerr.ErrorUnknownInternal.Error(err)
+	}
+
+	logs, err := handler.LogStore.Open(key, offset, length)
+	if err != nil {
+		return nil, nil, cerr.ErrorUnknownInternal.Error(err)
+	}
+
 	headers := make(map[string]string)
 	headers[httputil.HeaderContentType] = "text/plain"
+	headers[httputil.HeaderLogSize] = strconv.FormatInt(info.Size, 10)
 	if download {
 		logFileName := fmt.Sprintf("%s-%s-%s-log.txt", workflowrun, stage, container)
 		headers["Content-Disposition"] = fmt.Sprintf("attachment; filename=%s", logFileName)
 	}
 
-	return []byte(logs), headers, nil
+	return logstore.FilterLines(logs, q), headers, nil
+}
+
+// GetStageLogs gets logs of all containers of a stage merged in time order, lines are selected
+// by the query, at most 1000 lines are returned if neither limit nor tail is set.
+func GetStageLogs(ctx context.Context, project, workflow, workflowrun, tenant, stage string,
+	query *types.LogQuery) (*types.ListResponse, error) {
+	if stage == "" {
+		return nil, cerr.ErrorURLParamNotFound.Error(httputil.StageNameQueryParameter)
+	}
+	q, err := lineQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit == 0 && q.Tail == 0 {
+		q.Limit = defaultMergedLogLines
+	}
+
+	namespace := common.TenantNamespace(tenant)
+	lines, err := logstore.Merge(handler.LogStore, namespace, workflowrun, stage, q)
+	if err != nil {
+		log.Errorf("Merge logs of stage %s in workflowrun %s/%s error: %v", stage, namespace, workflowrun, err)
+		return nil, cerr.ErrorUnknownInternal.Error(err)
+	}
+
+	return types.NewListResponse(len(lines), lines), nil
+}
+
+// SearchWorkflowRunLogs searches lines containing the keyword in logs of a workflowrun, only logs of the
+// given stage are searched if stage is not empty. If regex is true, keyword is used as a regular expression.
+func SearchWorkflowRunLogs(ctx context.Context, project, workflow, workflowrun, tenant, stage, keyword string,
+	regex bool, limit int) (*types.ListResponse, error) {
+	if keyword == "" {
+		return nil, cerr.ErrorURLParamNotFound.Error(httputil.KeywordQueryParameter)
+	}
+
+	match := func(line []byte) bool {
+		return bytes.Contains(line, []byte(keyword))
+	}
+	if regex {
+		re, err := regexp.Compile(keyword)
+		if err != nil {
+			return nil, cerr.ErrorParamTypeError.Error(httputil.KeywordQueryParameter, "regular expression", keyword)
+		}
+		match = re.Match
+	}
+
+	namespace := common.TenantNamespace(tenant)
+	lines, err := logstore.Search(handler.LogStore, namespace, workflowrun, stage, match, limit)
+	if err != nil {
+		log.Errorf("Search logs of workflowrun %s/%s error: %v", namespace, workflowrun, err)
+		return nil, cerr.ErrorUnknownInternal.Error(err)
+	}
+
+	return types.NewListResponse(len(lines), lines), nil
+}
+
+// ListWorkflowRunLogsStatus lists status of logs of a workflowrun, only logs of the given stage
+// are listed if stage is not empty. It tells whether logs have been pushed completely.
+func ListWorkflowRunLogsStatus(ctx context.Context, project, workflow, workflowrun, tenant, stage string) (*types.ListResponse, error) {
+	namespace := common.TenantNamespace(tenant)
+	status, err := logstore.ListStatus(handler.LogStore, namespace, workflowrun, stage)
+	if err != nil {
+		log.Errorf("List logs status of workflowrun %s/%s error: %v", namespace, workflowrun, err)
+		return nil, cerr.ErrorUnknownInternal.Error(err)
+	}
+
+	return types.NewListResponse(len(status), status), nil
 }
 
-// getContainerLogs gets the stage container logs.
-func getContainerLogs(workflowrun, stage, container, namespace string) (string, error) {
-	logFilePath, err := getLogFilePath(workflowrun, stage, container, namespace)
+// GetWorkflowRunDurations gets how long the workflowrun and its stages have been queued and running.
+func GetWorkflowRunDurations(ctx context.Context, project, workflow, workflowrun, tenant string) (*api.WorkflowRunDurations, error) {
+	wfr, err := handler.K8sClient.CycloneV1alpha1().WorkflowRuns(common.TenantNamespace(tenant)).Get(workflowrun, metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	now := time.Now()
+	durations := &api.WorkflowRunDurations{
+		Durations: durationsOf(overallStatus(wfr), now),
+		Stages:    make(map[string]api.Durations),
+	}
+	for stage, status := range wfr.Status.Stages {
+		durations.Stages[stage] = durationsOf(&status.Status, now)
+	}
+
+	return durations, nil
+}
+
+// overallStatus gets overall status of the workflowrun. Completion time isn't recorded in
+// workflowruns terminated before it's introduced, time of the last stage transition is used
+// as completion time of them if the overall transition time is also unknown.
+func overallStatus(wfr *v1alpha1.WorkflowRun) *v1alpha1.Status {
+	status := wfr.Status.Overall.DeepCopy()
+	if !history.Terminated(status.Status) || !endTime(status).IsZero() {
+		return status
 	}
+	for _, s := range wfr.Status.Stages {
+		if end := endTime(&s.Status); end.After(status.CompletionTime.Time) {
+			status.CompletionTime = metav1.Time{Time: end}
+		}
+	}
+	return status
+}
+
+// GetWorkflowStats gets statistics of workflowruns of the workflow started in the time window.
+func GetWorkflowStats(ctx context.Context, project, workflow, tenant string, startTime, endTime int64) (*api.WorkflowRunStats, error) {
+	return workflowRunStats(tenant, summarystore.Filter{Project: project, Workflow: workflow}, startTime, endTime)
+}
 
-	// Check the existence of the log file for this stage. If does not exist, return error when stage is success,
-	// otherwise directly return the got logs as stage is failed or aborted.
-	if !fileutil.Exists(logFilePath) {
-		log.Errorf("log file %s does not exist", logFilePath)
-		return "", fmt.Errorf("log file for stage %s does not exist", stage)
+// GetProjectStats gets statistics of workflowruns of all workflows in the project started in the time window.
+func GetProjectStats(ctx context.Context, project, tenant string, startTime, endTime int64) (*api.WorkflowRunStats, error) {
+	return workflowRunStats(tenant, summarystore.Filter{Project: project}, startTime, endTime)
+}
+
+// workflowRunStats computes statistics from both summaries archived in the summary store and
+// workflowruns not deleted yet, so that it's not limited by workflowruns pruned. Start and end
+// time are in unix seconds, 0 means not limited.
+func workflowRunStats(tenant string, filter summarystore.Filter, startTime, endTime int64) (*api.WorkflowRunStats, error) {
+	namespace := common.TenantNamespace(tenant)
+	archived, err := handler.SummaryStore.List(namespace, filter)
+	if err != nil {
+		log.Errorf("List workflowrun summaries of %+v error: %v", filter, err)
+		return nil, err
 	}
 
-	// TODO (robin) Read the whole file, need to consider the memory consumption when the log file is too huge.
-	log, err := ioutil.ReadFile(logFilePath)
+	selector := common.ProjectSelector(filter.Project)
+	if filter.Workflow != "" {
+		selector += "," + common.WorkflowSelector(filter.Workflow)
+	}
+	wfrs, err := handler.K8sClient.CycloneV1alpha1().WorkflowRuns(namespace).List(metav1.ListOptions{
+		LabelSelector: selector,
+	})
 	if err != nil {
-		return "", err
+		log.Errorf("List workflowruns with selector %s error: %v", selector, err)
+		return nil, err
+	}
+
+	var start, end time.Time
+	if startTime > 0 {
+		start = time.Unix(startTime, 0)
+	}
+	if endTime > 0 {
+		end = time.Unix(endTime, 0)
+	}
+	return stats.Compute(history.Merge(archived, wfrs.Items), start, end), nil
+}
+
+// durationsOf calculates durations from timing of the status. Stages that never ran are
+// regarded as queued until they terminated. Only termination is reported if the status is
+// terminated but its end time is unknown.
+func durationsOf(status *v1alpha1.Status, now time.Time) api.Durations {
+	durations := api.Durations{
+		Terminated: history.Terminated(status.Status),
+	}
+	if status.StartTime.IsZero() {
+		return durations
 	}
 
-	return string(log), nil
+	end := now
+	if durations.Terminated {
+		end = endTime(status)
+		if end.IsZero() || end.Before(status.StartTime.Time) {
+			return durations
+		}
+	}
+	running := end
+	if !status.RunningTime.IsZero() && status.RunningTime.Time.Before(end) {
+		running = status.RunningTime.Time
+	}
+
+	durations.Queued = int64(running.Sub(status.StartTime.Time).Seconds())
+	durations.Running = int64(end.Sub(running).Seconds())
+	durations.Total = durations.Queued + durations.Running
+	return durations
+}
+
+// endTime gets the time when the status terminated, it's the completion time, or time of the
+// last transition if completion time isn't recorded.
+func endTime(status *v1alpha1.Status) time.Time {
+	if !status.CompletionTime.IsZero() {
+		return status.CompletionTime.Time
+	}
+	return status.LastTransitionTime.Time
 }
@@ -0,0 +1,206 @@
+package v1alpha1
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/logstore"
+	"github.com/caicloud/cyclone/pkg/server/biz/summarystore"
+	"github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/server/handler"
+	"github.com/caicloud/cyclone/pkg/server/types"
+)
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+// fakeLogStore serves a fixed log content, and tracks readers opened.
+type fakeLogStore struct {
+	logstore.Store
+	content string
+	opened  []*closeTracker
+}
+
+func (s *fakeLogStore) Stat(key logstore.Key) (*logstore.Info, error) {
+	return &logstore.Info{Size: int64(len(s.content))}, nil
+}
+
+func (s *fakeLogStore) Open(key logstore.Key, offset, length int64) (io.ReadCloser, error) {
+	r := &closeTracker{Reader: strings.NewReader(s.content[offset:])}
+	s.opened = append(s.opened, r)
+	return r, nil
+}
+
+func TestGetContainerLogsClosesReader(t *testing.T) {
+	store := &fakeLogStore{content: "line1\nline2\nline3\n"}
+	origin := handler.LogStore
+	handler.LogStore = store
+	defer func() { handler.LogStore = origin }()
+
+	for _, q := range []*types.LogQuery{{Timestamps: true}, {Start: 1, Limit: 1}, {Tail: 1}} {
+		// Underlying reader is closed once logs are read to the end, without closing.
+		r, _, err := GetContainerLogs(context.TODO(), "p", "wf", "wfr", "t", "build", "main", false, 0, 0, q)
+		assert.Nil(t, err)
+		_, err = ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.True(t, store.opened[len(store.opened)-1].closed)
+
+		// Underlying reader is closed if the client goes away before reading to the end.
+		r, _, err = GetContainerLogs(context.TODO(), "p", "wf", "wfr", "t", "build", "main", false, 0, 0, q)
+		assert.Nil(t, err)
+		assert.Nil(t, r.Close())
+		assert.True(t, store.opened[len(store.opened)-1].closed)
+	}
+}
+
+// fakeSummaryStore serves summaries of the given workflowruns.
+type fakeSummaryStore struct {
+	summarystore.Store
+	archived map[string]bool
+}
+
+func (s *fakeSummaryStore) Get(namespace, workflow, workflowrun string) (*api.WorkflowRunSummary, error) {
+	if !s.archived[workflow+"/"+workflowrun] {
+		return nil, summarystore.ErrNotFound
+	}
+	return &api.WorkflowRunSummary{Name: workflowrun, Workflow: workflow}, nil
+}
+
+func TestInjectTriggeredBy(t *testing.T) {
+	namespace := common.TenantNamespace("t1")
+	originClient, originStore := handler.K8sClient, handler.SummaryStore
+	handler.K8sClient = fake.NewSimpleClientset(
+		&v1alpha1.WorkflowRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "wf-1", Namespace: namespace},
+			Spec:       v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: "wf"}},
+		},
+		&v1alpha1.WorkflowRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-1", Namespace: namespace},
+			Spec:       v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: "other"}},
+		},
+	)
+	handler.SummaryStore = &fakeSummaryStore{archived: map[string]bool{"wf/wf-0": true}}
+	defer func() { handler.K8sClient, handler.SummaryStore = originClient, originStore }()
+
+	cases := map[string]struct {
+		requested *v1alpha1.TriggeredBy
+		expected  *v1alpha1.TriggeredBy
+	}{
+		"default": {
+			expected: &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeManual, User: "u1"},
+		},
+		"forged user": {
+			requested: &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeManual, User: "admin"},
+			expected:  &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeManual, User: "u1"},
+		},
+		"rerun": {
+			requested: &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeRerun, ParentRun: "wf-1", Trigger: "forged"},
+			expected:  &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeRerun, User: "u1", ParentRun: "wf-1"},
+		},
+		"rerun archived": {
+			requested: &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeRerun, ParentRun: "wf-0"},
+			expected:  &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeRerun, User: "u1", ParentRun: "wf-0"},
+		},
+		"rerun without parent": {
+			requested: &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeRerun},
+		},
+		"rerun missing parent": {
+			requested: &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeRerun, ParentRun: "wf-2"},
+		},
+		"rerun parent of other workflow": {
+			requested: &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeRerun, ParentRun: "other-1"},
+		},
+		"cron": {
+			requested: &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeCron, Trigger: "nightly"},
+		},
+		"webhook": {
+			requested: &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeWebhook, Trigger: "github"},
+		},
+	}
+
+	for name, c := range cases {
+		wfr := &v1alpha1.WorkflowRun{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{common.LabelWorkflowTriggerName: "forged"}},
+			Spec:       v1alpha1.WorkflowRunSpec{TriggeredBy: c.requested},
+		}
+		err := injectTriggeredBy("t1", "wf", "u1", wfr)
+		if c.expected == nil {
+			assert.Error(t, err, name)
+			continue
+		}
+		assert.Nil(t, err, name)
+		assert.Equal(t, c.expected, wfr.Spec.TriggeredBy, name)
+		assert.Equal(t, map[string]string{common.LabelTriggerType: string(c.expected.Type)}, wfr.Labels, name)
+	}
+}
+
+func TestListWorkflowRunsInvalidSelector(t *testing.T) {
+	_, err := ListWorkflowRuns(context.TODO(), "p", "wf", "t1", "Manual,cyclone.io/project-name=other", "", &types.Pagination{Limit: 10})
+	assert.Error(t, err)
+	_, err = ListWorkflowRuns(context.TODO(), "p", "wf", "t1", "", "a b", &types.Pagination{Limit: 10})
+	assert.Error(t, err)
+}
+
+func TestDurationsOf(t *testing.T) {
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) metav1.Time {
+		return metav1.Time{Time: start.Add(time.Duration(seconds) * time.Second)}
+	}
+	now := start.Add(time.Hour)
+
+	cases := map[string]struct {
+		status   v1alpha1.Status
+		expected api.Durations
+	}{
+		"running": {
+			status:   v1alpha1.Status{Status: v1alpha1.StatusRunning, StartTime: at(0), RunningTime: at(10)},
+			expected: api.Durations{Queued: 10, Running: 3590, Total: 3600},
+		},
+		"completed": {
+			status:   v1alpha1.Status{Status: v1alpha1.StatusCompleted, StartTime: at(0), RunningTime: at(10), CompletionTime: at(30)},
+			expected: api.Durations{Queued: 10, Running: 20, Total: 30, Terminated: true},
+		},
+		"cancelled without completion time": {
+			status:   v1alpha1.Status{Status: v1alpha1.StatusCancelled, StartTime: at(0), RunningTime: at(10), LastTransitionTime: at(20)},
+			expected: api.Durations{Queued: 10, Running: 10, Total: 20, Terminated: true},
+		},
+		"terminated with unknown end": {
+			status:   v1alpha1.Status{Status: v1alpha1.StatusError, StartTime: at(0), RunningTime: at(10)},
+			expected: api.Durations{Terminated: true},
+		},
+	}
+	for name, c := range cases {
+		assert.Equal(t, c.expected, durationsOf(&c.status, now), name)
+	}
+
+	// Workflowruns terminated without completion and transition time end at their last stage.
+	wfr := &v1alpha1.WorkflowRun{
+		Status: v1alpha1.WorkflowRunStatus{
+			Overall: v1alpha1.Status{Status: v1alpha1.StatusCompleted, StartTime: at(0), RunningTime: at(5)},
+			Stages: map[string]*v1alpha1.StageStatus{
+				"build": {Status: v1alpha1.Status{Status: v1alpha1.StatusCompleted, LastTransitionTime: at(40)}},
+				"test":  {Status: v1alpha1.Status{Status: v1alpha1.StatusCompleted, CompletionTime: at(50)}},
+			},
+		},
+	}
+	assert.Equal(t, api.Durations{Queued: 5, Running: 45, Total: 50, Terminated: true}, durationsOf(overallStatus(wfr), now))
+	assert.True(t, wfr.Status.Overall.CompletionTime.IsZero())
+}
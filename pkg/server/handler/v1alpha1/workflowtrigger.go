@@ -2,19 +2,27 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
+	"regexp"
 
 	"github.com/caicloud/nirvana/log"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 
 	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/scm"
 	"github.com/caicloud/cyclone/pkg/server/common"
 	"github.com/caicloud/cyclone/pkg/server/handler"
 	"github.com/caicloud/cyclone/pkg/server/types"
+	"github.com/caicloud/cyclone/pkg/util/cerr"
 )
 
 // CreateWorkflowTrigger ...
 func CreateWorkflowTrigger(ctx context.Context, project, tenant string, wft *v1alpha1.WorkflowTrigger) (*v1alpha1.WorkflowTrigger, error) {
+	if err := validateWorkflowTrigger(wft); err != nil {
+		return nil, err
+	}
+
 	modifiers := []CreationModifier{GenerateNameModifier, InjectProjectLabelModifier}
 	for _, modifier := range modifiers {
 		err := modifier(tenant, project, "", wft)
@@ -57,6 +65,10 @@ func GetWorkflowTrigger(ctx context.Context, project, workflowtrigger, tenant st
 
 // UpdateWorkflowTrigger ...
 func UpdateWorkflowTrigger(ctx context.Context, project, workflowtrigger, tenant string, wft *v1alpha1.WorkflowTrigger) (*v1alpha1.WorkflowTrigger, error) {
+	if err := validateWorkflowTrigger(wft); err != nil {
+		return nil, err
+	}
+
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		origin, err := handler.K8sClient.CycloneV1alpha1().WorkflowTriggers(common.TenantNamespace(tenant)).Get(workflowtrigger, metav1.GetOptions{})
 		if err != nil {
@@ -80,3 +92,48 @@ func UpdateWorkflowTrigger(ctx context.Context, project, workflowtrigger, tenant
 func DeleteWorkflowTrigger(ctx context.Context, project, workflowtrigger, tenant string) error {
 	return handler.K8sClient.CycloneV1alpha1().WorkflowTriggers(common.TenantNamespace(tenant)).Delete(workflowtrigger, nil)
 }
+
+// validateWorkflowTrigger validates config of the workflowtrigger for its type.
+func validateWorkflowTrigger(wft *v1alpha1.WorkflowTrigger) error {
+	switch wft.Spec.Type {
+	case v1alpha1.TriggerTypeWorkflowRun:
+		if wft.Spec.WorkflowRun.Workflow == "" {
+			return cerr.ErrorValidationFailed.Error("workflowRun.workflow", "upstream workflow is required")
+		}
+		ref := wft.Spec.WorkflowRunSpec.WorkflowRef
+		if ref == nil || ref.Name == "" {
+			return cerr.ErrorValidationFailed.Error("workflowRunSpec.workflowRef", "downstream workflow is required")
+		}
+		if ref.Name == wft.Spec.WorkflowRun.Workflow {
+			return cerr.ErrorValidationFailed.Error("workflowRun.workflow", "workflow can not trigger itself")
+		}
+		for _, o := range wft.Spec.WorkflowRun.Outputs {
+			if o.Stage == "" || o.Output == "" || o.TargetStage == "" {
+				return cerr.ErrorValidationFailed.Error("workflowRun.outputs", "stage, output and targetStage are required")
+			}
+		}
+	case v1alpha1.TriggerTypeImage:
+		image := wft.Spec.Image
+		if image.Integration == "" || image.Repository == "" || image.Resource == "" {
+			return cerr.ErrorValidationFailed.Error("image", "integration, repository and resource are required")
+		}
+		if _, err := regexp.Compile(image.Tag); err != nil {
+			return cerr.ErrorValidationFailed.Error("image.tag", fmt.Sprintf("invalid regular expression: %v", err))
+		}
+		if image.PollIntervalSeconds < 0 {
+			return cerr.ErrorValidationFailed.Error("image.pollIntervalSeconds", "it can not be negative")
+		}
+	case v1alpha1.TriggerTypeSCMPoll:
+		poll := wft.Spec.SCMPoll
+		if poll.Integration == "" || poll.Repository == "" {
+			return cerr.ErrorValidationFailed.Error("scmPoll", "integration and repository are required")
+		}
+		if err := scm.ValidateRepository(poll.Repository); err != nil {
+			return cerr.ErrorValidationFailed.Error("scmPoll.repository", err.Error())
+		}
+		if poll.PollIntervalSeconds <= 0 {
+			return cerr.ErrorValidationFailed.Error("scmPoll.pollIntervalSeconds", "it should be positive")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+)
+
+func TestValidateWorkflowRunTrigger(t *testing.T) {
+	cases := map[string]struct {
+		upstream   string
+		downstream *corev1.ObjectReference
+		valid      bool
+	}{
+		"valid": {
+			upstream:   "build",
+			downstream: &corev1.ObjectReference{Name: "deploy"},
+			valid:      true,
+		},
+		"no upstream": {
+			downstream: &corev1.ObjectReference{Name: "deploy"},
+		},
+		"no downstream": {
+			upstream: "build",
+		},
+		"self reference": {
+			upstream:   "build",
+			downstream: &corev1.ObjectReference{Name: "build"},
+		},
+	}
+
+	for name, c := range cases {
+		wft := &v1alpha1.WorkflowTrigger{
+			Spec: v1alpha1.WorkflowTriggerSpec{
+				Type:            v1alpha1.TriggerTypeWorkflowRun,
+				WorkflowRun:     v1alpha1.WorkflowRunTrigger{Workflow: c.upstream},
+				WorkflowRunSpec: v1alpha1.WorkflowRunSpec{WorkflowRef: c.downstream},
+			},
+		}
+		err := validateWorkflowTrigger(wft)
+		assert.Equal(t, c.valid, err == nil, name)
+	}
+}
+
+func TestValidateSCMPollTrigger(t *testing.T) {
+	cases := map[string]bool{
+		"https://github.com/caicloud/cyclone.git": true,
+		"svn://svn.example.com/repo":              true,
+		"--upload-pack=touch /tmp/x":              false,
+		"/tmp/repo.git":                           false,
+		"":                                        false,
+	}
+
+	for repository, valid := range cases {
+		wft := &v1alpha1.WorkflowTrigger{
+			Spec: v1alpha1.WorkflowTriggerSpec{
+				Type: v1alpha1.TriggerTypeSCMPoll,
+				SCMPoll: v1alpha1.SCMPollTrigger{
+					Integration:         "github",
+					Repository:          repository,
+					PollIntervalSeconds: 60,
+				},
+			},
+		}
+		err := validateWorkflowTrigger(wft)
+		assert.Equal(t, valid, err == nil, repository)
+	}
+}
@@ -8,6 +8,20 @@ type Pagination struct {
 	Limit int64 `source:"query,limit,default=99999"`
 }
 
+// LogQuery describes which lines of logs to get.
+type LogQuery struct {
+	// Start is the number of lines to skip.
+	Start int `source:"query,start,default=0"`
+	// Limit is the maximum number of lines to get, 0 means no limit.
+	Limit int `source:"query,limit,default=0"`
+	// Tail gets only the last lines if it's positive.
+	Tail int `source:"query,tail,default=0"`
+	// Since gets only lines logged since the time, it's in RFC3339 format.
+	Since string `source:"query,since"`
+	// Timestamps indicates whether to get lines with time and stream.
+	Timestamps bool `source:"query,timestamps,default=false"`
+}
+
 // ListMeta describes the structure of list metadata
 type ListMeta struct {
 	Total       int `json:"total"`
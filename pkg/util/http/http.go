@@ -64,9 +64,18 @@ const (
 	// TenantHeaderName is name of tenant header name in http reqeust
 	TenantHeaderName = "X-Tenant"
 
+	// UserHeaderName is name of the header of the user who sends the request
+	UserHeaderName = "X-User"
+
+	// AuthorizationHeaderName is name of the header carrying credentials of the request
+	AuthorizationHeaderName = "Authorization"
+
 	// HeaderContentType represents the the key of Content-Type.
 	HeaderContentType = "Content-Type"
 
+	// HeaderLogSize represents the key of the header to return total size of logs.
+	HeaderLogSize = "X-Log-Size"
+
 	// DefaultNamespace represents the default namespace 'default'.
 	DefaultNamespace = "default"
 
@@ -79,6 +88,36 @@ const (
 	// IncludePublicQueryParameter indicates whether include system level resources, for example, when list
 	// stage templates in a tenant, whether to include system level templates. Default is true.
 	IncludePublicQueryParameter = "includePublic"
+
+	// OffsetQueryParameter represents the query param of the byte offset to read logs from.
+	OffsetQueryParameter = "offset"
+
+	// LengthQueryParameter represents the query param of the number of bytes to read logs.
+	LengthQueryParameter = "length"
+
+	// LogQueryAutoParameter represents the auto param to select lines of logs.
+	LogQueryAutoParameter = "logQuery"
+
+	// KeywordQueryParameter represents the query param of the keyword to search.
+	KeywordQueryParameter = "q"
+
+	// RegexQueryParameter represents the query param indicating whether the keyword is a regular expression.
+	RegexQueryParameter = "regex"
+
+	// LimitQueryParameter represents the query param of the maximum number of items to return.
+	LimitQueryParameter = "limit"
+
+	// StartTimeQueryParameter represents the query param of the start of a time window, in unix seconds.
+	StartTimeQueryParameter = "startTime"
+
+	// EndTimeQueryParameter represents the query param of the end of a time window, in unix seconds.
+	EndTimeQueryParameter = "endTime"
+
+	// TriggerTypeQueryParameter represents the query param of type of the source that started workflowruns.
+	TriggerTypeQueryParameter = "triggerType"
+
+	// TriggerQueryParameter represents the query param of the workflowtrigger that started workflowruns.
+	TriggerQueryParameter = "trigger"
 )
 
 // GetHTTPRequest gets request from context.
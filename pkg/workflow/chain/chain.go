@@ -0,0 +1,287 @@
+package chain
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+// maxChainDepth is the maximum number of WorkflowRuns in a chain started by WorkflowRun triggers,
+// so that workflows triggering each other in a cycle won't run forever.
+const maxChainDepth = 10
+
+// Chainer starts WorkflowRuns of downstream workflows by WorkflowRun triggers, when WorkflowRuns
+// of their upstream workflows reach the configured statuses.
+type Chainer struct {
+	client   clientset.Interface
+	recorder record.EventRecorder
+}
+
+// NewChainer creates a chainer.
+func NewChainer(client clientset.Interface) *Chainer {
+	return &Chainer{
+		client:   client,
+		recorder: common.GetEventRecorder(client, common.EventSourceWfrController),
+	}
+}
+
+// Chain fires WorkflowRun triggers matching the WorkflowRun if its overall status transitioned
+// since last chained. Names of downstream WorkflowRuns are derived from the upstream WorkflowRun
+// and its status, and the status is marked as chained after they are created, so downstream
+// WorkflowRuns are started once for each transition even if the controller restarts. Error is
+// returned if the status isn't marked, chaining should be retried in this case.
+func (c *Chainer) Chain(wfr *v1alpha1.WorkflowRun) error {
+	status := wfr.Status.Overall.Status
+	if status == "" || status == v1alpha1.StatusPending || status == wfr.Status.Chained {
+		return nil
+	}
+	logger := log.WithField("wfr", wfr.Name)
+
+	wfts, err := c.client.CycloneV1alpha1().WorkflowTriggers(wfr.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list WorkflowTriggers error: %v", err)
+	}
+	depth := -1
+	for i := range wfts.Items {
+		wft := &wfts.Items[i]
+		if !match(wft, wfr) {
+			continue
+		}
+		if depth < 0 {
+			depth = c.depth(wfr)
+		}
+		if depth >= maxChainDepth {
+			logger.WithField("wft", wft.Name).Warningf("Chain stopped since it's %d WorkflowRuns long", depth)
+			c.recorder.Eventf(wfr, corev1.EventTypeWarning, "ChainStopped", "Trigger '%s' not fired since the chain reached the maximum length %d", wft.Name, maxChainDepth)
+			continue
+		}
+		if err := c.fire(wft, wfr); err != nil {
+			c.recorder.Eventf(wfr, corev1.EventTypeWarning, "ChainFailed", "Start WorkflowRun by trigger '%s' error: %v", wft.Name, err)
+			return fmt.Errorf("start downstream WorkflowRun by trigger %s error: %v", wft.Name, err)
+		}
+	}
+
+	if err := c.mark(wfr, status); err != nil {
+		return fmt.Errorf("mark WorkflowRun chained error: %v", err)
+	}
+	return nil
+}
+
+// match checks whether the WorkflowRun trigger should be fired by the upstream WorkflowRun.
+// WorkflowRuns that reached the status before the trigger was created are not matched.
+func match(wft *v1alpha1.WorkflowTrigger, wfr *v1alpha1.WorkflowRun) bool {
+	spec := wft.Spec.WorkflowRun
+	if wft.Spec.Type != v1alpha1.TriggerTypeWorkflowRun || wft.Spec.Disabled {
+		return false
+	}
+	if wfr.Spec.WorkflowRef == nil || spec.Workflow != wfr.Spec.WorkflowRef.Name {
+		return false
+	}
+	// Workflow triggering itself.
+	if ref := wft.Spec.WorkflowRunSpec.WorkflowRef; ref == nil || ref.Name == spec.Workflow {
+		return false
+	}
+	if wfr.Status.Overall.LastTransitionTime.Before(&wft.CreationTimestamp) {
+		return false
+	}
+
+	statuses := spec.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{v1alpha1.StatusCompleted}
+	}
+	matched := false
+	for _, s := range statuses {
+		if s == wfr.Status.Overall.Status {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+
+	for _, filter := range spec.Parameters {
+		if !matchParameter(filter, wfr.Spec.Stages) {
+			return false
+		}
+	}
+	return true
+}
+
+// depth gets the number of WorkflowRuns in the chain ending with the WorkflowRun, by walking
+// upstream WorkflowRuns that started it. Walking stops at deleted WorkflowRuns or the maximum
+// depth.
+func (c *Chainer) depth(wfr *v1alpha1.WorkflowRun) int {
+	depth := 1
+	for depth < maxChainDepth {
+		triggeredBy := wfr.Spec.TriggeredBy
+		if triggeredBy == nil || triggeredBy.Type != v1alpha1.TriggerTypeWorkflowRun || triggeredBy.UpstreamRun == "" {
+			break
+		}
+		upstream, err := c.client.CycloneV1alpha1().WorkflowRuns(wfr.Namespace).Get(triggeredBy.UpstreamRun, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				log.WithField("wfr", wfr.Name).Warning("Get upstream WorkflowRun error: ", err)
+			}
+			break
+		}
+		depth++
+		wfr = upstream
+	}
+	return depth
+}
+
+func matchParameter(filter v1alpha1.ParameterFilter, stages []v1alpha1.ParameterConfig) bool {
+	for _, stage := range stages {
+		if filter.Stage != "" && filter.Stage != stage.Name {
+			continue
+		}
+		for _, p := range stage.Parameters {
+			if p.Name == filter.Name && p.Value == filter.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fire creates the downstream WorkflowRun, and records it in status of the trigger. It's
+// skipped if the WorkflowRun has already been created.
+func (c *Chainer) fire(wft *v1alpha1.WorkflowTrigger, upstream *v1alpha1.WorkflowRun) error {
+	wfr := newWorkflowRun(wft, upstream)
+	created, err := c.client.CycloneV1alpha1().WorkflowRuns(wft.Namespace).Create(wfr)
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	log.WithField("wfr", created.Name).WithField("upstream", upstream.Name).Info("Downstream WorkflowRun started")
+	c.recorder.Eventf(upstream, corev1.EventTypeNormal, "Chained", "WorkflowRun '%s' started by trigger '%s'", created.Name, wft.Name)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := c.client.CycloneV1alpha1().WorkflowTriggers(wft.Namespace).Get(wft.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest.Status.Count++
+		latest.Status.LastFireTime = &metav1.Time{Time: time.Now()}
+		latest.Status.LastWorkflowRun = created.Name
+		latest.Status.LastError = ""
+		_, err = c.client.CycloneV1alpha1().WorkflowTriggers(wft.Namespace).Update(latest)
+		return err
+	})
+}
+
+// newWorkflowRun creates the downstream WorkflowRun started by the trigger, outputs of upstream
+// stages are passed as parameters.
+func newWorkflowRun(wft *v1alpha1.WorkflowTrigger, upstream *v1alpha1.WorkflowRun) *v1alpha1.WorkflowRun {
+	wfr := &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      runName(wft.Name, upstream),
+			Namespace: wft.Namespace,
+			Labels:    make(map[string]string),
+		},
+		Spec: *wft.Spec.WorkflowRunSpec.DeepCopy(),
+	}
+	if project, ok := wft.Labels[common.ProjectNameLabelName]; ok {
+		wfr.Labels[common.ProjectNameLabelName] = project
+	} else if project, ok := upstream.Labels[common.ProjectNameLabelName]; ok {
+		wfr.Labels[common.ProjectNameLabelName] = project
+	}
+	if wfr.Spec.WorkflowRef != nil {
+		wfr.Labels[common.WorkflowNameLabelName] = wfr.Spec.WorkflowRef.Name
+	}
+	wfr.Labels[common.WorkflowTriggerNameLabelName] = wft.Name
+	wfr.Labels[common.TriggerTypeLabelName] = string(v1alpha1.TriggerTypeWorkflowRun)
+	wfr.Spec.TriggeredBy = &v1alpha1.TriggeredBy{
+		Type:        v1alpha1.TriggerTypeWorkflowRun,
+		Trigger:     wft.Name,
+		UpstreamRun: upstream.Name,
+	}
+
+	for _, o := range wft.Spec.WorkflowRun.Outputs {
+		value, ok := output(upstream, o.Stage, o.Output)
+		if !ok {
+			log.WithField("wft", wft.Name).WithField("stage", o.Stage).Warningf("Output '%s' not found in upstream WorkflowRun", o.Output)
+			continue
+		}
+		name := o.Parameter
+		if name == "" {
+			name = o.Output
+		}
+		setParameter(&wfr.Spec, o.TargetStage, name, value)
+	}
+
+	return wfr
+}
+
+// runName gets name of the downstream WorkflowRun, it's determined by the trigger, the upstream
+// WorkflowRun and its status.
+func runName(trigger string, upstream *v1alpha1.WorkflowRun) string {
+	h := fnv.New32a()
+	h.Write([]byte(upstream.Name + "/" + upstream.Status.Overall.Status))
+	return fmt.Sprintf("%s-%08x", trigger, h.Sum32())
+}
+
+func output(wfr *v1alpha1.WorkflowRun, stage, key string) (string, bool) {
+	status, ok := wfr.Status.Stages[stage]
+	if !ok || status == nil {
+		return "", false
+	}
+	for _, kv := range status.Outputs {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return "", false
+}
+
+// setParameter sets the parameter of the stage, it overrides the existing one.
+func setParameter(spec *v1alpha1.WorkflowRunSpec, stage, name, value string) {
+	for i := range spec.Stages {
+		if spec.Stages[i].Name != stage {
+			continue
+		}
+		for j := range spec.Stages[i].Parameters {
+			if spec.Stages[i].Parameters[j].Name == name {
+				spec.Stages[i].Parameters[j].Value = value
+				return
+			}
+		}
+		spec.Stages[i].Parameters = append(spec.Stages[i].Parameters, v1alpha1.ParameterItem{Name: name, Value: value})
+		return
+	}
+	spec.Stages = append(spec.Stages, v1alpha1.ParameterConfig{
+		Name:       stage,
+		Parameters: []v1alpha1.ParameterItem{{Name: name, Value: value}},
+	})
+}
+
+// mark marks the status as chained in the WorkflowRun.
+func (c *Chainer) mark(wfr *v1alpha1.WorkflowRun, status string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := c.client.CycloneV1alpha1().WorkflowRuns(wfr.Namespace).Get(wfr.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if latest.Status.Overall.Status != status || latest.Status.Chained == status {
+			return nil
+		}
+
+		latest.Status.Chained = status
+		_, err = c.client.CycloneV1alpha1().WorkflowRuns(wfr.Namespace).Update(latest)
+		return err
+	})
+}
@@ -0,0 +1,178 @@
+package chain
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+func upstreamRun(name, status, branch string, transition time.Time) *v1alpha1.WorkflowRun {
+	return &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{common.ProjectNameLabelName: "p1"},
+		},
+		Spec: v1alpha1.WorkflowRunSpec{
+			WorkflowRef: &corev1.ObjectReference{Name: "build-and-test"},
+			Stages: []v1alpha1.ParameterConfig{
+				{Name: "checkout", Parameters: []v1alpha1.ParameterItem{{Name: "branch", Value: branch}}},
+			},
+		},
+		Status: v1alpha1.WorkflowRunStatus{
+			Overall: v1alpha1.Status{Status: status, LastTransitionTime: metav1.Time{Time: transition}},
+			Stages: map[string]*v1alpha1.StageStatus{
+				"build": {Outputs: []v1alpha1.KeyValue{{Key: "image", Value: "app:v1"}}},
+			},
+		},
+	}
+}
+
+func deployTrigger(created time.Time) *v1alpha1.WorkflowTrigger {
+	return &v1alpha1.WorkflowTrigger{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "deploy-staging",
+			Namespace:         "default",
+			CreationTimestamp: metav1.Time{Time: created},
+		},
+		Spec: v1alpha1.WorkflowTriggerSpec{
+			Type: v1alpha1.TriggerTypeWorkflowRun,
+			WorkflowRun: v1alpha1.WorkflowRunTrigger{
+				Workflow:   "build-and-test",
+				Parameters: []v1alpha1.ParameterFilter{{Name: "branch", Value: "main"}},
+				Outputs:    []v1alpha1.OutputParameter{{Stage: "build", Output: "image", TargetStage: "deploy"}},
+			},
+			WorkflowRunSpec: v1alpha1.WorkflowRunSpec{
+				WorkflowRef: &corev1.ObjectReference{Name: "deploy"},
+				Stages: []v1alpha1.ParameterConfig{
+					{Name: "deploy", Parameters: []v1alpha1.ParameterItem{{Name: "image", Value: "app:latest"}, {Name: "env", Value: "staging"}}},
+				},
+			},
+		},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Now()
+	wft := deployTrigger(now.Add(-time.Hour))
+
+	assert.True(t, match(wft, upstreamRun("r1", v1alpha1.StatusCompleted, "main", now)))
+	assert.False(t, match(wft, upstreamRun("r1", v1alpha1.StatusError, "main", now)))
+	assert.False(t, match(wft, upstreamRun("r1", v1alpha1.StatusCompleted, "dev", now)))
+	assert.False(t, match(wft, upstreamRun("r1", v1alpha1.StatusCompleted, "main", now.Add(-2*time.Hour))))
+
+	wft.Spec.WorkflowRun.Statuses = []string{v1alpha1.StatusError}
+	assert.True(t, match(wft, upstreamRun("r1", v1alpha1.StatusError, "main", now)))
+
+	wft.Spec.WorkflowRun.Parameters[0].Stage = "build"
+	assert.False(t, match(wft, upstreamRun("r1", v1alpha1.StatusError, "main", now)))
+
+	wft.Spec.WorkflowRun.Parameters = nil
+	wft.Spec.Disabled = true
+	assert.False(t, match(wft, upstreamRun("r1", v1alpha1.StatusError, "main", now)))
+
+	// Workflow triggering itself.
+	wft = deployTrigger(now.Add(-time.Hour))
+	wft.Spec.WorkflowRunSpec.WorkflowRef.Name = "build-and-test"
+	assert.False(t, match(wft, upstreamRun("r1", v1alpha1.StatusCompleted, "main", now)))
+}
+
+func TestChain(t *testing.T) {
+	now := time.Now()
+	upstream := upstreamRun("build-and-test-1", v1alpha1.StatusCompleted, "main", now)
+	other := deployTrigger(now.Add(-time.Hour))
+	other.Name = "other"
+	other.Spec.WorkflowRun.Workflow = "lint"
+	client := fake.NewSimpleClientset(upstream, deployTrigger(now.Add(-time.Hour)), other)
+	c := &Chainer{client: client, recorder: record.NewFakeRecorder(10)}
+
+	c.Chain(upstream)
+	wfrs, err := client.CycloneV1alpha1().WorkflowRuns("default").List(metav1.ListOptions{
+		LabelSelector: common.TriggerTypeLabelName + "=" + string(v1alpha1.TriggerTypeWorkflowRun),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(wfrs.Items))
+	wfr := wfrs.Items[0]
+	assert.Equal(t, "deploy", wfr.Spec.WorkflowRef.Name)
+	assert.Equal(t, "p1", wfr.Labels[common.ProjectNameLabelName])
+	assert.Equal(t, "deploy-staging", wfr.Labels[common.WorkflowTriggerNameLabelName])
+	assert.Equal(t, &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeWorkflowRun, Trigger: "deploy-staging", UpstreamRun: upstream.Name}, wfr.Spec.TriggeredBy)
+	assert.Equal(t, []v1alpha1.ParameterItem{{Name: "image", Value: "app:v1"}, {Name: "env", Value: "staging"}}, wfr.Spec.Stages[0].Parameters)
+
+	latest, err := client.CycloneV1alpha1().WorkflowRuns("default").Get(upstream.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha1.StatusCompleted, latest.Status.Chained)
+	wft, err := client.CycloneV1alpha1().WorkflowTriggers("default").Get("deploy-staging", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, wft.Status.Count)
+	assert.Equal(t, wfr.Name, wft.Status.LastWorkflowRun)
+
+	// Chained status is skipped, and downstream WorkflowRun isn't started again even if the
+	// status isn't marked.
+	c.Chain(latest)
+	c.Chain(upstream)
+	wfrs, err = client.CycloneV1alpha1().WorkflowRuns("default").List(metav1.ListOptions{
+		LabelSelector: common.TriggerTypeLabelName + "=" + string(v1alpha1.TriggerTypeWorkflowRun),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(wfrs.Items))
+	wft, err = client.CycloneV1alpha1().WorkflowTriggers("default").Get("deploy-staging", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, wft.Status.Count)
+}
+
+func TestSetParameter(t *testing.T) {
+	spec := &v1alpha1.WorkflowRunSpec{}
+	setParameter(spec, "deploy", "image", "app:v1")
+	setParameter(spec, "deploy", "env", "staging")
+	setParameter(spec, "deploy", "image", "app:v2")
+	assert.Equal(t, []v1alpha1.ParameterConfig{
+		{Name: "deploy", Parameters: []v1alpha1.ParameterItem{{Name: "image", Value: "app:v2"}, {Name: "env", Value: "staging"}}},
+	}, spec.Stages)
+}
+
+func TestChainDepth(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(deployTrigger(now.Add(-time.Hour)))
+	recorder := record.NewFakeRecorder(10)
+	c := &Chainer{client: client, recorder: recorder}
+
+	// WorkflowRuns started by each other, for example, workflows triggering each other in a cycle.
+	var runs []*v1alpha1.WorkflowRun
+	for i := 0; i < maxChainDepth; i++ {
+		wfr := upstreamRun(fmt.Sprintf("r%d", i), v1alpha1.StatusCompleted, "main", now)
+		if i > 0 {
+			wfr.Spec.TriggeredBy = &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeWorkflowRun, UpstreamRun: runs[i-1].Name}
+		}
+		_, err := client.CycloneV1alpha1().WorkflowRuns("default").Create(wfr)
+		assert.Nil(t, err)
+		runs = append(runs, wfr)
+	}
+	assert.Equal(t, 1, c.depth(runs[0]))
+	assert.Equal(t, maxChainDepth-1, c.depth(runs[maxChainDepth-2]))
+	assert.Equal(t, maxChainDepth, c.depth(runs[maxChainDepth-1]))
+
+	c.Chain(runs[maxChainDepth-2])
+	c.Chain(runs[maxChainDepth-1])
+	wfrs, err := client.CycloneV1alpha1().WorkflowRuns("default").List(metav1.ListOptions{
+		LabelSelector: common.TriggerTypeLabelName + "=" + string(v1alpha1.TriggerTypeWorkflowRun),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(wfrs.Items))
+	assert.Equal(t, runs[maxChainDepth-2].Name, wfrs.Items[0].Spec.TriggeredBy.UpstreamRun)
+	<-recorder.Events
+	assert.Contains(t, <-recorder.Events, "ChainStopped")
+
+	// Walking stops at deleted WorkflowRuns.
+	assert.Nil(t, client.CycloneV1alpha1().WorkflowRuns("default").Delete(runs[4].Name, nil))
+	assert.Equal(t, maxChainDepth-5, c.depth(runs[maxChainDepth-1]))
+}
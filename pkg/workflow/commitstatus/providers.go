@@ -0,0 +1,193 @@
+package commitstatus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+// State is state of a commit status.
+type State string
+
+const (
+	// StatePending means the WorkflowRun or stage is not started yet.
+	StatePending State = "pending"
+	// StateRunning means the WorkflowRun or stage is running.
+	StateRunning State = "running"
+	// StateSuccess means the WorkflowRun or stage completed.
+	StateSuccess State = "success"
+	// StateFailure means the WorkflowRun or stage failed or was cancelled.
+	StateFailure State = "failure"
+)
+
+// requestTimeout is the timeout of SCM API requests.
+const requestTimeout = 10 * time.Second
+
+var httpClient = &http.Client{Timeout: requestTimeout}
+
+// status is a commit status to report.
+type status struct {
+	state       State
+	context     string
+	description string
+	targetURL   string
+}
+
+// provider reports commit statuses to a type of SCM.
+type provider interface {
+	// resolve resolves the revision, such as a branch or tag, to commit SHA.
+	resolve(repo, revision string) (string, error)
+	// report creates a status for the commit.
+	report(repo, sha string, s *status) error
+}
+
+// newProvider creates a provider for the SCM, server is the server address used when it's
+// not set in the SCM.
+func newProvider(scm *api.SCMSource, server string) (provider, error) {
+	if scm.Server != "" {
+		server = scm.Server
+	}
+	server = strings.TrimSuffix(server, "/")
+
+	switch scm.Type {
+	case api.GitHub:
+		return newGitHub(scm, server), nil
+	case api.GitLab:
+		if scm.AuthType == api.AuthTypePassword {
+			return nil, fmt.Errorf("password auth not supported for GitLab API, use token instead")
+		}
+		return &gitLab{api: server + "/api/v4", token: scm.Token}, nil
+	default:
+		return nil, fmt.Errorf("commit status not supported for SCM type %s", scm.Type)
+	}
+}
+
+// gitHub reports commit statuses to GitHub or GitHub Enterprise.
+type gitHub struct {
+	api string
+	scm *api.SCMSource
+}
+
+func newGitHub(scm *api.SCMSource, server string) *gitHub {
+	apiServer := server + "/api/v3"
+	if u, err := url.Parse(server); err == nil && u.Host == "github.com" {
+		apiServer = "https://api.github.com"
+	}
+	return &gitHub{api: apiServer, scm: scm}
+}
+
+func (g *gitHub) auth(req *http.Request) {
+	if g.scm.AuthType == api.AuthTypePassword {
+		req.SetBasicAuth(g.scm.User, g.scm.Password)
+	} else {
+		req.Header.Set("Authorization", "token "+g.scm.Token)
+	}
+}
+
+func (g *gitHub) resolve(repo, revision string) (string, error) {
+	commit := struct {
+		SHA string `json:"sha"`
+	}{}
+	path := fmt.Sprintf("%s/repos/%s/commits/%s", g.api, repo, url.PathEscape(revision))
+	if err := do(http.MethodGet, path, nil, g.auth, &commit); err != nil {
+		return "", err
+	}
+	return commit.SHA, nil
+}
+
+func (g *gitHub) report(repo, sha string, s *status) error {
+	// GitHub has no running state.
+	state := s.state
+	if state == StateRunning {
+		state = StatePending
+	}
+	body := map[string]string{
+		"state":       string(state),
+		"context":     s.context,
+		"description": s.description,
+		"target_url":  s.targetURL,
+	}
+	path := fmt.Sprintf("%s/repos/%s/statuses/%s", g.api, repo, sha)
+	return do(http.MethodPost, path, body, g.auth, nil)
+}
+
+// gitLab reports commit statuses to GitLab.
+type gitLab struct {
+	api   string
+	token string
+}
+
+func (g *gitLab) auth(req *http.Request) {
+	req.Header.Set("Private-Token", g.token)
+}
+
+func (g *gitLab) resolve(repo, revision string) (string, error) {
+	commit := struct {
+		ID string `json:"id"`
+	}{}
+	path := fmt.Sprintf("%s/projects/%s/repository/commits/%s", g.api, url.PathEscape(repo), url.PathEscape(revision))
+	if err := do(http.MethodGet, path, nil, g.auth, &commit); err != nil {
+		return "", err
+	}
+	return commit.ID, nil
+}
+
+func (g *gitLab) report(repo, sha string, s *status) error {
+	state := string(s.state)
+	if s.state == StateFailure {
+		state = "failed"
+	}
+	body := map[string]string{
+		"state":       state,
+		"name":        s.context,
+		"description": s.description,
+		"target_url":  s.targetURL,
+	}
+	path := fmt.Sprintf("%s/projects/%s/statuses/%s", g.api, url.PathEscape(repo), sha)
+	return do(http.MethodPost, path, body, g.auth, nil)
+}
+
+// do sends a request to SCM API, body is encoded in JSON and response is decoded into result
+// if it's not nil.
+func do(method, path string, body interface{}, auth func(*http.Request), result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	auth(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		content, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: unexpected response status %d: %s", method, path, resp.StatusCode, content)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
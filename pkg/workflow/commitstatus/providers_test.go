@@ -0,0 +1,86 @@
+package commitstatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+)
+
+const sha = "0123456789abcdef0123456789abcdef01234567"
+
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   map[string]string
+}
+
+func scmStub(response string) (*httptest.Server, <-chan request) {
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make(map[string]string)
+		json.NewDecoder(r.Body).Decode(&body)
+		requests <- request{method: r.Method, path: r.URL.EscapedPath(), header: r.Header, body: body}
+		fmt.Fprint(w, response)
+	}))
+	return server, requests
+}
+
+func TestGitHub(t *testing.T) {
+	server, requests := scmStub(fmt.Sprintf(`{"sha": "%s"}`, sha))
+	defer server.Close()
+
+	p, err := newProvider(&api.SCMSource{Type: api.GitHub, Server: server.URL, Token: "token", AuthType: api.AuthTypeToken}, "")
+	assert.Nil(t, err)
+
+	resolved, err := p.resolve("caicloud/cyclone", "feature/a")
+	assert.Nil(t, err)
+	assert.Equal(t, sha, resolved)
+	r := <-requests
+	assert.Equal(t, "/api/v3/repos/caicloud/cyclone/commits/feature%2Fa", r.path)
+	assert.Equal(t, "token token", r.header.Get("Authorization"))
+
+	err = p.report("caicloud/cyclone", sha, &status{state: StateRunning, context: "cyclone/wf1", targetURL: "http://cyclone/wfr"})
+	assert.Nil(t, err)
+	r = <-requests
+	assert.Equal(t, http.MethodPost, r.method)
+	assert.Equal(t, "/api/v3/repos/caicloud/cyclone/statuses/"+sha, r.path)
+	assert.Equal(t, "pending", r.body["state"])
+	assert.Equal(t, "cyclone/wf1", r.body["context"])
+	assert.Equal(t, "http://cyclone/wfr", r.body["target_url"])
+
+	assert.Equal(t, "https://api.github.com", newGitHub(&api.SCMSource{}, "https://github.com").api)
+}
+
+func TestGitLab(t *testing.T) {
+	server, requests := scmStub(fmt.Sprintf(`{"id": "%s"}`, sha))
+	defer server.Close()
+
+	p, err := newProvider(&api.SCMSource{Type: api.GitLab, Token: "token", AuthType: api.AuthTypeToken}, server.URL)
+	assert.Nil(t, err)
+
+	resolved, err := p.resolve("group/sub/repo", "master")
+	assert.Nil(t, err)
+	assert.Equal(t, sha, resolved)
+	r := <-requests
+	assert.Equal(t, "/api/v4/projects/group%2Fsub%2Frepo/repository/commits/master", r.path)
+	assert.Equal(t, "token", r.header.Get("Private-Token"))
+
+	err = p.report("group/sub/repo", sha, &status{state: StateFailure, context: "cyclone/wf1/build"})
+	assert.Nil(t, err)
+	r = <-requests
+	assert.Equal(t, "/api/v4/projects/group%2Fsub%2Frepo/statuses/"+sha, r.path)
+	assert.Equal(t, "failed", r.body["state"])
+	assert.Equal(t, "cyclone/wf1/build", r.body["name"])
+
+	_, err = newProvider(&api.SCMSource{Type: api.GitLab, AuthType: api.AuthTypePassword}, server.URL)
+	assert.NotNil(t, err)
+	_, err = newProvider(&api.SCMSource{Type: api.SVN}, server.URL)
+	assert.NotNil(t, err)
+}
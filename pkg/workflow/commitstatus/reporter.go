@@ -0,0 +1,266 @@
+package commitstatus
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	servercommon "github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+)
+
+// Reporter reports statuses of WorkflowRuns and their stages to the commits they run on, so
+// that results can be seen in SCM, for example, on merge requests.
+type Reporter struct {
+	client   clientset.Interface
+	recorder record.EventRecorder
+	// newProvider creates provider for the SCM, it can be replaced in tests.
+	newProvider func(scm *api.SCMSource, server string) (provider, error)
+}
+
+// NewReporter creates a commit status reporter.
+func NewReporter(client clientset.Interface) *Reporter {
+	return &Reporter{
+		client:      client,
+		recorder:    common.GetEventRecorder(client, common.EventSourceWfrController),
+		newProvider: newProvider,
+	}
+}
+
+// Report reports statuses of the WorkflowRun and its stages that changed since last reported.
+// Error is returned if some statuses are failed to report, they should be retried later.
+func (r *Reporter) Report(wfr *v1alpha1.WorkflowRun) error {
+	if !controller.Config.CommitStatus.Enabled {
+		return nil
+	}
+
+	recorded := wfr.Status.CommitStatus
+	if recorded != nil && recorded.Repo == "" {
+		return nil
+	}
+
+	changed := make(map[string]State)
+	for context, state := range states(wfr) {
+		if recorded == nil || recorded.States[context] != string(state) {
+			changed[context] = state
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return r.report(wfr, changed)
+}
+
+// report reports the changed statuses and records them in the WorkflowRun.
+func (r *Reporter) report(wfr *v1alpha1.WorkflowRun, changed map[string]State) error {
+	logger := log.WithField("wfr", wfr.Name)
+	scm, err := scmSource(r.client, wfr)
+	if err != nil {
+		return fmt.Errorf("get SCM integration error: %v", err)
+	}
+
+	recorded := wfr.Status.CommitStatus
+	if recorded == nil {
+		src, err := gitResource(r.client, wfr)
+		if err != nil {
+			return fmt.Errorf("get git resource error: %v", err)
+		}
+		if scm == nil || src == nil {
+			logger.Debug("No git resource or SCM integration found, commit status not applicable")
+			return r.record(wfr, &v1alpha1.CommitStatus{}, nil)
+		}
+
+		// Commit pulled by the stage is preferred, since the branch or tag may have moved on
+		// when statuses are reported. Revision is only resolved by the SCM if the commit
+		// pulled is unknown after the stage terminated.
+		revision := src.revision
+		if sha := pulledRevision(wfr, src); sha != "" {
+			revision = sha
+		} else if !isSHA(revision) && pulling(wfr, src) {
+			logger.Debug("Git resource not pulled yet, report commit status later")
+			return nil
+		}
+		recorded = &v1alpha1.CommitStatus{Repo: src.url, SHA: revision}
+	}
+	if scm == nil {
+		return nil
+	}
+
+	server, repo, err := parseRepo(recorded.Repo)
+	if err != nil {
+		logger.Warning("Parse git repository url error: ", err)
+		return r.record(wfr, &v1alpha1.CommitStatus{}, nil)
+	}
+	p, err := r.newProvider(scm, server)
+	if err != nil {
+		r.recorder.Eventf(wfr, corev1.EventTypeWarning, "CommitStatusFailed", "Report commit status error: %v", err)
+		return r.record(wfr, &v1alpha1.CommitStatus{}, nil)
+	}
+
+	if !isSHA(recorded.SHA) {
+		sha, err := p.resolve(repo, recorded.SHA)
+		if err != nil {
+			return fmt.Errorf("resolve revision %s error: %v", recorded.SHA, err)
+		}
+		recorded.SHA = sha
+	}
+
+	reported := make(map[string]State)
+	var failed []string
+	for context, state := range changed {
+		s := &status{
+			state:       state,
+			context:     context,
+			description: description(wfr, context, state),
+			targetURL:   targetURL(wfr, context),
+		}
+		if err := p.report(repo, recorded.SHA, s); err != nil {
+			logger.WithField("context", context).Warning("Report commit status error: ", err)
+			r.recorder.Eventf(wfr, corev1.EventTypeWarning, "CommitStatusFailed", "Report commit status '%s' error: %v", context, err)
+			failed = append(failed, context)
+			continue
+		}
+		reported[context] = state
+	}
+
+	if err := r.record(wfr, recorded, reported); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("report commit status %s error", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// record records the reported states in the WorkflowRun.
+func (r *Reporter) record(wfr *v1alpha1.WorkflowRun, commit *v1alpha1.CommitStatus, reported map[string]State) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := r.client.CycloneV1alpha1().WorkflowRuns(wfr.Namespace).Get(wfr.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if latest.Status.CommitStatus == nil {
+			latest.Status.CommitStatus = &v1alpha1.CommitStatus{Repo: commit.Repo, SHA: commit.SHA}
+		}
+		if latest.Status.CommitStatus.States == nil && len(reported) > 0 {
+			latest.Status.CommitStatus.States = make(map[string]string)
+		}
+		for context, state := range reported {
+			latest.Status.CommitStatus.States[context] = string(state)
+		}
+
+		_, err = r.client.CycloneV1alpha1().WorkflowRuns(wfr.Namespace).Update(latest)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("record commit status error: %v", err)
+	}
+	return nil
+}
+
+// states gets states of the WorkflowRun and its stages, keyed by context.
+func states(wfr *v1alpha1.WorkflowRun) map[string]State {
+	results := make(map[string]State)
+	workflow := wfr.Spec.WorkflowRef.Name
+	if wfr.Status.Overall.Status != "" {
+		results[overallContext(workflow)] = toState(wfr.Status.Overall.Status)
+	}
+	for stage, s := range wfr.Status.Stages {
+		results[stageContext(workflow, stage)] = toState(s.Status.Status)
+	}
+	return results
+}
+
+// toState converts status of WorkflowRun or stage to commit status state.
+func toState(status string) State {
+	switch status {
+	case v1alpha1.StatusRunning:
+		return StateRunning
+	case v1alpha1.StatusCompleted:
+		return StateSuccess
+	case v1alpha1.StatusError, v1alpha1.StatusCancelled:
+		return StateFailure
+	default:
+		return StatePending
+	}
+}
+
+// overallContext is the context of the status of the whole WorkflowRun.
+func overallContext(workflow string) string {
+	return fmt.Sprintf("cyclone/%s", workflow)
+}
+
+// stageContext is the context of the status of a stage.
+func stageContext(workflow, stage string) string {
+	return fmt.Sprintf("cyclone/%s/%s", workflow, stage)
+}
+
+// stageOf gets the stage of the context, it's empty for the whole WorkflowRun.
+func stageOf(wfr *v1alpha1.WorkflowRun, context string) string {
+	for stage := range wfr.Status.Stages {
+		if context == stageContext(wfr.Spec.WorkflowRef.Name, stage) {
+			return stage
+		}
+	}
+	return ""
+}
+
+// description describes the state in commit statuses.
+func description(wfr *v1alpha1.WorkflowRun, context string, state State) string {
+	subject := fmt.Sprintf("WorkflowRun %s", wfr.Name)
+	if stage := stageOf(wfr, context); stage != "" {
+		subject = fmt.Sprintf("Stage %s of WorkflowRun %s", stage, wfr.Name)
+	}
+
+	switch state {
+	case StateRunning:
+		return subject + " is running"
+	case StateSuccess:
+		return subject + " completed"
+	case StateFailure:
+		return subject + " failed"
+	default:
+		return subject + " is pending"
+	}
+}
+
+// targetURL renders link to the WorkflowRun page with the template configured.
+func targetURL(wfr *v1alpha1.WorkflowRun, context string) string {
+	text := controller.Config.CommitStatus.TargetURL
+	if text == "" {
+		return ""
+	}
+	t, err := template.New("target").Parse(text)
+	if err != nil {
+		log.WithField("template", text).Warning("Parse target url template error: ", err)
+		return ""
+	}
+
+	buf := &bytes.Buffer{}
+	err = t.Execute(buf, map[string]string{
+		"Tenant":      servercommon.NamespaceTenant(wfr.Namespace),
+		"Project":     wfr.Labels[common.ProjectNameLabelName],
+		"Workflow":    wfr.Spec.WorkflowRef.Name,
+		"WorkflowRun": wfr.Name,
+		"Stage":       stageOf(wfr, context),
+	})
+	if err != nil {
+		log.WithField("template", text).Warning("Execute target url template error: ", err)
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,187 @@
+package commitstatus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+)
+
+type fakeProvider struct {
+	repo     string
+	statuses []*status
+}
+
+func (p *fakeProvider) resolve(repo, revision string) (string, error) {
+	return sha, nil
+}
+
+func (p *fakeProvider) report(repo, sha string, s *status) error {
+	p.repo = repo
+	p.statuses = append(p.statuses, s)
+	return nil
+}
+
+func objects(scm bool) []runtime.Object {
+	integration, _ := json.Marshal(&api.IntegrationSpec{
+		Type:              api.SCM,
+		IntegrationSource: api.IntegrationSource{SCM: &api.SCMSource{Type: api.GitHub}},
+	})
+	project := &v1alpha1.Project{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"}}
+	if scm {
+		project.Spec.Integrations = []v1alpha1.IntegrationItem{{Type: string(api.SCM), Name: "github"}}
+	}
+
+	return []runtime.Object{
+		project,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: "default"},
+			Data:       map[string][]byte{"integration": integration},
+		},
+		&v1alpha1.Workflow{
+			ObjectMeta: metav1.ObjectMeta{Name: "wf1", Namespace: "default"},
+			Spec:       v1alpha1.WorkflowSpec{Stages: []v1alpha1.StageItem{{Name: "build"}}},
+		},
+		&v1alpha1.Stage{
+			ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "default"},
+			Spec: v1alpha1.StageSpec{Pod: &v1alpha1.PodWorkload{
+				Inputs: v1alpha1.Inputs{Resources: []v1alpha1.ResourceItem{{Name: "repo"}}},
+			}},
+		},
+		&v1alpha1.Resource{
+			ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "default"},
+			Spec: v1alpha1.ResourceSpec{
+				Type: v1alpha1.GitResourceType,
+				Parameters: []v1alpha1.ParameterItem{
+					{Name: gitURLParameter, Value: "https://github.com/caicloud/cyclone.git"},
+					{Name: gitRevisionParameter, Value: "master"},
+				},
+			},
+		},
+		&v1alpha1.WorkflowRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "wf1-abc",
+				Namespace: "default",
+				UID:       types.UID("wf1-abc"),
+				Labels:    map[string]string{common.ProjectNameLabelName: "p1"},
+			},
+			Spec: v1alpha1.WorkflowRunSpec{
+				WorkflowRef: &corev1.ObjectReference{Name: "wf1"},
+				Resources: []v1alpha1.ParameterConfig{
+					{Name: "repo", Parameters: []v1alpha1.ParameterItem{{Name: gitRevisionParameter, Value: "develop"}}},
+				},
+			},
+			Status: v1alpha1.WorkflowRunStatus{
+				Overall: v1alpha1.Status{Status: v1alpha1.StatusRunning},
+				Stages: map[string]*v1alpha1.StageStatus{
+					"build": {Status: v1alpha1.Status{Status: v1alpha1.StatusError}},
+				},
+			},
+		},
+	}
+}
+
+func newTestReporter(scm bool) (*Reporter, *fakeProvider) {
+	p := &fakeProvider{}
+	client := fake.NewSimpleClientset(objects(scm)...)
+	return &Reporter{
+		client:   client,
+		recorder: record.NewFakeRecorder(10),
+		newProvider: func(scm *api.SCMSource, server string) (provider, error) {
+			return p, nil
+		},
+	}, p
+}
+
+func TestReport(t *testing.T) {
+	pre := controller.Config.CommitStatus
+	defer func() {
+		controller.Config.CommitStatus = pre
+	}()
+	controller.Config.CommitStatus.TargetURL = "https://cyclone/projects/{{.Project}}/workflowruns/{{.WorkflowRun}}"
+
+	r, p := newTestReporter(true)
+	wfr, _ := r.client.CycloneV1alpha1().WorkflowRuns("default").Get("wf1-abc", metav1.GetOptions{})
+	assert.Nil(t, r.report(wfr, states(wfr)))
+
+	assert.Equal(t, "caicloud/cyclone", p.repo)
+	assert.Equal(t, 2, len(p.statuses))
+	for _, s := range p.statuses {
+		assert.Equal(t, "https://cyclone/projects/p1/workflowruns/wf1-abc", s.targetURL)
+		if s.context == "cyclone/wf1/build" {
+			assert.Equal(t, StateFailure, s.state)
+			assert.Equal(t, "Stage build of WorkflowRun wf1-abc failed", s.description)
+		} else {
+			assert.Equal(t, "cyclone/wf1", s.context)
+			assert.Equal(t, StateRunning, s.state)
+		}
+	}
+
+	wfr, _ = r.client.CycloneV1alpha1().WorkflowRuns("default").Get("wf1-abc", metav1.GetOptions{})
+	assert.Equal(t, &v1alpha1.CommitStatus{
+		Repo:   "https://github.com/caicloud/cyclone.git",
+		SHA:    sha,
+		States: map[string]string{"cyclone/wf1": "running", "cyclone/wf1/build": "failure"},
+	}, wfr.Status.CommitStatus)
+
+	// Reported statuses are not reported again.
+	controller.Config.CommitStatus.Enabled = true
+	assert.Nil(t, r.Report(wfr))
+	assert.Equal(t, 2, len(p.statuses))
+}
+
+func TestReportPulledRevision(t *testing.T) {
+	pulled := "fedcba9876543210fedcba9876543210fedcba98"
+	r, p := newTestReporter(true)
+	wfr, _ := r.client.CycloneV1alpha1().WorkflowRuns("default").Get("wf1-abc", metav1.GetOptions{})
+
+	// Statuses are not reported before the branch is pulled.
+	wfr.Status.Stages["build"].Status.Status = v1alpha1.StatusRunning
+	r.report(wfr, states(wfr))
+	assert.Equal(t, 0, len(p.statuses))
+
+	// Commit pulled is reported rather than head of the branch.
+	wfr.Status.Stages["build"].Resources = []v1alpha1.ResourceStatus{
+		{Name: "repo", Direction: v1alpha1.ResourceDirectionInput, Results: map[string]string{"revision": pulled}},
+	}
+	r.report(wfr, states(wfr))
+	assert.Equal(t, 2, len(p.statuses))
+	wfr, _ = r.client.CycloneV1alpha1().WorkflowRuns("default").Get("wf1-abc", metav1.GetOptions{})
+	assert.Equal(t, pulled, wfr.Status.CommitStatus.SHA)
+}
+
+func TestReportNotApplicable(t *testing.T) {
+	r, p := newTestReporter(false)
+	wfr, _ := r.client.CycloneV1alpha1().WorkflowRuns("default").Get("wf1-abc", metav1.GetOptions{})
+	r.report(wfr, states(wfr))
+
+	assert.Equal(t, 0, len(p.statuses))
+	wfr, _ = r.client.CycloneV1alpha1().WorkflowRuns("default").Get("wf1-abc", metav1.GetOptions{})
+	assert.Equal(t, &v1alpha1.CommitStatus{}, wfr.Status.CommitStatus)
+}
+
+func TestParseRepo(t *testing.T) {
+	server, repo, err := parseRepo("https://gitlab.example.com/group/sub/repo.git")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://gitlab.example.com", server)
+	assert.Equal(t, "group/sub/repo", repo)
+
+	_, _, err = parseRepo("git@github.com:caicloud/cyclone.git")
+	assert.NotNil(t, err)
+	_, _, err = parseRepo("https://github.com/cyclone")
+	assert.NotNil(t, err)
+
+	assert.True(t, isSHA(sha))
+	assert.False(t, isSHA("master"))
+}
@@ -0,0 +1,187 @@
+package commitstatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	servercommon "github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/resolver"
+)
+
+const (
+	// gitURLParameter is the parameter of git resources for repository url.
+	gitURLParameter = "GIT_URL"
+	// gitRevisionParameter is the parameter of git resources for revision, such as branch or tag.
+	gitRevisionParameter = "GIT_REVISION"
+)
+
+var shaRegexp = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// isSHA checks whether the revision is a full commit SHA.
+func isSHA(revision string) bool {
+	return shaRegexp.MatchString(revision)
+}
+
+// scmSource gets the SCM in SCM integration of the Project that the WorkflowRun belongs to,
+// nil is returned if there is no such integration.
+func scmSource(client clientset.Interface, wfr *v1alpha1.WorkflowRun) (*api.SCMSource, error) {
+	name, ok := wfr.Labels[common.ProjectNameLabelName]
+	if !ok {
+		return nil, nil
+	}
+	project, err := client.CycloneV1alpha1().Projects(wfr.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var integration string
+	for _, i := range project.Spec.Integrations {
+		if i.Type == string(api.SCM) {
+			integration = i.Name
+			break
+		}
+	}
+	if integration == "" {
+		return nil, nil
+	}
+
+	secret, err := client.CoreV1().Secrets(wfr.Namespace).Get(servercommon.IntegrationSecret(integration), metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	spec := &api.IntegrationSpec{}
+	if err := json.Unmarshal(secret.Data[servercommon.SecretKeyIntegration], spec); err != nil {
+		return nil, fmt.Errorf("unmarshal integration %s error: %v", integration, err)
+	}
+
+	return spec.SCM, nil
+}
+
+// gitSource is a git resource pulled by a stage of the WorkflowRun.
+type gitSource struct {
+	// stage is the stage that pulls the resource.
+	stage string
+	// resource is name of the resource.
+	resource string
+	url      string
+	revision string
+}
+
+// gitResource gets the first git resource used by stages of the WorkflowRun, parameters given
+// in the WorkflowRun override those in the resource. Nil is returned if there is no git resource.
+func gitResource(client clientset.Interface, wfr *v1alpha1.WorkflowRun) (*gitSource, error) {
+	wf, err := client.CycloneV1alpha1().Workflows(wfr.Namespace).Get(wfr.Spec.WorkflowRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range wf.Spec.Stages {
+		stage, err := client.CycloneV1alpha1().Stages(wfr.Namespace).Get(s.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if stage.Spec.Pod == nil {
+			continue
+		}
+
+		for _, r := range stage.Spec.Pod.Inputs.Resources {
+			resource, err := client.CycloneV1alpha1().Resources(wfr.Namespace).Get(r.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			if resource.Spec.Type != v1alpha1.GitResourceType {
+				continue
+			}
+
+			parameters := make(map[string]string)
+			for _, p := range resource.Spec.Parameters {
+				parameters[p.Name] = p.Value
+			}
+			for _, c := range wfr.Spec.Resources {
+				if c.Name != r.Name {
+					continue
+				}
+				for _, p := range c.Parameters {
+					parameters[p.Name] = p.Value
+				}
+			}
+			if parameters[gitURLParameter] != "" {
+				return &gitSource{
+					stage:    s.Name,
+					resource: r.Name,
+					url:      parameters[gitURLParameter],
+					revision: parameters[gitRevisionParameter],
+				}, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// pulledRevision gets commit SHA of the git resource pulled by the stage, which is recorded in
+// status of the WorkflowRun by the resolver. Empty string is returned if it's not recorded.
+func pulledRevision(wfr *v1alpha1.WorkflowRun, src *gitSource) string {
+	status, ok := wfr.Status.Stages[src.stage]
+	if !ok || status == nil {
+		return ""
+	}
+	for _, r := range status.Resources {
+		if r.Name != src.resource || r.Direction != v1alpha1.ResourceDirectionInput {
+			continue
+		}
+		if revision := r.Results[resolver.ResultRevision]; isSHA(revision) {
+			return revision
+		}
+	}
+	return ""
+}
+
+// pulling checks whether the git resource may still be pulled by the stage, that is, neither the
+// stage nor the WorkflowRun is terminated.
+func pulling(wfr *v1alpha1.WorkflowRun, src *gitSource) bool {
+	terminated := func(status string) bool {
+		state := toState(status)
+		return state == StateSuccess || state == StateFailure
+	}
+	if terminated(wfr.Status.Overall.Status) {
+		return false
+	}
+	status, ok := wfr.Status.Stages[src.stage]
+	return !ok || status == nil || !terminated(status.Status.Status)
+}
+
+// parseRepo parses the git repository url into server address and repository path, for
+// example, 'https://github.com/caicloud/cyclone.git' is parsed into 'https://github.com'
+// and 'caicloud/cyclone'.
+func parseRepo(gitURL string) (string, string, error) {
+	u, err := url.Parse(gitURL)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", fmt.Errorf("unsupported git url %s, only http and https supported", gitURL)
+	}
+
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if !strings.Contains(path, "/") {
+		return "", "", fmt.Errorf("invalid repository path in git url %s", gitURL)
+	}
+	return fmt.Sprintf("%s://%s", u.Scheme, u.Host), path, nil
+}
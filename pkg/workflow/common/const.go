@@ -65,6 +65,12 @@ const (
 	WorkflowLabelName = "cyclone.io/workflow"
 	// WorkflowNameLabelName is label applied to WorkflowRun to specify Workflow
 	WorkflowNameLabelName = "cyclone.io/workflow-name"
+	// ProjectNameLabelName is label applied to WorkflowRun to specify Project
+	ProjectNameLabelName = "cyclone.io/project-name"
+	// WorkflowTriggerNameLabelName is label applied to WorkflowRun to specify WorkflowTrigger that created it
+	WorkflowTriggerNameLabelName = "cyclone.io/workflowtrigger-name"
+	// TriggerTypeLabelName is label applied to WorkflowRun to specify type of the source that started it
+	TriggerTypeLabelName = "cyclone.io/trigger-type"
 	// PodLabelSelector is selector used to select pod created by Cyclone stages
 	PodLabelSelector = "cyclone.io/workflow==true"
 	// WorkflowRunAnnotationName is annotation applied to pod to specify WorkflowRun the pod belongs to
@@ -15,23 +15,39 @@ import (
 const (
 	// EventSourceWfrController represents events send from workflowrun controller.
 	EventSourceWfrController string = "WorkflowRunController"
+	// EventSourceWfController represents events send from workflow controller.
+	EventSourceWfController string = "WorkflowController"
+	// EventSourceLeaderElection represents events send from leader election of workflow controllers.
+	EventSourceLeaderElection string = "LeaderElection"
 )
 
-// EventRecorder is used to record events to k8s, controllers here would use it to record
-// events reflecting the WorkflowRun executing process.
-var eventRecorder record.EventRecorder
+// broadcaster is used to record events to k8s, controllers here would use recorders created
+// from it to record events reflecting the WorkflowRun executing process.
+var broadcaster record.EventBroadcaster
 
-// Once is used to ensure that the eventRecorder is initailized only once.
-var once sync.Once
+// eventRecorders are event recorders created for each component.
+var eventRecorders = make(map[string]record.EventRecorder)
 
-// GetEventRecorder get the event recorder object. Create it of not exists yet.
+// lock is used to ensure that the broadcaster and recorders are initailized only once.
+var lock sync.Mutex
+
+// GetEventRecorder get the event recorder object of the component. Create it of not exists yet.
 func GetEventRecorder(client clientset.Interface, component string) record.EventRecorder {
-	once.Do(func() {
-		log.Info("Creating event recorder")
-		broadcaster := record.NewBroadcaster()
+	lock.Lock()
+	defer lock.Unlock()
+
+	if broadcaster == nil {
+		log.Info("Creating event broadcaster")
+		broadcaster = record.NewBroadcaster()
 		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
-		eventRecorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
-	})
+	}
+
+	recorder, ok := eventRecorders[component]
+	if !ok {
+		log.WithField("component", component).Info("Creating event recorder")
+		recorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
+		eventRecorders[component] = recorder
+	}
 
-	return eventRecorder
+	return recorder
 }
@@ -50,6 +50,12 @@ type WorkflowControllerConfig struct {
 	GC GCConfig `json:"gc"`
 	// Limits of each resources should be retained
 	Limits LimitsConfig `json:"limits"`
+	// Pending configures how long stage pods can be stuck before stages fail
+	Pending PendingConfig `json:"pending"`
+	// Notification configures how to send notifications of WorkflowRuns
+	Notification NotificationConfig `json:"notification"`
+	// CommitStatus configures reporting commit statuses to SCM
+	CommitStatus CommitStatusConfig `json:"commit_status"`
 	// ResourceRequirements is default resource requirements for containers in stage Pod
 	ResourceRequirements corev1.ResourceRequirements `json:"default_resource_quota"`
 	// ExecutionContext defines default namespace and pvc used to run workflow.
@@ -96,6 +102,71 @@ type LimitsConfig struct {
 	MaxWorkflowRuns int `json:"max_workflowruns"`
 }
 
+// PendingConfig configures how long stage pods can be stuck in pending states, such as failing
+// to pull images or being unschedulable, before stages fail. 0 means using the default grace
+// period, and negative value means no limit.
+type PendingConfig struct {
+	// ImagePullGraceSeconds is the time containers can keep failing to pull images or to be created.
+	ImagePullGraceSeconds time.Duration `json:"image_pull_grace_seconds"`
+	// UnschedulableGraceSeconds is the time stage pods can be unschedulable.
+	UnschedulableGraceSeconds time.Duration `json:"unschedulable_grace_seconds"`
+}
+
+const (
+	// DefaultImagePullGraceSeconds is the default grace period of image pull failures.
+	DefaultImagePullGraceSeconds = 300
+	// DefaultUnschedulableGraceSeconds is the default grace period of unschedulable pods.
+	DefaultUnschedulableGraceSeconds = 600
+)
+
+// ImagePullGracePeriod gets grace period of image pull failures, negative means no limit.
+func (c PendingConfig) ImagePullGracePeriod() time.Duration {
+	return gracePeriod(c.ImagePullGraceSeconds, DefaultImagePullGraceSeconds)
+}
+
+// UnschedulableGracePeriod gets grace period of unschedulable pods, negative means no limit.
+func (c PendingConfig) UnschedulableGracePeriod() time.Duration {
+	return gracePeriod(c.UnschedulableGraceSeconds, DefaultUnschedulableGraceSeconds)
+}
+
+func gracePeriod(seconds, defaultSeconds time.Duration) time.Duration {
+	if seconds == 0 {
+		seconds = defaultSeconds
+	}
+	return time.Second * seconds
+}
+
+// NotificationConfig configures sending notifications of WorkflowRuns.
+type NotificationConfig struct {
+	// SMTP is the SMTP server to send email notifications.
+	SMTP SMTPConfig `json:"smtp"`
+	// RetryCount defines how many times to retry when sending a notification failed, 0 means no retry.
+	RetryCount int `json:"retry"`
+}
+
+// SMTPConfig configures the SMTP server.
+type SMTPConfig struct {
+	// Address of the SMTP server, in 'host:port' form.
+	Address string `json:"address"`
+	// Username to authenticate to the SMTP server, no authentication if empty.
+	Username string `json:"username"`
+	// Password to authenticate to the SMTP server.
+	Password string `json:"password"`
+	// From is the sender address of emails.
+	From string `json:"from"`
+}
+
+// CommitStatusConfig configures reporting statuses of WorkflowRuns and stages to commits in SCM,
+// the SCM is the one in SCM integration of the Project.
+type CommitStatusConfig struct {
+	// Enabled controls whether commit statuses are reported.
+	Enabled bool `json:"enabled"`
+	// TargetURL is the template of links to WorkflowRun pages, such as
+	// 'https://cyclone.example.com/projects/{{.Project}}/workflows/{{.Workflow}}/workflowruns/{{.WorkflowRun}}'.
+	// Tenant, Project, Workflow, WorkflowRun and Stage can be used in the template.
+	TargetURL string `json:"target_url"`
+}
+
 // Config is Workflow Controller config instance
 var Config WorkflowControllerConfig
 
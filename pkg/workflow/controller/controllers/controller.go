@@ -12,6 +12,7 @@ import (
 
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/workflow/controller/handlers"
+	"github.com/caicloud/cyclone/pkg/workflow/metrics"
 )
 
 // Controller ...
@@ -35,6 +36,20 @@ const (
 	DELETE
 )
 
+// String returns name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case CREATE:
+		return "create"
+	case UPDATE:
+		return "update"
+	case DELETE:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
 // Event ...
 type Event struct {
 	Key       string
@@ -90,6 +105,8 @@ func (c *Controller) nextWork() bool {
 }
 
 func (c *Controller) doWork(e Event) error {
+	defer metrics.ObserveReconcile(c.name, e.EventType.String(), time.Now())
+
 	switch e.EventType {
 	case CREATE:
 		c.eventHandler.ObjectCreated(e.Object)
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"reflect"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	"github.com/caicloud/cyclone/pkg/k8s/informers"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+	handlers "github.com/caicloud/cyclone/pkg/workflow/controller/handlers/workflow"
+	"github.com/caicloud/cyclone/pkg/workflow/coordinator/cycloneserver"
+	"github.com/caicloud/cyclone/pkg/workflow/workflowstatus"
+)
+
+// NewWorkflowController creates a controller maintaining status of Workflows.
+func NewWorkflowController(client clientset.Interface) *Controller {
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	factory := informers.NewSharedInformerFactory(
+		client,
+		common.ResyncPeriod,
+	)
+
+	informer := factory.Cyclone().V1alpha1().Workflows().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			key, err := cache.MetaNamespaceKeyFunc(obj)
+			if err != nil {
+				return
+			}
+			queue.Add(Event{
+				Key:       key,
+				EventType: CREATE,
+				Object:    obj,
+			})
+		},
+		UpdateFunc: func(old, new interface{}) {
+			key, err := cache.MetaNamespaceKeyFunc(new)
+			if err != nil {
+				return
+			}
+
+			// Skip updates of status only, they are made by the controller itself. Resyncs
+			// are still processed to resolve stages again.
+			oldWf, newWf := old.(*v1alpha1.Workflow), new.(*v1alpha1.Workflow)
+			if oldWf.ResourceVersion != newWf.ResourceVersion && reflect.DeepEqual(oldWf.Spec, newWf.Spec) {
+				return
+			}
+
+			queue.Add(Event{
+				Key:       key,
+				EventType: UPDATE,
+				Object:    new,
+			})
+		},
+	})
+
+	return &Controller{
+		name:      "Workflow Controller",
+		clientSet: client,
+		informer:  informer,
+		queue:     queue,
+		eventHandler: &handlers.Handler{
+			Updater: workflowstatus.NewUpdater(client, cycloneserver.NewClient(controller.Config.CycloneServerAddr)),
+		},
+	}
+}
@@ -1,19 +1,22 @@
 package controllers
 
 import (
+	"reflect"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/k8s/informers"
 	"github.com/caicloud/cyclone/pkg/workflow/controller/handlers/workflowtrigger"
 )
 
-// NewWorkflowTriggerController ...
-func NewWorkflowTriggerController(client clientset.Interface) *Controller {
+// NewWorkflowTriggerController creates the controller of WorkflowTriggers, cron triggers are
+// scheduled until stopCh is closed.
+func NewWorkflowTriggerController(client clientset.Interface, stopCh <-chan struct{}) *Controller {
 	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
 	factory := informers.NewSharedInformerFactoryWithOptions(
 		client,
@@ -39,6 +42,14 @@ func NewWorkflowTriggerController(client clientset.Interface) *Controller {
 			if err != nil {
 				return
 			}
+
+			// Skip updates of status only, they are made by the controller itself when
+			// the trigger fires. Resyncs are still processed.
+			oldWft, newWft := old.(*v1alpha1.WorkflowTrigger), new.(*v1alpha1.WorkflowTrigger)
+			if oldWft.ResourceVersion != newWft.ResourceVersion && reflect.DeepEqual(oldWft.Spec, newWft.Spec) {
+				return
+			}
+
 			log.WithField("name", key).Debug("WorkflowTrigger update observed")
 			queue.Add(Event{
 				Key:       key,
@@ -66,7 +77,7 @@ func NewWorkflowTriggerController(client clientset.Interface) *Controller {
 		informer:  informer,
 		queue:     queue,
 		eventHandler: &workflowtrigger.Handler{
-			CronManager: workflowtrigger.NewTriggerManager(client),
+			CronManager: workflowtrigger.NewTriggerManager(client, stopCh),
 		},
 	}
 }
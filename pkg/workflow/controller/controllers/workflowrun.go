@@ -6,14 +6,25 @@ import (
 
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/k8s/informers"
+	"github.com/caicloud/cyclone/pkg/workflow/chain"
+	"github.com/caicloud/cyclone/pkg/workflow/commitstatus"
 	"github.com/caicloud/cyclone/pkg/workflow/common"
 	"github.com/caicloud/cyclone/pkg/workflow/controller"
 	handlers "github.com/caicloud/cyclone/pkg/workflow/controller/handlers/workflowrun"
+	"github.com/caicloud/cyclone/pkg/workflow/coordinator/cycloneserver"
+	"github.com/caicloud/cyclone/pkg/workflow/history"
+	"github.com/caicloud/cyclone/pkg/workflow/metrics"
+	"github.com/caicloud/cyclone/pkg/workflow/notification"
 	"github.com/caicloud/cyclone/pkg/workflow/workflowrun"
+	"github.com/caicloud/cyclone/pkg/workflow/workflowstatus"
 )
 
-// NewWorkflowRunController ...
-func NewWorkflowRunController(client clientset.Interface) *Controller {
+// hookWorkers is the number of workers to perform hooks of WorkflowRuns.
+const hookWorkers = 4
+
+// NewWorkflowRunController creates the WorkflowRun controller, hooks of WorkflowRuns are
+// performed until stopCh is closed.
+func NewWorkflowRunController(client clientset.Interface, stopCh <-chan struct{}) *Controller {
 	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
 	factory := informers.NewSharedInformerFactory(
 		client,
@@ -57,6 +68,36 @@ func NewWorkflowRunController(client clientset.Interface) *Controller {
 		},
 	})
 
+	timeoutProcessor := workflowrun.NewTimeoutProcessor(client)
+	gcProcessor := workflowrun.NewGCProcessor(client, controller.Config.GC.Enabled)
+	limitedQueues := workflowrun.NewLimitedQueues(client, controller.Config.Limits.MaxWorkflowRuns)
+	server := cycloneserver.NewClient(controller.Config.CycloneServerAddr)
+	archiver := workflowrun.NewArchiver(client, server)
+	limitedQueues.Archiver = archiver
+
+	notifier := notification.NewNotifier(client)
+	reporter := commitstatus.NewReporter(client)
+	chainer := chain.NewChainer(client)
+	recorder := history.NewRecorder(archiver)
+	updater := workflowstatus.NewUpdater(client, server)
+	hookProcessor := workflowrun.NewHookProcessor(
+		// Send notifications and report commit statuses if status transitioned.
+		workflowrun.Hook{Name: "notification", Observe: notifier.Notify},
+		workflowrun.Hook{Name: "commit status", Observe: reporter.Report},
+		// Start WorkflowRuns of downstream workflows chained by WorkflowRun triggers.
+		workflowrun.Hook{Name: "chain", Observe: chainer.Chain},
+		// Archive summary of the WorkflowRun in history once it's terminated, and update status
+		// of the Workflow if status of the WorkflowRun changed.
+		workflowrun.Hook{Name: "history", Observe: recorder.Record, Forget: recorder.Forget},
+		workflowrun.Hook{Name: "workflow status", Observe: updater.Observe, Forget: updater.Forget},
+	)
+	go hookProcessor.Run(hookWorkers, stopCh)
+
+	metrics.RegisterQueue("timeout", timeoutProcessor.Size)
+	metrics.RegisterQueue("gc", gcProcessor.Size)
+	metrics.RegisterQueue("limited", limitedQueues.Size)
+	metrics.RegisterQueue("hooks", hookProcessor.Size)
+
 	return &Controller{
 		name:      "WorkflowRun Controller",
 		clientSet: client,
@@ -64,9 +105,10 @@ func NewWorkflowRunController(client clientset.Interface) *Controller {
 		queue:     queue,
 		eventHandler: &handlers.Handler{
 			Client:           client,
-			TimeoutProcessor: workflowrun.NewTimeoutProcessor(client),
-			GCProcessor:      workflowrun.NewGCProcessor(client, controller.Config.GC.Enabled),
-			LimitedQueues:    workflowrun.NewLimitedQueues(client, controller.Config.Limits.MaxWorkflowRuns),
+			TimeoutProcessor: timeoutProcessor,
+			GCProcessor:      gcProcessor,
+			LimitedQueues:    limitedQueues,
+			HookProcessor:    hookProcessor,
 		},
 	}
 }
@@ -0,0 +1,196 @@
+package pod
+
+import (
+	"fmt"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/logs"
+	"github.com/caicloud/cyclone/pkg/workflow/workflowrun"
+)
+
+const (
+	// lastLinesCount is the number of last lines of output recorded for failed containers.
+	lastLinesCount = 20
+
+	// failedSchedulingReason is reason of events about scheduling failures.
+	failedSchedulingReason = "FailedScheduling"
+)
+
+// problem is a problem found in diagnostics, it's recorded as a WorkflowRun event.
+type problem struct {
+	reason  string
+	message string
+}
+
+// diagnose records diagnostics of the stage pod when the stage failed or got blocked, for
+// example, the pod can't be scheduled or images can't be pulled. Newly found problems are
+// also recorded as WorkflowRun events. If failed is true, last lines of output of failed
+// containers are collected.
+func (p *Operator) diagnose(wfrOperator workflowrun.Operator, failed bool) {
+	diagnostics := collectDiagnostics(p.client, p.pod)
+	found := problems(p.stage, diagnostics)
+	if !failed && len(found) == 0 {
+		return
+	}
+
+	wfr := wfrOperator.GetWorkflowRun()
+	var previous *v1alpha1.StageDiagnostics
+	if status, ok := wfr.Status.Stages[p.stage]; ok {
+		previous = status.Diagnostics
+	}
+	if previous != nil && !failed && reflect.DeepEqual(previous, diagnostics) {
+		return
+	}
+
+	if failed {
+		for i, c := range diagnostics.Containers {
+			if c.ExitCode == nil || *c.ExitCode == 0 {
+				continue
+			}
+			lines, err := logs.Tail(p.client, p.pod.Namespace, p.pod.Name, c.Name, lastLinesCount)
+			if err != nil {
+				log.WithField("pod", p.pod.Name).WithField("container", c.Name).Warning("Get last lines of output error: ", err)
+				continue
+			}
+			diagnostics.Containers[i].LastLines = lines
+		}
+	}
+
+	// Only record events for problems not found before.
+	known := make(map[problem]bool)
+	if previous != nil {
+		for _, pr := range problems(p.stage, previous) {
+			known[pr] = true
+		}
+	}
+	recorder := common.GetEventRecorder(p.client, common.EventSourceWfrController)
+	for _, pr := range found {
+		if !known[pr] {
+			recorder.Event(wfr, corev1.EventTypeWarning, pr.reason, pr.message)
+		}
+	}
+
+	wfrOperator.UpdateStageDiagnostics(p.stage, diagnostics)
+}
+
+// collectDiagnostics collects diagnostics from status of the pod, coordinator is included
+// since its failure also fails the stage.
+func collectDiagnostics(client clientset.Interface, pod *corev1.Pod) *v1alpha1.StageDiagnostics {
+	diagnostics := &v1alpha1.StageDiagnostics{
+		Reason:  pod.Status.Reason,
+		Message: pod.Status.Message,
+	}
+
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable {
+			diagnostics.Scheduling = c.Message
+			if diagnostics.Scheduling == "" {
+				diagnostics.Scheduling = schedulingMessage(client, pod)
+			}
+		}
+	}
+
+	for _, cs := range pod.Status.InitContainerStatuses {
+		diagnostics.Containers = append(diagnostics.Containers, containerDiagnostics(cs, true))
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		diagnostics.Containers = append(diagnostics.Containers, containerDiagnostics(cs, false))
+	}
+
+	return diagnostics
+}
+
+// schedulingMessage gets message of the latest scheduling failure event of the pod.
+func schedulingMessage(client clientset.Interface, pod *corev1.Pod) string {
+	selector := fields.Set{
+		"involvedObject.kind": "Pod",
+		"involvedObject.name": pod.Name,
+		"reason":              failedSchedulingReason,
+	}.AsSelector().String()
+	events, err := client.CoreV1().Events(pod.Namespace).List(metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		log.WithField("pod", pod.Name).Warning("List scheduling events error: ", err)
+		return corev1.PodReasonUnschedulable
+	}
+
+	var latest *corev1.Event
+	for i, e := range events.Items {
+		if latest == nil || latest.LastTimestamp.Before(&e.LastTimestamp) {
+			latest = &events.Items[i]
+		}
+	}
+	if latest == nil {
+		return corev1.PodReasonUnschedulable
+	}
+	return latest.Message
+}
+
+// containerDiagnostics converts status of a container to diagnostics.
+func containerDiagnostics(cs corev1.ContainerStatus, init bool) v1alpha1.ContainerDiagnostics {
+	d := v1alpha1.ContainerDiagnostics{
+		Name: cs.Name,
+		Init: init,
+	}
+
+	switch {
+	case cs.State.Terminated != nil:
+		exitCode := cs.State.Terminated.ExitCode
+		d.State = v1alpha1.ContainerStateTerminated
+		d.ExitCode = &exitCode
+		d.Reason = cs.State.Terminated.Reason
+		d.Message = cs.State.Terminated.Message
+	case cs.State.Running != nil:
+		d.State = v1alpha1.ContainerStateRunning
+	default:
+		d.State = v1alpha1.ContainerStateWaiting
+		if cs.State.Waiting != nil {
+			d.Reason = cs.State.Waiting.Reason
+			d.Message = cs.State.Waiting.Message
+		}
+	}
+
+	return d
+}
+
+// problems finds problems in the diagnostics: pod failure, scheduling failure, containers that
+// would never start and containers terminated with error.
+func problems(stage string, diagnostics *v1alpha1.StageDiagnostics) []problem {
+	var results []problem
+	if diagnostics.Reason != "" {
+		results = append(results, problem{
+			reason:  diagnostics.Reason,
+			message: fmt.Sprintf("Pod of stage '%s' failed: %s", stage, diagnostics.Message),
+		})
+	}
+	if diagnostics.Scheduling != "" {
+		results = append(results, problem{
+			reason:  "StageUnschedulable",
+			message: fmt.Sprintf("Pod of stage '%s' can't be scheduled: %s", stage, diagnostics.Scheduling),
+		})
+	}
+
+	for _, c := range diagnostics.Containers {
+		switch {
+		case c.State == v1alpha1.ContainerStateWaiting && (imagePullFailures[c.Reason] || permanentFailures[c.Reason]):
+			results = append(results, problem{
+				reason:  c.Reason,
+				message: fmt.Sprintf("Container '%s' of stage '%s' can't start: %s", c.Name, stage, c.Message),
+			})
+		case c.ExitCode != nil && *c.ExitCode != 0:
+			results = append(results, problem{
+				reason:  "ContainerFailed",
+				message: fmt.Sprintf("Container '%s' of stage '%s' terminated with exit code %d, reason: %s", c.Name, stage, *c.ExitCode, c.Reason),
+			})
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,67 @@
+package pod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+)
+
+func TestCollectDiagnostics(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/3 nodes are available: 3 Insufficient cpu.",
+				},
+			},
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "i1", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}}},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}}},
+				{Name: "sidecar", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}}},
+				{Name: "csc-co", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			},
+		},
+	}
+
+	diagnostics := collectDiagnostics(fake.NewSimpleClientset(), pod)
+	assert.Equal(t, "0/3 nodes are available: 3 Insufficient cpu.", diagnostics.Scheduling)
+	assert.Equal(t, 4, len(diagnostics.Containers))
+	assert.True(t, diagnostics.Containers[0].Init)
+	assert.Equal(t, v1alpha1.ContainerStateTerminated, diagnostics.Containers[1].State)
+	assert.Equal(t, int32(137), *diagnostics.Containers[1].ExitCode)
+	assert.Equal(t, "OOMKilled", diagnostics.Containers[1].Reason)
+	assert.Equal(t, v1alpha1.ContainerStateWaiting, diagnostics.Containers[2].State)
+	assert.Nil(t, diagnostics.Containers[2].ExitCode)
+	assert.Equal(t, v1alpha1.ContainerStateRunning, diagnostics.Containers[3].State)
+
+	found := problems("build", diagnostics)
+	assert.Equal(t, []problem{
+		{reason: "StageUnschedulable", message: "Pod of stage 'build' can't be scheduled: 0/3 nodes are available: 3 Insufficient cpu."},
+		{reason: "ContainerFailed", message: "Container 'main' of stage 'build' terminated with exit code 137, reason: OOMKilled"},
+		{reason: "ImagePullBackOff", message: "Container 'sidecar' of stage 'build' can't start: Back-off pulling image"},
+	}, found)
+}
+
+func TestProblemsOfPodFailure(t *testing.T) {
+	diagnostics := collectDiagnostics(fake.NewSimpleClientset(), &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase:   corev1.PodFailed,
+			Reason:  "DeadlineExceeded",
+			Message: "Pod was active on the node longer than the specified deadline",
+		},
+	})
+
+	assert.Equal(t, []problem{
+		{reason: "DeadlineExceeded", message: "Pod of stage 'build' failed: Pod was active on the node longer than the specified deadline"},
+	}, problems("build", diagnostics))
+	assert.Nil(t, problems("build", &v1alpha1.StageDiagnostics{}))
+}
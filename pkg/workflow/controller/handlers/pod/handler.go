@@ -1,8 +1,14 @@
 package pod
 
 import (
+	"sync"
+	"time"
+
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/workflow/controller/handlers"
@@ -11,6 +17,10 @@ import (
 // Handler ...
 type Handler struct {
 	Client clientset.Interface
+
+	// lock protects rechecks, which records pods scheduled to be checked again.
+	lock     sync.Mutex
+	rechecks map[types.UID]bool
 }
 
 // Ensure *Handler has implemented handlers.Interface interface.
@@ -37,6 +47,7 @@ func (h *Handler) ObjectDeleted(obj interface{}) {
 		return
 	}
 	log.WithField("name", pod.Name).Debug("Observed pod deleted")
+	tracker.forget(pod.UID)
 
 	// Check whether it's GC pod.
 	if IsGCPod(pod) {
@@ -80,4 +91,38 @@ func (h *Handler) onUpdate(obj interface{}) {
 	if err != nil {
 		log.WithField("pod", pod.Name).Error("process updated pod error: ", err)
 	}
+
+	if d := operator.RecheckAfter(); d > 0 {
+		h.recheck(pod, d)
+	}
+}
+
+// recheck checks the pod again after the given time, it's used for pods stuck in pending
+// state, which may not get updated when the grace period runs out.
+func (h *Handler) recheck(pod *corev1.Pod, after time.Duration) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.rechecks == nil {
+		h.rechecks = make(map[types.UID]bool)
+	}
+	if h.rechecks[pod.UID] {
+		return
+	}
+	h.rechecks[pod.UID] = true
+
+	log.WithField("pod", pod.Name).Debugf("Pod stuck, check it again after %s", after)
+	time.AfterFunc(after, func() {
+		h.lock.Lock()
+		delete(h.rechecks, pod.UID)
+		h.lock.Unlock()
+
+		latest, err := h.Client.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				log.WithField("pod", pod.Name).Warning("Get pod to recheck error: ", err)
+			}
+			return
+		}
+		h.onUpdate(latest)
+	})
 }
@@ -7,6 +7,9 @@ import (
 	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+	"github.com/caicloud/cyclone/pkg/workflow/coordinator/cycloneserver"
+	"github.com/caicloud/cyclone/pkg/workflow/logs"
 	"github.com/caicloud/cyclone/pkg/workflow/workflowrun"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -21,6 +24,9 @@ type Operator struct {
 	stage         string
 	metaNamespace string
 	pod           *corev1.Pod
+	// recheckAfter is the time after which the pod should be checked again, since it's stuck
+	// in pending state and the grace period hasn't run out.
+	recheckAfter time.Duration
 }
 
 // NewOperator ...
@@ -72,6 +78,13 @@ func (p *Operator) OnDelete() error {
 			LastTransitionTime: metav1.Time{Time: time.Now()},
 			Reason:             "PodDeleted",
 		})
+
+		// Logs can't be recovered since the pod is gone, they may be lost if coordinator
+		// didn't finish collecting them.
+		operator.UpdateStageLogsStatus(p.stage, &v1alpha1.LogsStatus{
+			Reason:  "PodDeleted",
+			Message: "Pod was deleted before the stage finished, logs may be incomplete.",
+		})
 	}
 
 	return operator.Update()
@@ -109,6 +122,10 @@ func (p *Operator) OnUpdated() error {
 	}
 
 	status, ok := wfr.Status.Stages[p.stage]
+	wasTerminated := ok && terminated(status)
+	if t := runningTime(p.pod); !t.IsZero() {
+		wfrOperator.UpdateStageRunningTime(p.stage, t)
+	}
 
 	switch p.pod.Status.Phase {
 	case corev1.PodFailed:
@@ -136,9 +153,41 @@ func (p *Operator) OnUpdated() error {
 			})
 		}
 	default:
+		if wasTerminated {
+			break
+		}
+		if stuck := checkPending(p.pod, time.Now()); stuck != nil {
+			if stuck.remaining > 0 {
+				p.recheckAfter = stuck.remaining
+			} else {
+				p.failStuck(wfrOperator, stuck)
+				break
+			}
+		}
 		p.DetermineStatus(wfrOperator)
 	}
 
+	current := wfrOperator.GetWorkflowRun().Status.Stages[p.stage]
+	if !wasTerminated {
+		p.diagnose(wfrOperator, current != nil && current.Status.Status == v1alpha1.StatusError)
+		p.recordResources(wfrOperator)
+	}
+
+	// Once the stage terminates, check its logs and recover those coordinator failed to
+	// collect. GC of the WorkflowRun waits for the recovery, so that the pod is not deleted
+	// before logs are read.
+	if !wasTerminated && terminated(current) {
+		reason := current.Status.Reason
+		if p.pod.Status.Reason != "" {
+			reason = p.pod.Status.Reason
+		}
+		done := workflowrun.StartLogRecovery(origin)
+		go func() {
+			defer done()
+			p.recoverLogs(origin, reason)
+		}()
+	}
+
 	return wfrOperator.Update()
 }
 
@@ -195,3 +244,77 @@ func (p *Operator) DetermineStatus(wfrOperator workflowrun.Operator) {
 		})
 	}
 }
+
+// RecheckAfter returns the time after which the pod should be checked again, 0 means no need.
+func (p *Operator) RecheckAfter() time.Duration {
+	return p.recheckAfter
+}
+
+// failStuck fails the stage whose pod is stuck in pending state for longer than grace period.
+func (p *Operator) failStuck(wfrOperator workflowrun.Operator, stuck *stuckState) {
+	wfr := wfrOperator.GetWorkflowRun()
+	log.WithField("wfr", wfr.Name).
+		WithField("stg", p.stage).
+		WithField("reason", stuck.reason).
+		Info("Stage pod stuck, to fail the stage: ", stuck.message)
+	wfrOperator.UpdateStageStatus(p.stage, &v1alpha1.Status{
+		Status:             v1alpha1.StatusError,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             stuck.reason,
+		Message:            stuck.message,
+	})
+	common.GetEventRecorder(p.client, common.EventSourceWfrController).
+		Eventf(wfr, corev1.EventTypeWarning, stuck.reason, "Stage '%s' failed: %s", p.stage, stuck.message)
+	tracker.forget(p.pod.UID)
+}
+
+// recoverLogs recovers logs of the stage that coordinator failed to collect before the pod
+// gets deleted, and records why the logs may be incomplete in stage status.
+func (p *Operator) recoverLogs(wfr *v1alpha1.WorkflowRun, reason string) {
+	cycloneClient := cycloneserver.NewClient(controller.Config.CycloneServerAddr)
+	status := logs.Recover(p.client, cycloneClient, p.pod, wfr, p.stage, reason)
+	if status == nil {
+		return
+	}
+
+	log.WithField("wfr", wfr.Name).
+		WithField("stage", p.stage).
+		WithField("reason", status.Reason).
+		Warning("Stage logs may be incomplete: ", status.Message)
+	operator, err := workflowrun.NewOperator(p.client, wfr.Name, wfr.Namespace)
+	if err != nil {
+		log.WithField("wfr", wfr.Name).Error("Create WorkflowRun operator error: ", err)
+		return
+	}
+	operator.UpdateStageLogsStatus(p.stage, status)
+	if err := operator.Update(); err != nil {
+		log.WithField("wfr", wfr.Name).Error("Update stage logs status error: ", err)
+	}
+}
+
+// terminated checks whether the stage status is terminated.
+func terminated(status *v1alpha1.StageStatus) bool {
+	return status != nil && (status.Status.Status == v1alpha1.StatusCompleted || status.Status.Status == v1alpha1.StatusError)
+}
+
+// runningTime gets the time when the first container of the pod started running, time spent
+// before it, such as scheduling and pulling images, is regarded as queued. Zero time is
+// returned if no container has started.
+func runningTime(pod *corev1.Pod) metav1.Time {
+	var earliest metav1.Time
+	for _, list := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, cs := range list {
+			var t metav1.Time
+			switch {
+			case cs.State.Running != nil:
+				t = cs.State.Running.StartedAt
+			case cs.State.Terminated != nil:
+				t = cs.State.Terminated.StartedAt
+			}
+			if !t.IsZero() && (earliest.IsZero() || t.Before(&earliest)) {
+				earliest = t
+			}
+		}
+	}
+	return earliest
+}
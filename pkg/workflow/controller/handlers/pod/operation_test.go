@@ -0,0 +1,29 @@
+package pod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRunningTime(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+			},
+		},
+	}
+	assert.Equal(t, metav1.Time{}, runningTime(pod))
+
+	first := metav1.Time{Time: time.Now().Add(-time.Minute)}
+	second := metav1.Time{Time: time.Now()}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{Name: "i1", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{StartedAt: first}}},
+	}
+	pod.Status.ContainerStatuses[0].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: second}}
+	assert.Equal(t, first, runningTime(pod))
+}
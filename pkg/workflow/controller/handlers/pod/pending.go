@@ -0,0 +1,139 @@
+package pod
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+)
+
+// imagePullFailures are reasons of waiting containers that keep failing to pull images or to be
+// created, they may recover, for example, when the registry is back or the secret is created.
+var imagePullFailures = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"RegistryUnavailable":        true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// permanentFailures are reasons of waiting containers that would never start, stages fail
+// immediately in this case.
+var permanentFailures = map[string]bool{
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// stuckState describes a stage pod stuck in pending state.
+type stuckState struct {
+	// reason is the reason of the stage failure if the pod is stuck for longer than grace period.
+	reason string
+	// message describes why the pod is stuck.
+	message string
+	// remaining is the remaining time of the grace period, it's not positive if the pod is
+	// stuck for longer than grace period.
+	remaining time.Duration
+}
+
+// waitingTracker tracks since when containers have been waiting in failure states, since
+// container status doesn't record the time.
+type waitingTracker struct {
+	lock  sync.Mutex
+	since map[types.UID]map[string]time.Time
+}
+
+var tracker = &waitingTracker{since: make(map[types.UID]map[string]time.Time)}
+
+// observe records containers of the pod in failure states, and returns since when they have
+// been observed. Containers not in failure states any more are forgotten.
+func (t *waitingTracker) observe(pod types.UID, failing map[string]bool, now time.Time) map[string]time.Time {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	previous := t.since[pod]
+	current := make(map[string]time.Time)
+	for c := range failing {
+		if since, ok := previous[c]; ok {
+			current[c] = since
+		} else {
+			current[c] = now
+		}
+	}
+
+	if len(current) == 0 {
+		delete(t.since, pod)
+	} else {
+		t.since[pod] = current
+	}
+	return current
+}
+
+// forget forgets containers of the pod.
+func (t *waitingTracker) forget(pod types.UID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.since, pod)
+}
+
+// checkPending checks whether the pod is stuck in states that would not recover without
+// intervention, that are failing to pull images and being unschedulable. It returns nil if
+// the pod is not stuck.
+func checkPending(pod *corev1.Pod, now time.Time) *stuckState {
+	var stuck *stuckState
+	// Keep the state that runs out of grace period first.
+	update := func(s *stuckState) {
+		if stuck == nil || s.remaining < stuck.remaining {
+			stuck = s
+		}
+	}
+
+	unschedulableGrace := controller.Config.Pending.UnschedulableGracePeriod()
+	for _, c := range pod.Status.Conditions {
+		if c.Type != corev1.PodScheduled || c.Status != corev1.ConditionFalse || c.Reason != corev1.PodReasonUnschedulable || unschedulableGrace < 0 {
+			continue
+		}
+		update(&stuckState{
+			reason:    corev1.PodReasonUnschedulable,
+			message:   fmt.Sprintf("Pod can't be scheduled for %s: %s", unschedulableGrace, c.Message),
+			remaining: c.LastTransitionTime.Add(unschedulableGrace).Sub(now),
+		})
+	}
+
+	failing := make(map[string]bool)
+	statuses := make(map[string]*corev1.ContainerStateWaiting)
+	for _, list := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, cs := range list {
+			if cs.State.Waiting == nil {
+				continue
+			}
+			statuses[cs.Name] = cs.State.Waiting
+			if imagePullFailures[cs.State.Waiting.Reason] {
+				failing[cs.Name] = true
+			}
+			if permanentFailures[cs.State.Waiting.Reason] {
+				update(&stuckState{
+					reason:  cs.State.Waiting.Reason,
+					message: fmt.Sprintf("Container '%s' can't start with image '%s': %s", cs.Name, cs.Image, cs.State.Waiting.Message),
+				})
+			}
+		}
+	}
+
+	imagePullGrace := controller.Config.Pending.ImagePullGracePeriod()
+	for c, since := range tracker.observe(pod.UID, failing, now) {
+		if imagePullGrace < 0 {
+			continue
+		}
+		update(&stuckState{
+			reason:    statuses[c].Reason,
+			message:   fmt.Sprintf("Container '%s' keeps failing to start for %s: %s", c, imagePullGrace, statuses[c].Message),
+			remaining: since.Add(imagePullGrace).Sub(now),
+		})
+	}
+
+	return stuck
+}
@@ -0,0 +1,89 @@
+package pod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+)
+
+func waitingPod(uid, reason string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid)},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "main",
+					Image: "busybox:latset",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "pull failed"}},
+				},
+			},
+		},
+	}
+}
+
+func TestCheckPendingImagePull(t *testing.T) {
+	now := time.Now()
+	pod := waitingPod("image-pull", "ErrImagePull")
+	stuck := checkPending(pod, now)
+	assert.NotNil(t, stuck)
+	assert.Equal(t, "ErrImagePull", stuck.reason)
+	assert.Equal(t, controller.Config.Pending.ImagePullGracePeriod(), stuck.remaining)
+
+	// Grace period counts from the first time the failure is observed.
+	pod.Status.ContainerStatuses[0].State.Waiting.Reason = "ImagePullBackOff"
+	stuck = checkPending(pod, now.Add(time.Minute))
+	assert.Equal(t, "ImagePullBackOff", stuck.reason)
+	assert.Equal(t, controller.Config.Pending.ImagePullGracePeriod()-time.Minute, stuck.remaining)
+
+	stuck = checkPending(pod, now.Add(controller.Config.Pending.ImagePullGracePeriod()))
+	assert.True(t, stuck.remaining <= 0)
+	assert.Equal(t, "Container 'main' keeps failing to start for 5m0s: pull failed", stuck.message)
+
+	// Recovered containers are forgotten.
+	pod.Status.ContainerStatuses[0].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	assert.Nil(t, checkPending(pod, now.Add(controller.Config.Pending.ImagePullGracePeriod())))
+	_, ok := tracker.since[pod.UID]
+	assert.False(t, ok)
+}
+
+func TestCheckPendingPermanent(t *testing.T) {
+	stuck := checkPending(waitingPod("permanent", "InvalidImageName"), time.Now())
+	assert.NotNil(t, stuck)
+	assert.Equal(t, "InvalidImageName", stuck.reason)
+	assert.Equal(t, time.Duration(0), stuck.remaining)
+	assert.Equal(t, "Container 'main' can't start with image 'busybox:latset': pull failed", stuck.message)
+}
+
+func TestCheckPendingUnschedulable(t *testing.T) {
+	now := time.Now()
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionFalse,
+					Reason:             corev1.PodReasonUnschedulable,
+					Message:            "0/3 nodes are available: 3 Insufficient cpu.",
+					LastTransitionTime: metav1.Time{Time: now.Add(-time.Minute)},
+				},
+			},
+		},
+	}
+
+	stuck := checkPending(pod, now)
+	assert.Equal(t, corev1.PodReasonUnschedulable, stuck.reason)
+	assert.Equal(t, 9*time.Minute, stuck.remaining)
+
+	pre := controller.Config.Pending
+	defer func() {
+		controller.Config.Pending = pre
+	}()
+	controller.Config.Pending.UnschedulableGraceSeconds = -1
+	assert.Nil(t, checkPending(pod, now))
+}
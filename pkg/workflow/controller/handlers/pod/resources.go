@@ -0,0 +1,81 @@
+package pod
+
+import (
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/resolver"
+	"github.com/caicloud/cyclone/pkg/workflow/workflowrun"
+)
+
+// recordResources records results reported by resolvers of the stage in WorkflowRun status,
+// such as commit SHA of Git resources pulled and digest of images pushed, so that runs are
+// traceable. Resolvers report results in termination messages of their containers.
+func (p *Operator) recordResources(wfrOperator workflowrun.Operator) {
+	results := resolverResults(p.pod)
+	if len(results) == 0 {
+		return
+	}
+
+	stage, err := p.client.CycloneV1alpha1().Stages(p.metaNamespace).Get(p.stage, metav1.GetOptions{})
+	if err != nil {
+		log.WithField("stg", p.stage).Warning("Get stage to record resources error: ", err)
+		return
+	}
+	resources := resourceStatuses(stage, results)
+
+	if status, ok := wfrOperator.GetWorkflowRun().Status.Stages[p.stage]; ok && reflect.DeepEqual(status.Resources, resources) {
+		return
+	}
+	wfrOperator.UpdateStageResources(p.stage, resources)
+}
+
+// resolverResults gets results reported by terminated containers of the pod, keyed by container
+// names. Termination messages that are not results, such as error messages, are ignored.
+func resolverResults(pod *corev1.Pod) map[string]resolver.Result {
+	results := make(map[string]resolver.Result)
+	for _, list := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, cs := range list {
+			if cs.State.Terminated == nil || cs.State.Terminated.ExitCode != 0 {
+				continue
+			}
+			if result := resolver.ParseResult(cs.State.Terminated.Message); result != nil {
+				results[cs.Name] = result
+			}
+		}
+	}
+	return results
+}
+
+// resourceStatuses maps results of resolver containers to resources of the stage, in the order
+// of inputs and then outputs.
+func resourceStatuses(stage *v1alpha1.Stage, results map[string]resolver.Result) []v1alpha1.ResourceStatus {
+	var resources []v1alpha1.ResourceStatus
+	if stage.Spec.Pod == nil {
+		return resources
+	}
+
+	for i, r := range stage.Spec.Pod.Inputs.Resources {
+		if result, ok := results[workflowrun.InputContainerName(i+1)]; ok {
+			resources = append(resources, v1alpha1.ResourceStatus{
+				Name:      r.Name,
+				Direction: v1alpha1.ResourceDirectionInput,
+				Results:   result,
+			})
+		}
+	}
+	for i, r := range stage.Spec.Pod.Outputs.Resources {
+		if result, ok := results[workflowrun.OutputContainerName(i+1)]; ok {
+			resources = append(resources, v1alpha1.ResourceStatus{
+				Name:      r.Name,
+				Direction: v1alpha1.ResourceDirectionOutput,
+				Results:   result,
+			})
+		}
+	}
+	return resources
+}
@@ -0,0 +1,56 @@
+package pod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/resolver"
+)
+
+func TestResolverResults(t *testing.T) {
+	terminated := func(exitCode int32, message string) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Message: message}}
+	}
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "i1", State: terminated(0, `{"revision":"abc"}`)},
+				{Name: "i2", State: terminated(0, "")},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", State: terminated(1, "build error")},
+				{Name: "csc-o1", State: terminated(0, `{"digest":"sha256:abc","size":"528"}`)},
+				{Name: "csc-o2", State: terminated(1, `{"digest":"sha256:def"}`)},
+				{Name: "csc-co", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			},
+		},
+	}
+
+	results := resolverResults(pod)
+	assert.Equal(t, map[string]resolver.Result{
+		"i1":     {"revision": "abc"},
+		"csc-o1": {"digest": "sha256:abc", "size": "528"},
+	}, results)
+
+	stage := &v1alpha1.Stage{
+		Spec: v1alpha1.StageSpec{
+			Pod: &v1alpha1.PodWorkload{
+				Inputs: v1alpha1.Inputs{
+					Resources: []v1alpha1.ResourceItem{{Name: "git"}, {Name: "kv"}},
+				},
+				Outputs: v1alpha1.Outputs{
+					Resources: []v1alpha1.ResourceItem{{Name: "image"}, {Name: "other-image"}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []v1alpha1.ResourceStatus{
+		{Name: "git", Direction: v1alpha1.ResourceDirectionInput, Results: map[string]string{"revision": "abc"}},
+		{Name: "image", Direction: v1alpha1.ResourceDirectionOutput, Results: map[string]string{"digest": "sha256:abc", "size": "528"}},
+	}, resourceStatuses(stage, results))
+
+	assert.Nil(t, resourceStatuses(&v1alpha1.Stage{}, results))
+}
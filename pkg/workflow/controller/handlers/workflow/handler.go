@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/controller/handlers"
+	"github.com/caicloud/cyclone/pkg/workflow/workflowstatus"
+)
+
+// Handler handles changes of Workflow CR.
+type Handler struct {
+	Updater *workflowstatus.Updater
+}
+
+// Ensure *Handler has implemented handlers.Interface interface.
+var _ handlers.Interface = (*Handler)(nil)
+
+// ObjectCreated handles a newly created Workflow
+func (h *Handler) ObjectCreated(obj interface{}) {
+	h.update(obj)
+}
+
+// ObjectUpdated handles a updated Workflow
+func (h *Handler) ObjectUpdated(obj interface{}) {
+	h.update(obj)
+}
+
+// ObjectDeleted handles the case when a Workflow get deleted, nothing to do.
+func (h *Handler) ObjectDeleted(obj interface{}) {
+}
+
+// update updates status of the Workflow.
+func (h *Handler) update(obj interface{}) {
+	wf, ok := obj.(*v1alpha1.Workflow)
+	if !ok {
+		log.Warning("unknown resource type")
+		return
+	}
+	log.WithField("name", wf.Name).Debug("Start to process Workflow")
+
+	if err := h.Updater.Update(wf); err != nil {
+		log.WithField("wf", wf.Name).Error("Update workflow status error: ", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/workflow/controller/handlers"
+	"github.com/caicloud/cyclone/pkg/workflow/metrics"
 	"github.com/caicloud/cyclone/pkg/workflow/workflowrun"
 )
 
@@ -15,6 +16,7 @@ type Handler struct {
 	TimeoutProcessor *workflowrun.TimeoutProcessor
 	GCProcessor      *workflowrun.GCProcessor
 	LimitedQueues    *workflowrun.LimitedQueues
+	HookProcessor    *workflowrun.HookProcessor
 }
 
 // Ensure *Handler has implemented handlers.Interface interface.
@@ -33,6 +35,13 @@ func (h *Handler) ObjectCreated(obj interface{}) {
 		return
 	}
 
+	metrics.ObserveWorkflowRun(originWfr)
+
+	// Perform hooks, such as notifications and commit statuses, if phase of the WorkflowRun
+	// transitioned. It's also performed on create, since transitions may be missed when the
+	// controller is down.
+	h.HookProcessor.Add(originWfr)
+
 	// AddOrRefresh adds a WorkflowRun to its corresponding queue, if the queue size exceed the
 	// maximum size, the oldest one would be deleted. And if the WorkflowRun already exists in
 	// the queue, its 'refresh' time field would be refreshed.
@@ -77,6 +86,12 @@ func (h *Handler) ObjectUpdated(obj interface{}) {
 		return
 	}
 
+	metrics.ObserveWorkflowRun(originWfr)
+
+	// Perform hooks, such as notifications and commit statuses, if phase of the WorkflowRun
+	// transitioned.
+	h.HookProcessor.Add(originWfr)
+
 	// Refresh updates 'refresh' time field of the WorkflowRun in the queue.
 	h.LimitedQueues.Refresh(originWfr)
 
@@ -112,6 +127,13 @@ func (h *Handler) ObjectDeleted(obj interface{}) {
 		return
 	}
 	log.WithField("name", originWfr.Name).Debug("Start to GC for WorkflowRun delete")
+	metrics.ForgetWorkflowRun(originWfr)
+
+	// Hooks are performed for valid WorkflowRuns only, but GC is performed for all WorkflowRuns
+	// deleted.
+	if validate(originWfr) {
+		h.HookProcessor.Delete(originWfr)
+	}
 
 	wfr := originWfr.DeepCopy()
 	operator, err := workflowrun.NewOperator(h.Client, wfr, wfr.Namespace)
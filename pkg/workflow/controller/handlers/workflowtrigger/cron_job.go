@@ -2,16 +2,21 @@ package workflowtrigger
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/robfig/cron"
 	log "github.com/sirupsen/logrus"
 	errors2 "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/rand"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/metrics"
 )
 
 const (
@@ -21,7 +26,6 @@ const (
 
 // CronTrigger ...
 type CronTrigger struct {
-	Cron                *cron.Cron
 	IsRunning           bool
 	SuccCount           int
 	FailCount           int
@@ -29,6 +33,17 @@ type CronTrigger struct {
 	WorkflowTriggerName string
 	WorkflowRun         *v1alpha1.WorkflowRun
 	Manage              *CronTriggerManager
+	// ConcurrencyPolicy specifies how to treat concurrent WorkflowRuns
+	ConcurrencyPolicy v1alpha1.ConcurrencyPolicy
+	// StartingDeadline is the deadline to start a WorkflowRun after the scheduled time, 0 means no deadline
+	StartingDeadline time.Duration
+	// Schedule of the trigger, times it returns are in the configured time zone
+	Schedule cron.Schedule
+	// Location is the time zone the schedule is interpreted in
+	Location *time.Location
+
+	// lock serializes runs of the trigger, since the scheduler runs jobs in separate goroutines
+	lock sync.Mutex
 }
 
 // CronTriggerManager represents manager for cron triggers.
@@ -36,15 +51,21 @@ type CronTriggerManager struct {
 	Client         clientset.Interface
 	CronTriggerMap map[string]*CronTrigger
 	mutex          sync.Mutex
+	// scheduler schedules all cron triggers.
+	scheduler *Scheduler
 }
 
-// NewTriggerManager returns a cron trigger manager.
-func NewTriggerManager(client clientset.Interface) *CronTriggerManager {
-	return &CronTriggerManager{
+// NewTriggerManager returns a cron trigger manager, cron triggers are scheduled until stopCh is
+// closed.
+func NewTriggerManager(client clientset.Interface, stopCh <-chan struct{}) *CronTriggerManager {
+	m := &CronTriggerManager{
 		Client:         client,
 		CronTriggerMap: make(map[string]*CronTrigger),
 		mutex:          sync.Mutex{},
+		scheduler:      NewScheduler(),
 	}
+	go m.scheduler.Run(stopCh)
+	return m
 }
 
 // AddTrigger adds one cron trigger.
@@ -65,7 +86,7 @@ func (m *CronTriggerManager) DeleteTrigger(wftKey string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	if wft, ok := m.CronTriggerMap[wftKey]; ok {
-		wft.Cron.Stop()
+		m.scheduler.Remove(wftKey)
 		wft.IsRunning = false
 		delete(m.CronTriggerMap, wftKey)
 	} else {
@@ -88,29 +109,161 @@ func (t *CronTrigger) getKeyFromTrigger() string {
 	return fmt.Sprintf(KeyTemplate, t.Namespace, t.WorkflowTriggerName)
 }
 
-// Run triggers the workflows.
-func (t *CronTrigger) Run() {
+// run starts a WorkflowRun for the scheduled time if it's within the starting deadline and allowed
+// by the concurrency policy, last schedule time of the WorkflowTrigger is recorded anyway. Name
+// of the WorkflowRun is derived from the scheduled time, so that the same schedule won't start
+// duplicated WorkflowRuns, for example, by the old and new leader of controllers.
+func (t *CronTrigger) run(scheduled, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	logger := log.WithField("wft", t.WorkflowTriggerName).WithField("schedule", scheduled)
+	var created string
+	var runErr error
+	defer func() {
+		t.recordRun(scheduled, created, runErr)
+	}()
+
+	if t.StartingDeadline > 0 && now.Sub(scheduled) > t.StartingDeadline {
+		logger.Warn("Missed starting deadline, skip it")
+		return
+	}
+
+	name := runName(t.WorkflowTriggerName, scheduled)
+	if !t.resolveConcurrency(name, logger) {
+		return
+	}
+
 	if t.WorkflowRun.Labels == nil {
 		t.WorkflowRun.Labels = make(map[string]string)
 	}
 	t.WorkflowRun.Labels[common.WorkflowNameLabelName] = t.WorkflowRun.Spec.WorkflowRef.Name
+	t.WorkflowRun.Labels[common.WorkflowTriggerNameLabelName] = t.WorkflowTriggerName
+	t.WorkflowRun.Labels[common.TriggerTypeLabelName] = string(v1alpha1.TriggerTypeCron)
+	t.WorkflowRun.Spec.TriggeredBy = &v1alpha1.TriggeredBy{
+		Type:         v1alpha1.TriggerTypeCron,
+		Trigger:      t.WorkflowTriggerName,
+		ScheduleTime: &metav1.Time{Time: scheduled},
+	}
 
-	for {
-		t.WorkflowRun.Name = fmt.Sprintf("%s-%s", t.WorkflowTriggerName, rand.String(5))
-		_, err := t.Manage.Client.CycloneV1alpha1().WorkflowRuns(t.Namespace).Create(t.WorkflowRun)
-		if err != nil {
-			if errors2.IsAlreadyExists(err) {
-				continue
-			} else {
-				t.FailCount++
-				log.Warnf("can not create WorkflowRun: %s", err)
-				break
-			}
-		} else {
-			t.SuccCount++
+	t.WorkflowRun.Name = name
+	wfr, err := t.Manage.Client.CycloneV1alpha1().WorkflowRuns(t.Namespace).Create(t.WorkflowRun)
+	if err != nil {
+		if errors2.IsAlreadyExists(err) {
+			logger.WithField("wfr", name).Info("WorkflowRun of the schedule already created, skip it")
+			return
+		}
+		t.FailCount++
+		runErr = fmt.Errorf("create WorkflowRun error: %v", err)
+		metrics.IncCronTrigger(t.Namespace, t.WorkflowTriggerName, false)
+		log.Warnf("can not create WorkflowRun: %s", err)
+		return
+	}
+	t.SuccCount++
+	created = wfr.Name
+	metrics.IncCronTrigger(t.Namespace, t.WorkflowTriggerName, true)
+}
+
+// runName gets name of the WorkflowRun started by the trigger at the scheduled time.
+func runName(trigger string, scheduled time.Time) string {
+	return fmt.Sprintf("%s-%d", trigger, scheduled.Unix())
+}
+
+// resolveConcurrency checks WorkflowRuns started by the trigger that haven't terminated, and
+// applies the concurrency policy. It returns whether a new WorkflowRun can be started. The
+// WorkflowRun with the given name, which is of the current schedule, is ignored.
+func (t *CronTrigger) resolveConcurrency(name string, logger *log.Entry) bool {
+	switch t.ConcurrencyPolicy {
+	case v1alpha1.ForbidConcurrent, v1alpha1.ReplaceConcurrent:
+	case "", v1alpha1.AllowConcurrent:
+		return true
+	default:
+		logger.WithField("policy", t.ConcurrencyPolicy).Warn("Unknown concurrency policy, allow concurrent runs")
+		return true
+	}
+
+	wfrs, err := t.Manage.Client.CycloneV1alpha1().WorkflowRuns(t.Namespace).List(metav1.ListOptions{
+		LabelSelector: common.WorkflowTriggerNameLabelName + "=" + t.WorkflowTriggerName,
+	})
+	if err != nil {
+		logger.Warn("List WorkflowRuns error, start new one anyway: ", err)
+		return true
+	}
+
+	var active []string
+	for _, wfr := range wfrs.Items {
+		if wfr.Name == name {
+			continue
+		}
+		switch wfr.Status.Overall.Status {
+		case v1alpha1.StatusCompleted, v1alpha1.StatusError, v1alpha1.StatusCancelled:
+		default:
+			active = append(active, wfr.Name)
+		}
+	}
+	if len(active) == 0 {
+		return true
+	}
+
+	if t.ConcurrencyPolicy == v1alpha1.ForbidConcurrent {
+		logger.WithField("active", active).Info("Previous WorkflowRuns still running, skip it")
+		return false
+	}
+
+	for _, name := range active {
+		err := t.Manage.Client.CycloneV1alpha1().WorkflowRuns(t.Namespace).Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !errors2.IsNotFound(err) {
+			logger.WithField("wfr", name).Warn("Delete running WorkflowRun to replace error: ", err)
+			continue
+		}
+		logger.WithField("wfr", name).Info("Running WorkflowRun deleted to be replaced")
+	}
+	return true
+}
+
+// recordRun records result of the scheduled run in status of the WorkflowTrigger. Last schedule
+// time is recorded even if no WorkflowRun created, so that missed schedules can be found when
+// the controller restarts.
+func (t *CronTrigger) recordRun(scheduled time.Time, created string, runErr error) {
+	now := time.Now()
+	err := t.Manage.updateStatus(t.Namespace, t.WorkflowTriggerName, func(status *v1alpha1.WorkflowTriggerStatus) {
+		if status.LastScheduleTime == nil || status.LastScheduleTime.Time.Before(scheduled) {
+			status.LastScheduleTime = &metav1.Time{Time: scheduled}
+		}
+		if created != "" {
+			status.Count++
+			status.LastFireTime = &metav1.Time{Time: now}
+			status.LastWorkflowRun = created
+			status.LastError = ""
+		}
+		if runErr != nil {
+			status.LastError = runErr.Error()
+		}
+		if t.Schedule != nil {
+			status.NextScheduleTime = &metav1.Time{Time: t.Schedule.Next(now.In(t.Location))}
+		}
+	})
+	if err != nil {
+		log.WithField("wft", t.WorkflowTriggerName).Warn("Record run in status error: ", err)
+	}
+}
+
+// maxMissedSchedules is the maximum number of missed schedules to check, it avoids spending too
+// much time when the schedule is frequent and the controller has been down for a long time.
+const maxMissedSchedules = 100
+
+// missedSchedule finds the latest schedule time missed since the last schedule time, zero time is
+// returned if no schedule missed.
+func missedSchedule(schedule cron.Schedule, last, now time.Time) time.Time {
+	var missed time.Time
+	for i, t := 0, schedule.Next(last); !t.After(now); i, t = i+1, schedule.Next(t) {
+		if i >= maxMissedSchedules {
+			log.WithField("last", last).Warn("Too many missed schedules, stop checking the rest")
 			break
 		}
+		missed = t
 	}
+	return missed
 }
 
 func getParamValue(items []v1alpha1.ParameterItem, key string) (string, bool) {
@@ -122,11 +275,41 @@ func getParamValue(items []v1alpha1.ParameterItem, key string) (string, bool) {
 	return "", false
 }
 
+// updateStatus updates status of the WorkflowTrigger with the update function, it's skipped if
+// nothing changed.
+func (m *CronTriggerManager) updateStatus(namespace, name string, update func(status *v1alpha1.WorkflowTriggerStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		wft, err := m.Client.CycloneV1alpha1().WorkflowTriggers(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		status := wft.Status.DeepCopy()
+		update(status)
+		if reflect.DeepEqual(status, &wft.Status) {
+			return nil
+		}
+
+		wft.Status = *status
+		_, err = m.Client.CycloneV1alpha1().WorkflowTriggers(namespace).Update(wft)
+		return err
+	})
+}
+
 // CreateCron creates a cron trigger from workflow trigger, and add it to cron trigger manager.
 func (m *CronTriggerManager) CreateCron(wft *v1alpha1.WorkflowTrigger) {
+	switch wft.Spec.Type {
+	case v1alpha1.TriggerTypeWebhook, v1alpha1.TriggerTypeWorkflowRun, v1alpha1.TriggerTypeImage, v1alpha1.TriggerTypeSCMPoll:
+		return
+	}
+
 	ct := &CronTrigger{
 		Namespace:           wft.Namespace,
 		WorkflowTriggerName: wft.Name,
+		ConcurrencyPolicy:   wft.Spec.Cron.ConcurrencyPolicy,
+	}
+	if wft.Spec.Cron.StartingDeadlineSeconds != nil {
+		ct.StartingDeadline = time.Duration(*wft.Spec.Cron.StartingDeadlineSeconds) * time.Second
 	}
 
 	wfr := &v1alpha1.WorkflowRun{
@@ -135,19 +318,71 @@ func (m *CronTriggerManager) CreateCron(wft *v1alpha1.WorkflowTrigger) {
 
 	ct.WorkflowRun = wfr
 
-	c := cron.New()
-	if err := c.AddJob(wft.Spec.Cron.Schedule, ct); err != nil {
-		log.Errorf("can not create Cron job: %s", err)
+	ct.Location = time.Local
+	if wft.Spec.Cron.TimeZone != "" {
+		location, err := time.LoadLocation(wft.Spec.Cron.TimeZone)
+		if err != nil {
+			m.recordError(wft, fmt.Errorf("invalid time zone '%s': %v", wft.Spec.Cron.TimeZone, err))
+			return
+		}
+		ct.Location = location
+	}
+
+	schedule, err := cron.Parse(wft.Spec.Cron.Schedule)
+	if err != nil {
+		m.recordError(wft, fmt.Errorf("invalid schedule '%s': %v", wft.Spec.Cron.Schedule, err))
 		return
 	}
+	ct.Schedule = schedule
 
-	ct.Cron = c
 	ct.Manage = m
 	m.AddTrigger(ct)
 
-	if !wft.Spec.Disabled {
-		ct.Cron.Start()
+	if wft.Spec.Disabled {
+		err = m.updateStatus(wft.Namespace, wft.Name, func(status *v1alpha1.WorkflowTriggerStatus) {
+			status.NextScheduleTime = nil
+		})
+	} else {
+		// Catch up the latest missed schedule, for example, when the controller was down. Like
+		// CronJob in Kubernetes, only one WorkflowRun is started for all missed schedules.
+		now := time.Now()
+		if wft.Status.LastScheduleTime != nil {
+			if missed := missedSchedule(schedule, wft.Status.LastScheduleTime.Time.In(ct.Location), now.In(ct.Location)); !missed.IsZero() {
+				log.WithField("wft", wft.Name).WithField("schedule", missed).Info("Catch up missed schedule")
+				ct.run(missed, now)
+			}
+		}
+
+		m.scheduler.Add(getKeyFromWorkflowTrigger(wft), schedule, ct.Location, func(scheduled time.Time) {
+			ct.run(scheduled, time.Now())
+		})
 		ct.IsRunning = true
+
+		err = m.updateStatus(wft.Namespace, wft.Name, func(status *v1alpha1.WorkflowTriggerStatus) {
+			status.NextScheduleTime = &metav1.Time{Time: schedule.Next(now.In(ct.Location))}
+			if strings.HasPrefix(status.LastError, invalidSpecPrefix) {
+				status.LastError = ""
+			}
+		})
+	}
+	if err != nil {
+		log.WithField("wft", wft.Name).Warn("Update next schedule time error: ", err)
+	}
+}
+
+// invalidSpecPrefix is prefix of errors about invalid spec of the cron trigger, they are cleared
+// once the spec is fixed.
+const invalidSpecPrefix = "invalid "
+
+// recordError records error of the cron trigger in its status.
+func (m *CronTriggerManager) recordError(wft *v1alpha1.WorkflowTrigger, cronErr error) {
+	log.WithField("wft", wft.Name).Error("Create cron trigger error: ", cronErr)
+	err := m.updateStatus(wft.Namespace, wft.Name, func(status *v1alpha1.WorkflowTriggerStatus) {
+		status.LastError = cronErr.Error()
+		status.NextScheduleTime = nil
+	})
+	if err != nil {
+		log.WithField("wft", wft.Name).Warn("Record error in status error: ", err)
 	}
 }
 
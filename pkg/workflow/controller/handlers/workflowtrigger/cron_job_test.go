@@ -0,0 +1,190 @@
+package workflowtrigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+func activeRun(name, status string) *v1alpha1.WorkflowRun {
+	return &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{common.WorkflowTriggerNameLabelName: "nightly"},
+		},
+		Status: v1alpha1.WorkflowRunStatus{Overall: v1alpha1.Status{Status: status}},
+	}
+}
+
+func TestRun(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	deadline := int64(60)
+	now := time.Now()
+	cases := map[string]struct {
+		policy    v1alpha1.ConcurrencyPolicy
+		scheduled time.Time
+		created   bool
+		remaining int
+	}{
+		"allow": {
+			policy:    v1alpha1.AllowConcurrent,
+			scheduled: now,
+			created:   true,
+			remaining: 3,
+		},
+		"forbid": {
+			policy:    v1alpha1.ForbidConcurrent,
+			scheduled: now,
+			created:   false,
+			remaining: 2,
+		},
+		"replace": {
+			policy:    v1alpha1.ReplaceConcurrent,
+			scheduled: now,
+			created:   true,
+			remaining: 2,
+		},
+		"missed deadline": {
+			policy:    v1alpha1.AllowConcurrent,
+			scheduled: now.Add(-time.Hour),
+			created:   false,
+			remaining: 2,
+		},
+	}
+
+	for name, c := range cases {
+		client := fake.NewSimpleClientset(
+			&v1alpha1.WorkflowTrigger{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"}},
+			activeRun("nightly-1", v1alpha1.StatusCompleted),
+			activeRun("nightly-2", v1alpha1.StatusRunning),
+		)
+		m := NewTriggerManager(client, stopCh)
+		m.CreateCron(&v1alpha1.WorkflowTrigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+			Spec: v1alpha1.WorkflowTriggerSpec{
+				Cron:            v1alpha1.CronTrigger{Schedule: "0 0 2 * * *", ConcurrencyPolicy: c.policy, StartingDeadlineSeconds: &deadline},
+				Disabled:        true,
+				WorkflowRunSpec: v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: "wf"}},
+			},
+		})
+		ct := m.CronTriggerMap["default/nightly"]
+		ct.run(c.scheduled, now)
+
+		wfrs, _ := client.CycloneV1alpha1().WorkflowRuns("default").List(metav1.ListOptions{})
+		assert.Equal(t, c.remaining, len(wfrs.Items), name)
+		assert.Equal(t, c.created, ct.SuccCount == 1, name)
+		if c.policy == v1alpha1.ReplaceConcurrent {
+			_, err := client.CycloneV1alpha1().WorkflowRuns("default").Get("nightly-2", metav1.GetOptions{})
+			assert.NotNil(t, err, name)
+		}
+		for _, wfr := range wfrs.Items {
+			if wfr.Spec.TriggeredBy != nil {
+				assert.Equal(t, c.scheduled.Unix(), wfr.Spec.TriggeredBy.ScheduleTime.Unix(), name)
+			}
+		}
+
+		wft, _ := client.CycloneV1alpha1().WorkflowTriggers("default").Get("nightly", metav1.GetOptions{})
+		assert.Equal(t, c.scheduled.Unix(), wft.Status.LastScheduleTime.Unix(), name)
+		assert.NotNil(t, wft.Status.NextScheduleTime, name)
+		if c.created {
+			assert.Equal(t, 1, wft.Status.Count, name)
+			assert.NotEqual(t, "", wft.Status.LastWorkflowRun, name)
+			assert.NotNil(t, wft.Status.LastFireTime, name)
+		} else {
+			assert.Equal(t, 0, wft.Status.Count, name)
+		}
+	}
+}
+
+func TestRunDuplicated(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	client := fake.NewSimpleClientset(
+		&v1alpha1.WorkflowTrigger{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"}},
+	)
+	m := NewTriggerManager(client, stopCh)
+	m.CreateCron(&v1alpha1.WorkflowTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+		Spec: v1alpha1.WorkflowTriggerSpec{
+			Cron:            v1alpha1.CronTrigger{Schedule: "0 0 2 * * *", ConcurrencyPolicy: v1alpha1.ReplaceConcurrent},
+			Disabled:        true,
+			WorkflowRunSpec: v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: "wf"}},
+		},
+	})
+	ct := m.CronTriggerMap["default/nightly"]
+
+	// Runs of the same schedule, for example, by another controller, start only one WorkflowRun,
+	// and it's not replaced.
+	scheduled := time.Date(2019, 1, 1, 2, 0, 0, 0, time.Local)
+	ct.run(scheduled, scheduled)
+	ct.run(scheduled, scheduled)
+	wfrs, _ := client.CycloneV1alpha1().WorkflowRuns("default").List(metav1.ListOptions{})
+	assert.Equal(t, 1, len(wfrs.Items))
+	assert.Equal(t, runName("nightly", scheduled), wfrs.Items[0].Name)
+	assert.Equal(t, 1, ct.SuccCount)
+	assert.Equal(t, 0, ct.FailCount)
+
+	wft, _ := client.CycloneV1alpha1().WorkflowTriggers("default").Get("nightly", metav1.GetOptions{})
+	assert.Equal(t, 1, wft.Status.Count)
+	assert.Equal(t, "", wft.Status.LastError)
+
+	ct.run(scheduled.Add(24*time.Hour), scheduled.Add(24*time.Hour))
+	wfrs, _ = client.CycloneV1alpha1().WorkflowRuns("default").List(metav1.ListOptions{})
+	assert.Equal(t, 1, len(wfrs.Items))
+	assert.Equal(t, runName("nightly", scheduled.Add(24*time.Hour)), wfrs.Items[0].Name)
+}
+
+func TestCreateCronTimeZone(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	wft := &v1alpha1.WorkflowTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+		Spec: v1alpha1.WorkflowTriggerSpec{
+			Cron:            v1alpha1.CronTrigger{Schedule: "0 0 2 * * *", TimeZone: "Mars/Olympus"},
+			WorkflowRunSpec: v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: "wf"}},
+		},
+	}
+	client := fake.NewSimpleClientset(wft)
+	m := NewTriggerManager(client, stopCh)
+
+	m.CreateCron(wft)
+	assert.Equal(t, 0, len(m.CronTriggerMap))
+	wft, _ = client.CycloneV1alpha1().WorkflowTriggers("default").Get("nightly", metav1.GetOptions{})
+	assert.Contains(t, wft.Status.LastError, "invalid time zone")
+
+	wft.Spec.Cron.TimeZone = "Asia/Shanghai"
+	m.UpdateCron(wft)
+	defer m.DeleteCron(wft)
+	wft, _ = client.CycloneV1alpha1().WorkflowTriggers("default").Get("nightly", metav1.GetOptions{})
+	assert.Equal(t, "", wft.Status.LastError)
+	location, _ := time.LoadLocation("Asia/Shanghai")
+	next := wft.Status.NextScheduleTime.In(location)
+	assert.Equal(t, 2, next.Hour())
+	assert.Equal(t, 0, next.Minute())
+}
+
+func TestMissedSchedule(t *testing.T) {
+	schedule, err := cron.Parse("0 0 2 * * *")
+	assert.Nil(t, err)
+
+	last := time.Date(2019, 1, 1, 2, 0, 0, 0, time.Local)
+	assert.True(t, missedSchedule(schedule, last, last.Add(time.Hour)).IsZero())
+	assert.Equal(t, last.Add(48*time.Hour), missedSchedule(schedule, last, last.Add(50*time.Hour)))
+
+	schedule, err = cron.Parse("@every 1s")
+	assert.Nil(t, err)
+	assert.Equal(t, last.Add(maxMissedSchedules*time.Second), missedSchedule(schedule, last, last.Add(time.Hour)))
+}
@@ -0,0 +1,113 @@
+package workflowtrigger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// idleInterval is the interval to check entries when there is nothing scheduled.
+const idleInterval = time.Hour
+
+// Scheduler runs jobs of all cron triggers on their schedules in a single goroutine, instead
+// of one cron goroutine per trigger.
+type Scheduler struct {
+	lock    sync.Mutex
+	entries map[string]*entry
+	// wake wakes up the scheduler when entries are changed.
+	wake chan struct{}
+	// now returns current time, it's replaced in tests.
+	now func() time.Time
+}
+
+// entry is a job scheduled in the scheduler.
+type entry struct {
+	schedule cron.Schedule
+	location *time.Location
+	job      func(scheduled time.Time)
+	// next is the next time to run the job, zero if the schedule will never be satisfied.
+	next time.Time
+}
+
+// NewScheduler creates a scheduler, it should be started by Run.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		entries: make(map[string]*entry),
+		wake:    make(chan struct{}, 1),
+		now:     time.Now,
+	}
+}
+
+// Add schedules the job with the given key, the former one with the same key is replaced. Times
+// of the schedule are interpreted in the location, and the job is called with the time it's
+// scheduled at in a separate goroutine.
+func (s *Scheduler) Add(key string, schedule cron.Schedule, location *time.Location, job func(scheduled time.Time)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.entries[key] = &entry{
+		schedule: schedule,
+		location: location,
+		job:      job,
+		next:     schedule.Next(s.now().In(location)),
+	}
+	s.notify()
+}
+
+// Remove removes the job with the given key.
+func (s *Scheduler) Remove(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.entries, key)
+	s.notify()
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs due jobs until stopCh is closed.
+func (s *Scheduler) Run(stopCh <-chan struct{}) {
+	for {
+		timer := time.NewTimer(s.runDue())
+		select {
+		case <-stopCh:
+			timer.Stop()
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// runDue starts jobs that are due and schedules their next runs. It returns the duration to
+// wait until the earliest next run.
+func (s *Scheduler) runDue() time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	wait := idleInterval
+	for _, e := range s.entries {
+		if e.next.IsZero() {
+			continue
+		}
+		if !e.next.After(now) {
+			go e.job(e.next)
+			e.next = e.schedule.Next(now.In(e.location))
+			if e.next.IsZero() {
+				continue
+			}
+		}
+		if d := e.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	return wait
+}
@@ -0,0 +1,59 @@
+package workflowtrigger
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// interval is a schedule that runs at fixed intervals, cron.Every doesn't support intervals
+// less than one second.
+type interval time.Duration
+
+func (i interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+func TestScheduler(t *testing.T) {
+	s := NewScheduler()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go s.Run(stopCh)
+
+	var lock sync.Mutex
+	runs := make(map[string]int)
+	job := func(key string) func(time.Time) {
+		return func(scheduled time.Time) {
+			lock.Lock()
+			defer lock.Unlock()
+			runs[key]++
+		}
+	}
+	count := func(key string) int {
+		lock.Lock()
+		defer lock.Unlock()
+		return runs[key]
+	}
+
+	s.Add("ns/a", interval(20*time.Millisecond), time.Local, job("a"))
+	s.Add("ns/b", interval(time.Hour), time.Local, job("b"))
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, count("a") > 1)
+	assert.Equal(t, 0, count("b"))
+
+	s.Remove("ns/a")
+	time.Sleep(50 * time.Millisecond)
+	removed := count("a")
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, removed, count("a"))
+
+	// Schedule that will never be satisfied.
+	s.Add("ns/c", interval(0), time.Local, job("c"))
+	s.lock.Lock()
+	s.entries["ns/c"].next = time.Time{}
+	s.lock.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, count("c"))
+}
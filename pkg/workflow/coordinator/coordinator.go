@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	core_v1 "k8s.io/api/core/v1"
@@ -28,6 +30,8 @@ type Coordinator struct {
 	Wfr *v1alpha1.WorkflowRun
 	// OutputResources represents output resources the related stage configured.
 	OutputResources []*v1alpha1.Resource
+	// logsCollecting tracks goroutines collecting container logs.
+	logsCollecting sync.WaitGroup
 }
 
 // RuntimeExecutor is an interface defined some methods
@@ -36,7 +40,7 @@ type RuntimeExecutor interface {
 	// WaitContainers waits selected containers to state.
 	WaitContainers(state common.ContainerState, selectors ...common.ContainerSelector) error
 	// CollectLog collects container logs to cyclone server.
-	CollectLog(container, wrorkflowrun, stage string) error
+	CollectLog(container string, wfr *v1alpha1.WorkflowRun, stage string) error
 	// CopyFromContainer copy a file or directory from container:path to dst.
 	CopyFromContainer(container, path, dst string) error
 	// GetPod get the stage related pod.
@@ -91,6 +95,8 @@ func NewCoordinator(client clientset.Interface) (*Coordinator, error) {
 }
 
 // CollectLogs collects all containers' logs except for the coordinator container itself.
+// Init containers, such as input resource resolvers, have terminated before coordinator
+// starts, their logs are collected retroactively.
 func (co *Coordinator) CollectLogs() {
 	cs, err := co.getAllOtherContainers()
 	if err != nil {
@@ -98,16 +104,35 @@ func (co *Coordinator) CollectLogs() {
 	}
 
 	for _, c := range cs {
-		go func(container, workflowrun, stage string) {
-			err := co.runtimeExec.CollectLog(container, workflowrun, stage)
+		co.logsCollecting.Add(1)
+		go func(container string, wfr *v1alpha1.WorkflowRun, stage string) {
+			defer co.logsCollecting.Done()
+			err := co.runtimeExec.CollectLog(container, wfr, stage)
 			if err != nil {
 				log.Errorf("Collect %s log failed:%v", container, err)
 			}
-		}(c, co.Wfr.Name, co.Stage.Name)
+		}(c, co.Wfr, co.Stage.Name)
 	}
 
 }
 
+// WaitLogsCollected waits logs of all containers to be pushed completely, so that logs won't
+// be cut off when coordinator exits. It returns false if timeout.
+func (co *Coordinator) WaitLogsCollected(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		co.logsCollecting.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
 // WaitRunning waits all containers to start run.
 func (co *Coordinator) WaitRunning() {
 	err := co.runtimeExec.WaitContainers(common.ContainerStateInitialized)
@@ -314,6 +339,9 @@ func (co *Coordinator) getAllOtherContainers() ([]string, error) {
 		return cs, err
 	}
 
+	for _, c := range pod.Spec.InitContainers {
+		cs = append(cs, c.Name)
+	}
 	for _, c := range pod.Spec.Containers {
 		if c.Name != common.CoordinatorSidecarName {
 			cs = append(cs, c.Name)
@@ -1,31 +1,48 @@
 package cycloneserver
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/server/types"
+	httputil "github.com/caicloud/cyclone/pkg/util/http"
 	websocketutil "github.com/caicloud/cyclone/pkg/util/websocket"
 )
 
 const (
 	cycloneAPIVersion = "/apis/v1alpha1"
 
-	apiPathForLogStream = "/workflowruns/%s/stages/%s/streamlogs"
+	apiPathForWorkflowRun = "/projects/%s/workflows/%s/workflowruns/%s"
+	apiPathForLogStream   = apiPathForWorkflowRun + "/streamlogs"
+	apiPathForLogsStatus  = apiPathForWorkflowRun + "/logs/status"
+	apiPathForSummaries   = "/projects/%s/workflows/%s/summaries"
 )
 
 // Client ...
 type Client interface {
-	PushLogStream(workflowrun, stage, container string, reader io.Reader, close chan struct{}) error
+	// PushLogStream pushes log records of a container in the stage of workflowrun until records is closed.
+	PushLogStream(wfr *v1alpha1.WorkflowRun, stage, container string, records <-chan *api.LogRecord) error
+	// ListLogsStatus lists status of logs of the stage in workflowrun.
+	ListLogsStatus(wfr *v1alpha1.WorkflowRun, stage string) ([]api.LogStatus, error)
+	// CreateWorkflowRunSummary archives summary of the workflowrun before it's pruned.
+	CreateWorkflowRunSummary(wfr *v1alpha1.WorkflowRun, summary *api.WorkflowRunSummary) error
+	// ListWorkflowRunSummaries lists archived summaries of workflowruns of the workflow, newest first.
+	// Only summaries in the given status are listed if status is not empty, at most limit ones are listed.
+	ListWorkflowRunSummaries(namespace, project, workflow, status string, limit int) ([]api.WorkflowRunSummary, error)
 }
 
 type client struct {
@@ -46,8 +63,8 @@ func NewClient(cycloneServer string) Client {
 	}
 }
 
-// do sends the request to Cyclone and returns an HTTP response.
-func (c *client) do(method, relativePath string, bodyObject interface{}) (*http.Response, error) {
+// do sends the request to Cyclone on behalf of the tenant and returns an HTTP response.
+func (c *client) do(method, relativePath, tenant string, bodyObject interface{}) (*http.Response, error) {
 	url := c.baseURL + cycloneAPIVersion + relativePath
 	log.Infof("Request for Cyclone server: %s %s", method, url)
 
@@ -66,25 +83,40 @@ func (c *client) do(method, relativePath string, bodyObject interface{}) (*http.
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(httputil.TenantHeaderName, tenant)
 	resp, err := c.client.Do(req)
 	if err != nil {
-		log.Errorf(err.Error())
+		log.Error(err)
 		return nil, err
 	}
 
 	return resp, nil
 }
 
-// PushLogStream ...
-func (c *client) PushLogStream(workflowrun, stage, container string, reader io.Reader, close chan struct{}) error {
-	path := fmt.Sprintf(apiPathForLogStream, workflowrun, stage)
+// PushLogStream pushes log records of a container to Cyclone server until records is closed.
+// Records are always drained even if pushing fails, so that producers won't be blocked.
+func (c *client) PushLogStream(wfr *v1alpha1.WorkflowRun, stage, container string, records <-chan *api.LogRecord) error {
+	defer func() {
+		for range records {
+		}
+	}()
+
+	path := workflowRunPath(apiPathForLogStream, wfr)
+
+	scheme := "ws"
+	if strings.HasPrefix(c.baseURL, "https://") {
+		scheme = "wss"
+	}
 	host := strings.TrimPrefix(c.baseURL, "http://")
 	host = strings.TrimPrefix(host, "https://")
+	query := url.Values{}
+	query.Set("stage", stage)
+	query.Set("container", container)
 	requestURL := url.URL{
 		Host:     host,
 		Path:     cycloneAPIVersion + path,
-		RawQuery: fmt.Sprintf("container=%s", container),
-		Scheme:   "ws",
+		RawQuery: query.Encode(),
+		Scheme:   scheme,
 	}
 
 	log.Infof("Path: %s", requestURL.String())
@@ -97,6 +129,7 @@ func (c *client) PushLogStream(workflowrun, stage, container string, reader io.R
 		"Sec-Websocket-Version": []string{"13"},
 	}
 	filteredHeader := websocketutil.FilterHeader(header)
+	filteredHeader.Set(httputil.TenantHeaderName, common.NamespaceTenant(wfr.Namespace))
 
 	ws, _, err := websocket.DefaultDialer.Dial(requestURL.String(), filteredHeader)
 	if err != nil {
@@ -105,35 +138,106 @@ func (c *client) PushLogStream(workflowrun, stage, container string, reader io.R
 	}
 	defer ws.Close()
 
-	return watchLogs(ws, reader, close)
+	return pushRecords(ws, records)
+}
+
+// ListLogsStatus lists status of logs of the stage in workflowrun.
+func (c *client) ListLogsStatus(wfr *v1alpha1.WorkflowRun, stage string) ([]api.LogStatus, error) {
+	path := workflowRunPath(apiPathForLogsStatus, wfr) + "?" + url.Values{"stage": []string{stage}}.Encode()
+	resp, err := c.do(http.MethodGet, path, common.NamespaceTenant(wfr.Namespace), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("list logs status error, status code %d: %s", resp.StatusCode, body)
+	}
+
+	var status []api.LogStatus
+	list := &types.ListResponse{Items: &status}
+	if err := json.NewDecoder(resp.Body).Decode(list); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// CreateWorkflowRunSummary archives summary of the workflowrun to Cyclone server.
+func (c *client) CreateWorkflowRunSummary(wfr *v1alpha1.WorkflowRun, summary *api.WorkflowRunSummary) error {
+	path := fmt.Sprintf(apiPathForSummaries, wfr.Labels[common.LabelProjectName], workflowName(wfr))
+	resp, err := c.do(http.MethodPost, path, common.NamespaceTenant(wfr.Namespace), summary)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("create workflowrun summary error, status code %d: %s", resp.StatusCode, body)
+	}
+	return nil
 }
 
-func watchLogs(ws *websocket.Conn, reader io.Reader, close chan struct{}) error {
-	//logFile, err := os.Open(filePath)
-	//if err != nil {
-	//	log.Error(err.Error())
-	//	return err
-	//}
-	//defer logFile.Close()
-
-	buf := bufio.NewReader(reader)
-	ticker := time.NewTicker(10 * time.Millisecond)
-	for {
-		select {
-		case <-ticker.C:
-			line, errRead := buf.ReadBytes('\n')
-			if errRead != nil {
-				if errRead == io.EOF {
-					continue
-				}
-				log.Errorf("watch log file errs: %v", errRead)
-				ws.WriteMessage(websocket.CloseMessage, []byte(errRead.Error()))
-				return errRead
-			}
-			ws.WriteMessage(websocket.TextMessage, line)
-		case <-close:
-			log.Info("Close the watch of log file")
-			return nil
+// ListWorkflowRunSummaries lists archived summaries of workflowruns of the workflow from Cyclone server.
+func (c *client) ListWorkflowRunSummaries(namespace, project, workflow, status string, limit int) ([]api.WorkflowRunSummary, error) {
+	query := url.Values{}
+	query.Set("start", "0")
+	query.Set("limit", strconv.Itoa(limit))
+	if status != "" {
+		query.Set(httputil.StatusQueryParameter, status)
+	}
+	path := fmt.Sprintf(apiPathForSummaries, project, workflow) + "?" + query.Encode()
+	resp, err := c.do(http.MethodGet, path, common.NamespaceTenant(namespace), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("list workflowrun summaries error, status code %d: %s", resp.StatusCode, body)
+	}
+
+	var summaries []api.WorkflowRunSummary
+	list := &types.ListResponse{Items: &summaries}
+	if err := json.NewDecoder(resp.Body).Decode(list); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// workflowRunPath formats the API path of the workflowrun.
+func workflowRunPath(format string, wfr *v1alpha1.WorkflowRun) string {
+	return fmt.Sprintf(format, wfr.Labels[common.LabelProjectName], workflowName(wfr), wfr.Name)
+}
+
+// workflowName gets name of the Workflow of the workflowrun.
+func workflowName(wfr *v1alpha1.WorkflowRun) string {
+	if wfr.Spec.WorkflowRef != nil && wfr.Spec.WorkflowRef.Name != "" {
+		return wfr.Spec.WorkflowRef.Name
+	}
+	return wfr.Labels[common.LabelWorkflowName]
+}
+
+// pushRecords sends records as JSON messages, and closes the connection normally once all
+// records are sent, so that Cyclone server knows the log is complete.
+func pushRecords(ws *websocket.Conn, records <-chan *api.LogRecord) error {
+	for record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			log.Warningf("Marshal log record error: %v", err)
+			continue
+		}
+
+		ws.SetWriteDeadline(time.Now().Add(websocketutil.WriteWait))
+		if err := ws.WriteMessage(websocket.TextMessage, data); err != nil {
+			log.Errorf("Push log record error: %v", err)
+			return err
 		}
 	}
+
+	log.Info("Close the log stream")
+	ws.SetWriteDeadline(time.Now().Add(websocketutil.WriteWait))
+	return ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 }
@@ -0,0 +1,141 @@
+package cycloneserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	websocketutil "github.com/caicloud/cyclone/pkg/util/websocket"
+)
+
+func TestPushLogStream(t *testing.T) {
+	var path, query, tenant string
+	var received []api.LogRecord
+	var closeErr error
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		path, query, tenant = r.URL.Path, r.URL.RawQuery, r.Header.Get("X-Tenant")
+		ws, err := websocketutil.Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			_, message, err := ws.ReadMessage()
+			if err != nil {
+				closeErr = err
+				return
+			}
+			record := api.LogRecord{}
+			json.Unmarshal(message, &record)
+			received = append(received, record)
+		}
+	}))
+	defer server.Close()
+
+	wfr := &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wfr",
+			Namespace: "cyclone--t1",
+			Labels:    map[string]string{"cyclone.io/project-name": "p1"},
+		},
+		Spec: v1alpha1.WorkflowRunSpec{
+			WorkflowRef: &corev1.ObjectReference{Name: "wf1"},
+		},
+	}
+
+	records := make(chan *api.LogRecord, 2)
+	now := time.Now()
+	records <- &api.LogRecord{Time: now, Container: "main", Stream: api.LogStreamStdout, Line: "l1"}
+	records <- &api.LogRecord{Time: now, Container: "main", Stream: api.LogStreamStderr, Line: "l2"}
+	close(records)
+
+	err := NewClient(server.URL).PushLogStream(wfr, "build", "main", records)
+	assert.Nil(t, err)
+
+	// Wait for the server to handle the close message.
+	<-done
+	assert.Equal(t, "/apis/v1alpha1/projects/p1/workflows/wf1/workflowruns/wfr/streamlogs", path)
+	assert.Equal(t, "container=main&stage=build", query)
+	assert.Equal(t, "t1", tenant)
+	assert.Equal(t, 2, len(received))
+	assert.Equal(t, api.LogStreamStderr, received[1].Stream)
+	assert.Equal(t, "l2", received[1].Line)
+	assert.True(t, websocket.IsCloseError(closeErr, websocket.CloseNormalClosure))
+}
+
+func TestListLogsStatus(t *testing.T) {
+	var path, query, tenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query, tenant = r.URL.Path, r.URL.RawQuery, r.Header.Get("X-Tenant")
+		w.Write([]byte(`{"metadata":{"total":1},"items":[{"stage":"build","container":"main","state":"Incomplete","size":10}]}`))
+	}))
+	defer server.Close()
+
+	wfr := &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wfr",
+			Namespace: "cyclone--t1",
+			Labels:    map[string]string{"cyclone.io/project-name": "p1", "cyclone.io/workflow-name": "wf1"},
+		},
+	}
+
+	status, err := NewClient(server.URL).ListLogsStatus(wfr, "build")
+	assert.Nil(t, err)
+	assert.Equal(t, "/apis/v1alpha1/projects/p1/workflows/wf1/workflowruns/wfr/logs/status", path)
+	assert.Equal(t, "stage=build", query)
+	assert.Equal(t, "t1", tenant)
+	assert.Equal(t, []api.LogStatus{{Stage: "build", Container: "main", State: api.LogStateIncomplete, Size: 10}}, status)
+}
+
+func TestCreateWorkflowRunSummary(t *testing.T) {
+	var method, path, tenant string
+	received := api.WorkflowRunSummary{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, tenant = r.Method, r.URL.Path, r.Header.Get("X-Tenant")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	wfr := &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wfr",
+			Namespace: "cyclone--t1",
+			Labels:    map[string]string{"cyclone.io/project-name": "p1", "cyclone.io/workflow-name": "wf1"},
+		},
+	}
+
+	err := NewClient(server.URL).CreateWorkflowRunSummary(wfr, &api.WorkflowRunSummary{Name: "wfr"})
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "/apis/v1alpha1/projects/p1/workflows/wf1/summaries", path)
+	assert.Equal(t, "t1", tenant)
+	assert.Equal(t, "wfr", received.Name)
+}
+
+func TestListWorkflowRunSummaries(t *testing.T) {
+	var path, query, tenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query, tenant = r.URL.Path, r.URL.RawQuery, r.Header.Get("X-Tenant")
+		w.Write([]byte(`{"metadata":{"total":3},"items":[{"name":"wfr3","workflow":"wf1"},{"name":"wfr1","workflow":"wf1"}]}`))
+	}))
+	defer server.Close()
+
+	summaries, err := NewClient(server.URL).ListWorkflowRunSummaries("cyclone--t1", "p1", "wf1", v1alpha1.StatusCompleted, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "/apis/v1alpha1/projects/p1/workflows/wf1/summaries", path)
+	assert.Equal(t, "limit=2&start=0&status=Completed", query)
+	assert.Equal(t, "t1", tenant)
+	assert.Equal(t, []api.WorkflowRunSummary{{Name: "wfr3", Workflow: "wf1"}, {Name: "wfr1", Workflow: "wf1"}}, summaries)
+}
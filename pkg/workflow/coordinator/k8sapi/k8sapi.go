@@ -9,9 +9,12 @@ import (
 	core_v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
 	"github.com/caicloud/cyclone/pkg/workflow/common"
 	"github.com/caicloud/cyclone/pkg/workflow/coordinator/cycloneserver"
+	"github.com/caicloud/cyclone/pkg/workflow/logs"
 )
 
 // Executor ...
@@ -89,28 +92,12 @@ func (k *Executor) GetPod() (*core_v1.Pod, error) {
 	return k.client.CoreV1().Pods(k.namespace).Get(k.podName, meta_v1.GetOptions{})
 }
 
-// CollectLog collects container logs.
-func (k *Executor) CollectLog(container, workflowrun, stage string) error {
+// CollectLog collects container logs and pushes them to Cyclone server as records.
+func (k *Executor) CollectLog(container string, wfr *v1alpha1.WorkflowRun, stage string) error {
 	log.Infof("Start to collect %s log", container)
-	stream, err := k.client.CoreV1().Pods(k.namespace).GetLogs(k.podName, &core_v1.PodLogOptions{
-		Container: container,
-		Follow:    true,
-	}).Stream()
-	if err != nil {
-		return err
-	}
-
-	closeLog := make(chan struct{})
-	defer func() {
-		stream.Close()
-		close(closeLog)
-	}()
-
-	k.cycloneClient.PushLogStream(workflowrun, stage, container, stream, closeLog)
-	if err != nil {
-		return err
-	}
-	return nil
+	return logs.Push(k.cycloneClient, wfr, stage, container, func(records chan<- *api.LogRecord) error {
+		return k.readLogs(container, records)
+	})
 }
 
 // CopyFromContainer copy a file/directory from container:path to dst.
@@ -0,0 +1,94 @@
+package k8sapi
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/logs"
+)
+
+// dockerContainerIDPrefix is prefix of container ID in pod status when docker is used as container runtime.
+const dockerContainerIDPrefix = "docker://"
+
+// readLogs follows logs of the container and sends them as records until the container terminates.
+// Logs are read by docker if it's the container runtime, so that stdout and stderr can be told apart,
+// otherwise they are read by k8s API as combined stream.
+func (k *Executor) readLogs(container string, records chan<- *api.LogRecord) error {
+	id, err := k.containerID(container)
+	if err != nil {
+		log.WithField("container", container).Warningf("Get container id error: %v", err)
+	}
+
+	if strings.HasPrefix(id, dockerContainerIDPrefix) {
+		started, err := dockerLogs(strings.TrimPrefix(id, dockerContainerIDPrefix), container, records)
+		if started {
+			return err
+		}
+		log.WithField("container", container).Warningf("Read logs by docker error: %v, fall back to k8s API", err)
+	}
+
+	return logs.ReadAPILogs(k.client, k.namespace, k.podName, container, true, records)
+}
+
+// containerID gets ID of the container from pod status, init containers included.
+func (k *Executor) containerID(container string) (string, error) {
+	pod, err := k.GetPod()
+	if err != nil {
+		return "", err
+	}
+
+	statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.Name == container {
+			return cs.ContainerID, nil
+		}
+	}
+	return "", fmt.Errorf("container %s not found in pod status", container)
+}
+
+// dockerLogs follows logs of the container by docker, stdout and stderr are read separately.
+// started tells whether docker has been started successfully.
+func dockerLogs(id, container string, records chan<- *api.LogRecord) (started bool, err error) {
+	cmd := exec.Command("docker", "logs", "--follow", "--timestamps", id)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return false, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return false, err
+	}
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, s := range []struct {
+		reader io.Reader
+		stream api.LogStream
+	}{{stdout, api.LogStreamStdout}, {stderr, api.LogStreamStderr}} {
+		wg.Add(1)
+		go func(i int, r io.Reader, stream api.LogStream) {
+			defer wg.Done()
+			errs[i] = logs.ScanRecords(r, container, stream, records)
+		}(i, s.reader, s.stream)
+	}
+	wg.Wait()
+
+	if err := cmd.Wait(); err != nil {
+		return true, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,157 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+// Terminated checks whether the status is a terminated status.
+func Terminated(status string) bool {
+	return status == v1alpha1.StatusCompleted || status == v1alpha1.StatusError || status == v1alpha1.StatusCancelled
+}
+
+// Summarize summarizes the WorkflowRun. Artifacts of stages are not included, since they are
+// got from Stages, and PVC is only included if it's given in the WorkflowRun. Start time of
+// the WorkflowRun defaults to its creation time.
+func Summarize(wfr *v1alpha1.WorkflowRun) *api.WorkflowRunSummary {
+	summary := &api.WorkflowRunSummary{
+		Name:         wfr.Name,
+		Project:      wfr.Labels[common.ProjectNameLabelName],
+		Workflow:     wfr.Labels[common.WorkflowNameLabelName],
+		TriggeredBy:  wfr.Spec.TriggeredBy,
+		CreationTime: wfr.CreationTimestamp,
+		Resources:    wfr.Spec.Resources,
+		Parameters:   wfr.Spec.Stages,
+		Status:       wfr.Status.Overall,
+		Stages:       make(map[string]api.StageSummary),
+	}
+	if wfr.Spec.WorkflowRef != nil && wfr.Spec.WorkflowRef.Name != "" {
+		summary.Workflow = wfr.Spec.WorkflowRef.Name
+	}
+	if wfr.Spec.ExecutionContext != nil {
+		summary.PVC = wfr.Spec.ExecutionContext.PVC
+	}
+	if summary.Status.StartTime.IsZero() {
+		summary.Status.StartTime = wfr.CreationTimestamp
+	}
+	if summary.Status.Status == "" {
+		summary.Status.Status = v1alpha1.StatusPending
+	}
+	// WorkflowRuns created by cron triggers before provenance is recorded only have the label.
+	if trigger, ok := wfr.Labels[common.WorkflowTriggerNameLabelName]; ok && summary.TriggeredBy == nil {
+		summary.TriggeredBy = &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeCron, Trigger: trigger}
+	}
+
+	for name, status := range wfr.Status.Stages {
+		summary.Stages[name] = api.StageSummary{
+			Status:  status.Status,
+			Outputs: status.Outputs,
+		}
+	}
+
+	return summary
+}
+
+// Duration gets duration of the terminated status in seconds, time of the last transition is
+// used if completion time is not recorded. 0 is returned if timings are not available.
+func Duration(status *v1alpha1.Status) int64 {
+	end := status.CompletionTime
+	if end.IsZero() {
+		end = status.LastTransitionTime
+	}
+	if status.StartTime.IsZero() || end.IsZero() || end.Before(&status.StartTime) {
+		return 0
+	}
+	return int64(end.Sub(status.StartTime.Time).Seconds())
+}
+
+// Merge merges summaries archived with WorkflowRuns not deleted yet, the latter take precedence
+// since they are up to date. WorkflowRuns without Workflow reference are skipped. Results are
+// sorted by creation time, newest first.
+func Merge(archived []api.WorkflowRunSummary, wfrs []v1alpha1.WorkflowRun) []api.WorkflowRunSummary {
+	merged := make(map[string]api.WorkflowRunSummary)
+	for _, s := range archived {
+		merged[s.Workflow+"/"+s.Name] = s
+	}
+	for i := range wfrs {
+		if wfrs[i].Spec.WorkflowRef == nil {
+			continue
+		}
+		s := Summarize(&wfrs[i])
+		merged[s.Workflow+"/"+s.Name] = *s
+	}
+
+	results := make([]api.WorkflowRunSummary, 0, len(merged))
+	for _, s := range merged {
+		results = append(results, s)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].CreationTime.Equal(&results[j].CreationTime) {
+			return results[j].CreationTime.Before(&results[i].CreationTime)
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// Archiver archives summaries of WorkflowRuns to the summary store, so that history of
+// WorkflowRuns is kept after they are deleted.
+type Archiver interface {
+	// Archive archives the WorkflowRun, it should be idempotent.
+	Archive(wfr *v1alpha1.WorkflowRun) error
+}
+
+// Recorder archives summaries of WorkflowRuns once they are terminated.
+type Recorder struct {
+	archiver Archiver
+
+	lock sync.Mutex
+	// recorded are WorkflowRuns already recorded.
+	recorded map[types.UID]bool
+}
+
+// NewRecorder creates a history recorder.
+func NewRecorder(archiver Archiver) *Recorder {
+	return &Recorder{
+		archiver: archiver,
+		recorded: make(map[types.UID]bool),
+	}
+}
+
+// Record archives summary of the WorkflowRun if it's terminated and not recorded yet.
+func (r *Recorder) Record(wfr *v1alpha1.WorkflowRun) error {
+	if !Terminated(wfr.Status.Overall.Status) {
+		return nil
+	}
+
+	r.lock.Lock()
+	recorded := r.recorded[wfr.UID]
+	r.lock.Unlock()
+	if recorded {
+		return nil
+	}
+
+	if err := r.archiver.Archive(wfr); err != nil {
+		return fmt.Errorf("record history error: %v", err)
+	}
+
+	r.lock.Lock()
+	r.recorded[wfr.UID] = true
+	r.lock.Unlock()
+	return nil
+}
+
+// Forget forgets the deleted WorkflowRun, its summary is kept in the history.
+func (r *Recorder) Forget(wfr *v1alpha1.WorkflowRun) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.recorded, wfr.UID)
+	return nil
+}
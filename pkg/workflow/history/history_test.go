@@ -0,0 +1,117 @@
+package history
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+func workflowRun(name, workflow, status string) *v1alpha1.WorkflowRun {
+	start := metav1.Time{Time: time.Unix(100, 0)}
+	return &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			UID:             types.UID(name),
+			Labels:          map[string]string{common.ProjectNameLabelName: "p1"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Workflow", Name: workflow}},
+		},
+		Spec: v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: workflow}},
+		Status: v1alpha1.WorkflowRunStatus{
+			Overall: v1alpha1.Status{
+				Status:         status,
+				StartTime:      start,
+				CompletionTime: metav1.Time{Time: time.Unix(160, 0)},
+			},
+			Stages: map[string]*v1alpha1.StageStatus{
+				"build": {Status: v1alpha1.Status{
+					Status:             status,
+					StartTime:          start,
+					LastTransitionTime: metav1.Time{Time: time.Unix(130, 0)},
+				}},
+			},
+		},
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	wfr := workflowRun("wf-1", "wf", v1alpha1.StatusError)
+	wfr.Labels[common.WorkflowTriggerNameLabelName] = "nightly"
+	s := Summarize(wfr)
+	assert.Equal(t, "p1", s.Project)
+	assert.Equal(t, "wf", s.Workflow)
+	assert.Equal(t, &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeCron, Trigger: "nightly"}, s.TriggeredBy)
+	assert.Equal(t, int64(60), Duration(&s.Status))
+	build := s.Stages["build"]
+	assert.Equal(t, v1alpha1.StatusError, build.Status.Status)
+	assert.Equal(t, int64(30), Duration(&build.Status))
+
+	// Start time defaults to creation time.
+	wfr = workflowRun("wf-2", "wf", "")
+	wfr.CreationTimestamp = metav1.Time{Time: time.Unix(90, 0)}
+	wfr.Status.Overall.StartTime = metav1.Time{}
+	s = Summarize(wfr)
+	assert.Equal(t, v1alpha1.StatusPending, s.Status.Status)
+	assert.Equal(t, int64(70), Duration(&s.Status))
+}
+
+func TestMerge(t *testing.T) {
+	archived := []api.WorkflowRunSummary{
+		{Name: "wf-1", Workflow: "wf", CreationTime: metav1.Time{Time: time.Unix(1, 0)}, Status: v1alpha1.Status{Status: v1alpha1.StatusCompleted}},
+		{Name: "wf-2", Workflow: "wf", CreationTime: metav1.Time{Time: time.Unix(2, 0)}, Status: v1alpha1.Status{Status: v1alpha1.StatusRunning}},
+	}
+	wfr2 := workflowRun("wf-2", "wf", v1alpha1.StatusError)
+	wfr2.CreationTimestamp = metav1.Time{Time: time.Unix(2, 0)}
+	wfr3 := workflowRun("wf-3", "wf", v1alpha1.StatusRunning)
+	wfr3.CreationTimestamp = metav1.Time{Time: time.Unix(3, 0)}
+	invalid := workflowRun("wf-4", "wf", v1alpha1.StatusRunning)
+	invalid.Spec.WorkflowRef = nil
+
+	merged := Merge(archived, []v1alpha1.WorkflowRun{*wfr2, *wfr3, *invalid})
+	assert.Equal(t, 3, len(merged))
+	assert.Equal(t, "wf-3", merged[0].Name)
+	assert.Equal(t, "wf-2", merged[1].Name)
+	assert.Equal(t, v1alpha1.StatusError, merged[1].Status.Status)
+	assert.Equal(t, "wf-1", merged[2].Name)
+}
+
+type fakeArchiver struct {
+	archived []string
+	err      error
+}
+
+func (a *fakeArchiver) Archive(wfr *v1alpha1.WorkflowRun) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.archived = append(a.archived, wfr.Name)
+	return nil
+}
+
+func TestRecord(t *testing.T) {
+	archiver := &fakeArchiver{err: fmt.Errorf("unavailable")}
+	r := NewRecorder(archiver)
+
+	r.Record(workflowRun("wf-1", "wf", v1alpha1.StatusRunning))
+	r.Record(workflowRun("wf-1", "wf", v1alpha1.StatusCompleted))
+	assert.Equal(t, 0, len(archiver.archived))
+
+	// Archived once when the server is available.
+	archiver.err = nil
+	r.Record(workflowRun("wf-1", "wf", v1alpha1.StatusCompleted))
+	r.Record(workflowRun("wf-1", "wf", v1alpha1.StatusCompleted))
+	r.Record(workflowRun("wf-2", "wf", v1alpha1.StatusError))
+	assert.Equal(t, []string{"wf-1", "wf-2"}, archiver.archived)
+
+	r.Forget(workflowRun("wf-1", "wf", v1alpha1.StatusCompleted))
+	assert.Equal(t, 1, len(r.recorded))
+}
@@ -0,0 +1,184 @@
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+// Config configures leader election.
+type Config struct {
+	// Client is used to operate the lock.
+	Client clientset.Interface
+	// LockType is type of the lock resource, ConfigMapsLock or LeasesLock, ConfigMapsLock by default.
+	LockType string
+	// Namespace and Name of the lock.
+	Namespace string
+	Name      string
+	// Identity is the unique identity of this candidate.
+	Identity string
+	// EventRecorder records leadership changes as events of the lock, a recorder of the
+	// LeaderElection component is used if it's not set.
+	EventRecorder record.EventRecorder
+	// LeaseDuration is the duration that non-leader candidates will wait to force acquire
+	// leadership, it's measured from the last time the record is observed changed.
+	LeaseDuration time.Duration
+	// RenewDeadline is the duration that the leader will retry refreshing leadership before
+	// giving up.
+	RenewDeadline time.Duration
+	// RetryPeriod is the duration candidates should wait between tries of actions.
+	RetryPeriod time.Duration
+}
+
+// DefaultConfig creates a config with default durations, which are the same as components of
+// Kubernetes.
+func DefaultConfig(client clientset.Interface, namespace, name, identity string) Config {
+	return Config{
+		Client:        client,
+		LockType:      ConfigMapsLock,
+		Namespace:     namespace,
+		Name:          name,
+		Identity:      identity,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+	}
+}
+
+// Elector elects a leader among candidates with a lock resource, it's built on leader election
+// of client-go, and additionally releases leadership when stopped and reports its health.
+type Elector struct {
+	config  Config
+	lock    *renewTracker
+	elector *leaderelection.LeaderElector
+
+	// lead is the leading function given to Run.
+	lead func(ctx context.Context)
+
+	// mutex protects leading, which is read by health checks.
+	mutex   sync.Mutex
+	leading bool
+
+	// now returns current time, it's replaced in tests.
+	now func() time.Time
+}
+
+// NewElector creates a leader elector.
+func NewElector(config Config) (*Elector, error) {
+	if config.Identity == "" {
+		return nil, fmt.Errorf("identity can not be empty")
+	}
+	if config.EventRecorder == nil {
+		config.EventRecorder = common.GetEventRecorder(config.Client, common.EventSourceLeaderElection)
+	}
+	lock, err := newResourceLock(config.LockType, config.Client, config.Namespace, config.Name, rl.ResourceLockConfig{
+		Identity:      config.Identity,
+		EventRecorder: config.EventRecorder,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Elector{
+		config: config,
+		lock:   &renewTracker{Interface: lock, now: time.Now},
+		now:    time.Now,
+	}
+	e.elector, err = leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          e.lock,
+		LeaseDuration: config.LeaseDuration,
+		RenewDeadline: config.RenewDeadline,
+		RetryPeriod:   config.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: e.startLeading,
+			OnStoppedLeading: e.stopLeading,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Run blocks until leadership is acquired, then runs the leading function with a context that
+// is cancelled once leadership is lost, and returns. It also returns if ctx is cancelled, the
+// leadership is released in this case, so that other candidates can take over immediately.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	e.lead = lead
+	e.elector.Run(ctx)
+	if ctx.Err() != nil {
+		e.release()
+	}
+}
+
+// IsLeader returns whether this candidate is leading.
+func (e *Elector) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.leading
+}
+
+// Check checks health of the elector, it fails if this candidate is leading but hasn't renewed
+// leadership for longer than lease duration plus the tolerance, which means the leader is stuck.
+func (e *Elector) Check(tolerance time.Duration) error {
+	if !e.IsLeader() {
+		return nil
+	}
+	renewTime := e.lock.lastRenew()
+	if e.now().Sub(renewTime) > e.config.LeaseDuration+tolerance {
+		return fmt.Errorf("leadership not renewed since %s", renewTime.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (e *Elector) startLeading(ctx context.Context) {
+	log.WithField("lock", e.lock.Describe()).WithField("identity", e.config.Identity).Info("Leadership acquired")
+	e.setLeading(true)
+	e.lead(ctx)
+}
+
+func (e *Elector) stopLeading() {
+	e.setLeading(false)
+}
+
+func (e *Elector) setLeading(leading bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.leading = leading
+}
+
+// release releases leadership held by this candidate, by clearing holder of the record.
+func (e *Elector) release() {
+	logger := log.WithField("lock", e.lock.Describe())
+	old, err := e.lock.Get()
+	if err != nil {
+		logger.Warn("Get leader election lock to release error: ", err)
+		return
+	}
+	if old.HolderIdentity != e.config.Identity {
+		return
+	}
+
+	now := metav1.Time{Time: e.now()}
+	err = e.lock.Update(rl.LeaderElectionRecord{
+		LeaseDurationSeconds: 1,
+		AcquireTime:          now,
+		RenewTime:            now,
+		LeaderTransitions:    old.LeaderTransitions,
+	})
+	if err != nil {
+		logger.Warn("Release leader election lock error: ", err)
+		return
+	}
+	logger.Info("Leadership released")
+}
@@ -0,0 +1,116 @@
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+)
+
+func testConfig(identity, lockType string) Config {
+	config := DefaultConfig(fake.NewSimpleClientset(), "default", "lock", identity)
+	config.LockType = lockType
+	config.EventRecorder = record.NewFakeRecorder(10)
+	return config
+}
+
+func TestNewElector(t *testing.T) {
+	_, err := NewElector(testConfig("", ConfigMapsLock))
+	assert.NotNil(t, err)
+
+	config := testConfig("a", ConfigMapsLock)
+	config.RenewDeadline = config.LeaseDuration
+	_, err = NewElector(config)
+	assert.NotNil(t, err)
+
+	_, err = NewElector(testConfig("a", "endpoints"))
+	assert.NotNil(t, err)
+}
+
+func TestLeaseLock(t *testing.T) {
+	config := testConfig("a", LeasesLock)
+	lock, err := newResourceLock(config.LockType, config.Client, config.Namespace, config.Name, rl.ResourceLockConfig{
+		Identity:      config.Identity,
+		EventRecorder: config.EventRecorder,
+	})
+	assert.Nil(t, err)
+
+	_, err = lock.Get()
+	assert.True(t, errors.IsNotFound(err))
+	assert.NotNil(t, lock.Update(rl.LeaderElectionRecord{HolderIdentity: "a"}))
+
+	assert.Nil(t, lock.Create(rl.LeaderElectionRecord{HolderIdentity: "a", LeaseDurationSeconds: 15}))
+	record, err := lock.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "a", record.HolderIdentity)
+	assert.Equal(t, 15, record.LeaseDurationSeconds)
+
+	assert.Nil(t, lock.Update(rl.LeaderElectionRecord{HolderIdentity: "b", LeaderTransitions: 1}))
+	record, err = lock.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "b", record.HolderIdentity)
+	assert.Equal(t, 1, record.LeaderTransitions)
+	assert.Equal(t, "default/lock", lock.Describe())
+}
+
+func TestRun(t *testing.T) {
+	for _, lockType := range []string{ConfigMapsLock, LeasesLock} {
+		config := testConfig("a", lockType)
+		config.LeaseDuration = time.Second
+		config.RenewDeadline = 500 * time.Millisecond
+		config.RetryPeriod = 10 * time.Millisecond
+		a, err := NewElector(config)
+		assert.Nil(t, err, lockType)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		leading := make(chan context.Context, 1)
+		done := make(chan struct{})
+		go func() {
+			a.Run(ctx, func(ctx context.Context) {
+				leading <- ctx
+			})
+			close(done)
+		}()
+
+		leadCtx := <-leading
+		assert.True(t, a.IsLeader(), lockType)
+		assert.Nil(t, a.Check(0), lockType)
+		record, err := a.lock.Get()
+		assert.Nil(t, err, lockType)
+		assert.Equal(t, "a", record.HolderIdentity, lockType)
+
+		// Leading context is cancelled and leadership is released when stopped.
+		cancel()
+		<-done
+		<-leadCtx.Done()
+		assert.False(t, a.IsLeader(), lockType)
+		record, err = a.lock.Get()
+		assert.Nil(t, err, lockType)
+		assert.Equal(t, "", record.HolderIdentity, lockType)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	a, err := NewElector(testConfig("a", ConfigMapsLock))
+	assert.Nil(t, err)
+	a.now = func() time.Time { return now }
+	a.lock.now = a.now
+	assert.Nil(t, a.Check(0))
+
+	// Renewals by other candidates are not tracked.
+	a.setLeading(true)
+	a.lock.observe(rl.LeaderElectionRecord{HolderIdentity: "b"})
+	assert.NotNil(t, a.Check(0))
+
+	a.lock.observe(rl.LeaderElectionRecord{HolderIdentity: "a"})
+	now = now.Add(20 * time.Second)
+	assert.Nil(t, a.Check(10*time.Second))
+	assert.NotNil(t, a.Check(0))
+}
@@ -0,0 +1,194 @@
+package leaderelection
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+)
+
+const (
+	// ConfigMapsLock stores leader election record in annotations of a ConfigMap.
+	ConfigMapsLock = rl.ConfigMapsResourceLock
+	// LeasesLock stores leader election record in a Lease, it requires coordination.k8s.io API.
+	LeasesLock = "leases"
+)
+
+// newResourceLock creates the lock of the given type. ConfigMap locks are provided by client-go,
+// while Lease locks are implemented here since the client-go vendored doesn't support them yet.
+func newResourceLock(lockType string, client clientset.Interface, namespace, name string, config rl.ResourceLockConfig) (rl.Interface, error) {
+	switch lockType {
+	case "", ConfigMapsLock:
+		return rl.New(rl.ConfigMapsResourceLock, namespace, name, client.CoreV1(), config)
+	case LeasesLock:
+		return &leaseLock{
+			client: client,
+			meta:   metav1.ObjectMeta{Namespace: namespace, Name: name},
+			config: config,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported lock type '%s'", lockType)
+	}
+}
+
+// leaseLock stores the record in spec of a Lease.
+type leaseLock struct {
+	client clientset.Interface
+	meta   metav1.ObjectMeta
+	config rl.ResourceLockConfig
+	lease  *coordinationv1beta1.Lease
+}
+
+var _ rl.Interface = (*leaseLock)(nil)
+
+// Get ...
+func (l *leaseLock) Get() (*rl.LeaderElectionRecord, error) {
+	lease, err := l.client.CoordinationV1beta1().Leases(l.meta.Namespace).Get(l.meta.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	l.lease = lease
+	return leaseSpecToRecord(&lease.Spec), nil
+}
+
+// Create ...
+func (l *leaseLock) Create(record rl.LeaderElectionRecord) error {
+	lease, err := l.client.CoordinationV1beta1().Leases(l.meta.Namespace).Create(&coordinationv1beta1.Lease{
+		ObjectMeta: l.meta,
+		Spec:       recordToLeaseSpec(record),
+	})
+	if err != nil {
+		return err
+	}
+	l.lease = lease
+	return nil
+}
+
+// Update ...
+func (l *leaseLock) Update(record rl.LeaderElectionRecord) error {
+	if l.lease == nil {
+		return fmt.Errorf("lock not got before update")
+	}
+	lease := l.lease.DeepCopy()
+	lease.Spec = recordToLeaseSpec(record)
+	lease, err := l.client.CoordinationV1beta1().Leases(l.meta.Namespace).Update(lease)
+	if err != nil {
+		return err
+	}
+	l.lease = lease
+	return nil
+}
+
+// RecordEvent ...
+func (l *leaseLock) RecordEvent(s string) {
+	l.config.EventRecorder.Eventf(&coordinationv1beta1.Lease{ObjectMeta: l.meta}, corev1.EventTypeNormal, "LeaderElection", "%v %v", l.config.Identity, s)
+}
+
+// Identity ...
+func (l *leaseLock) Identity() string {
+	return l.config.Identity
+}
+
+// Describe ...
+func (l *leaseLock) Describe() string {
+	return fmt.Sprintf("%v/%v", l.meta.Namespace, l.meta.Name)
+}
+
+func leaseSpecToRecord(spec *coordinationv1beta1.LeaseSpec) *rl.LeaderElectionRecord {
+	record := &rl.LeaderElectionRecord{}
+	if spec.HolderIdentity != nil {
+		record.HolderIdentity = *spec.HolderIdentity
+	}
+	if spec.LeaseDurationSeconds != nil {
+		record.LeaseDurationSeconds = int(*spec.LeaseDurationSeconds)
+	}
+	if spec.LeaseTransitions != nil {
+		record.LeaderTransitions = int(*spec.LeaseTransitions)
+	}
+	if spec.AcquireTime != nil {
+		record.AcquireTime = metav1.Time{Time: spec.AcquireTime.Time}
+	}
+	if spec.RenewTime != nil {
+		record.RenewTime = metav1.Time{Time: spec.RenewTime.Time}
+	}
+	return record
+}
+
+func recordToLeaseSpec(record rl.LeaderElectionRecord) coordinationv1beta1.LeaseSpec {
+	duration := int32(record.LeaseDurationSeconds)
+	transitions := int32(record.LeaderTransitions)
+	return coordinationv1beta1.LeaseSpec{
+		HolderIdentity:       &record.HolderIdentity,
+		LeaseDurationSeconds: &duration,
+		AcquireTime:          &metav1.MicroTime{Time: record.AcquireTime.Time},
+		RenewTime:            &metav1.MicroTime{Time: record.RenewTime.Time},
+		LeaseTransitions:     &transitions,
+	}
+}
+
+// renewTracker tracks the last time the leadership is acquired or renewed by this candidate
+// through the lock, for health checks. It also serializes operations on the lock, since the
+// renewal of client-go may still be in flight when leadership is released.
+type renewTracker struct {
+	rl.Interface
+
+	// now returns current time, it's replaced in tests.
+	now func() time.Time
+
+	// lockMutex protects the lock, which caches the object got or updated.
+	lockMutex sync.Mutex
+
+	mutex     sync.Mutex
+	renewTime time.Time
+}
+
+// Get ...
+func (t *renewTracker) Get() (*rl.LeaderElectionRecord, error) {
+	t.lockMutex.Lock()
+	defer t.lockMutex.Unlock()
+	return t.Interface.Get()
+}
+
+// Create ...
+func (t *renewTracker) Create(record rl.LeaderElectionRecord) error {
+	t.lockMutex.Lock()
+	defer t.lockMutex.Unlock()
+	if err := t.Interface.Create(record); err != nil {
+		return err
+	}
+	t.observe(record)
+	return nil
+}
+
+// Update ...
+func (t *renewTracker) Update(record rl.LeaderElectionRecord) error {
+	t.lockMutex.Lock()
+	defer t.lockMutex.Unlock()
+	if err := t.Interface.Update(record); err != nil {
+		return err
+	}
+	t.observe(record)
+	return nil
+}
+
+func (t *renewTracker) observe(record rl.LeaderElectionRecord) {
+	if record.HolderIdentity != t.Identity() {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.renewTime = t.now()
+}
+
+// lastRenew gets the local time when the leadership is renewed last time.
+func (t *renewTracker) lastRenew() time.Time {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.renewTime
+}
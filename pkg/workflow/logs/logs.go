@@ -0,0 +1,235 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	core_v1 "k8s.io/api/core/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/coordinator/cycloneserver"
+)
+
+const (
+	// recordsBufferSize is size of the buffer of log records to push.
+	recordsBufferSize = 256
+
+	// settleTimeout is the maximum time to wait for logs being written to be finished.
+	settleTimeout = 10 * time.Second
+
+	// settleInterval is the interval to check whether logs being written are finished.
+	settleInterval = time.Second
+
+	// maxLineSize is the maximum size of a line to read.
+	maxLineSize = 1024 * 1024
+)
+
+// Push reads logs of a container by read, and pushes them to Cyclone server as records.
+func Push(client cycloneserver.Client, wfr *v1alpha1.WorkflowRun, stage, container string, read func(records chan<- *api.LogRecord) error) error {
+	records := make(chan *api.LogRecord, recordsBufferSize)
+	pushed := make(chan error, 1)
+	go func() {
+		pushed <- client.PushLogStream(wfr, stage, container, records)
+	}()
+
+	err := read(records)
+	close(records)
+	if pushErr := <-pushed; pushErr != nil {
+		return pushErr
+	}
+	return err
+}
+
+// ReadAPILogs reads logs of the container by k8s API and sends them as records, stdout and
+// stderr are combined. If follow is true, it returns until the container terminates.
+func ReadAPILogs(client clientset.Interface, namespace, pod, container string, follow bool, records chan<- *api.LogRecord) error {
+	stream, err := client.CoreV1().Pods(namespace).GetLogs(pod, &core_v1.PodLogOptions{
+		Container:  container,
+		Follow:     follow,
+		Timestamps: true,
+	}).Stream()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	return ScanRecords(stream, container, api.LogStreamCombined, records)
+}
+
+// Tail gets the last lines of output of the container by k8s API.
+func Tail(client clientset.Interface, namespace, pod, container string, lines int64) ([]string, error) {
+	stream, err := client.CoreV1().Pods(namespace).GetLogs(pod, &core_v1.PodLogOptions{
+		Container: container,
+		TailLines: &lines,
+	}).Stream()
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var results []string
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		results = append(results, scanner.Text())
+	}
+	return results, scanner.Err()
+}
+
+// Recover checks logs of containers in the stage pod, and pushes logs that are missing or
+// incomplete by k8s API. It's used when the stage pod terminates, in case coordinator failed
+// to collect the logs, for example, coordinator got killed or the pod failed in initialization.
+// It returns nil if all logs are complete, otherwise status of logs with the reason is returned.
+func Recover(client clientset.Interface, cycloneClient cycloneserver.Client, pod *core_v1.Pod, wfr *v1alpha1.WorkflowRun, stage, reason string) *v1alpha1.LogsStatus {
+	containers := podContainers(pod)
+	states, err := waitStates(cycloneClient, wfr, stage, containers)
+	if err != nil {
+		log.WithField("wfr", wfr.Name).WithField("stage", stage).Warningf("Get logs status error: %v", err)
+		return &v1alpha1.LogsStatus{
+			Reason:  reason,
+			Message: fmt.Sprintf("Failed to get logs status: %v", err),
+		}
+	}
+
+	var recovered, lost []string
+	for _, container := range containers {
+		switch states[container] {
+		case api.LogStateCompleted:
+			continue
+		case api.LogStateWriting:
+			lost = append(lost, fmt.Sprintf("%s: still being pushed", container))
+			continue
+		}
+
+		// Containers never started have no logs.
+		if !started(pod, container) {
+			continue
+		}
+
+		log.WithField("pod", pod.Name).WithField("container", container).Info("Recover log by k8s API")
+		err := Push(cycloneClient, wfr, stage, container, func(records chan<- *api.LogRecord) error {
+			return ReadAPILogs(client, pod.Namespace, pod.Name, container, false, records)
+		})
+		if err != nil {
+			log.WithField("pod", pod.Name).WithField("container", container).Warningf("Recover log error: %v", err)
+			lost = append(lost, fmt.Sprintf("%s: %v", container, err))
+			continue
+		}
+		recovered = append(recovered, container)
+	}
+
+	if len(recovered) == 0 && len(lost) == 0 {
+		return nil
+	}
+
+	var messages []string
+	if len(recovered) > 0 {
+		messages = append(messages, fmt.Sprintf("Logs of %s are recovered by k8s API, they may be truncated by kubelet.", strings.Join(recovered, ", ")))
+	}
+	if len(lost) > 0 {
+		messages = append(messages, fmt.Sprintf("Logs may be incomplete: %s.", strings.Join(lost, "; ")))
+	}
+	return &v1alpha1.LogsStatus{
+		Reason:  reason,
+		Message: strings.Join(messages, " "),
+	}
+}
+
+// waitStates gets states of logs of the containers, it waits a while for logs being written
+// to be finished, since coordinator may have just pushed them.
+func waitStates(cycloneClient cycloneserver.Client, wfr *v1alpha1.WorkflowRun, stage string, containers []string) (map[string]api.LogState, error) {
+	deadline := time.Now().Add(settleTimeout)
+	for {
+		status, err := cycloneClient.ListLogsStatus(wfr, stage)
+		if err != nil {
+			return nil, err
+		}
+
+		states := make(map[string]api.LogState)
+		for _, s := range status {
+			states[s.Container] = s.State
+		}
+
+		writing := false
+		for _, c := range containers {
+			if states[c] == api.LogStateWriting {
+				writing = true
+				break
+			}
+		}
+		if !writing || time.Now().After(deadline) {
+			return states, nil
+		}
+		time.Sleep(settleInterval)
+	}
+}
+
+// podContainers gets names of containers whose logs should be collected, that is all
+// containers except for the coordinator, init containers included.
+func podContainers(pod *core_v1.Pod) []string {
+	var containers []string
+	for _, c := range pod.Spec.InitContainers {
+		containers = append(containers, c.Name)
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name != common.CoordinatorSidecarName {
+			containers = append(containers, c.Name)
+		}
+	}
+	return containers
+}
+
+// started checks whether the container has ever started.
+func started(pod *core_v1.Pod, container string) bool {
+	for _, statuses := range [][]core_v1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, cs := range statuses {
+			if cs.Name == container {
+				return cs.State.Running != nil || cs.State.Terminated != nil || cs.LastTerminationState.Terminated != nil
+			}
+		}
+	}
+	return false
+}
+
+// ScanRecords reads lines prefixed with RFC3339Nano timestamp, and sends them as records.
+func ScanRecords(r io.Reader, container string, stream api.LogStream, records chan<- *api.LogRecord) error {
+	buf := bufio.NewReader(r)
+	for {
+		line, err := buf.ReadString('\n')
+		if len(line) > 0 {
+			t, content := parseTimestampedLine(strings.TrimSuffix(line, "\n"))
+			records <- &api.LogRecord{
+				Time:      t,
+				Container: container,
+				Stream:    stream,
+				Line:      content,
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseTimestampedLine splits the leading timestamp from the line, current time is used
+// if the line has no valid timestamp.
+func parseTimestampedLine(line string) (time.Time, string) {
+	i := strings.IndexByte(line, ' ')
+	if i > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, line[:i]); err == nil {
+			return t, line[i+1:]
+		}
+	}
+	return time.Now(), line
+}
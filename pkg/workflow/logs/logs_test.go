@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+type fakeClient struct {
+	records   []*api.LogRecord
+	err       error
+	status    []api.LogStatus
+	statusErr error
+}
+
+func (c *fakeClient) ListLogsStatus(wfr *v1alpha1.WorkflowRun, stage string) ([]api.LogStatus, error) {
+	return c.status, c.statusErr
+}
+
+func (c *fakeClient) CreateWorkflowRunSummary(wfr *v1alpha1.WorkflowRun, summary *api.WorkflowRunSummary) error {
+	return nil
+}
+
+func (c *fakeClient) ListWorkflowRunSummaries(namespace, project, workflow, status string, limit int) ([]api.WorkflowRunSummary, error) {
+	return nil, nil
+}
+
+func (c *fakeClient) PushLogStream(wfr *v1alpha1.WorkflowRun, stage, container string, records <-chan *api.LogRecord) error {
+	for r := range records {
+		c.records = append(c.records, r)
+	}
+	return c.err
+}
+
+func TestScanRecords(t *testing.T) {
+	records := make(chan *api.LogRecord, 10)
+	err := ScanRecords(strings.NewReader("2019-01-01T00:00:01.5Z cloning\nno timestamp\n2019-01-01T00:00:02Z done"), "i1", api.LogStreamCombined, records)
+	close(records)
+	assert.Nil(t, err)
+
+	var lines []*api.LogRecord
+	for r := range records {
+		lines = append(lines, r)
+	}
+	assert.Equal(t, 3, len(lines))
+	assert.Equal(t, "cloning", lines[0].Line)
+	assert.Equal(t, 500000000, lines[0].Time.Nanosecond())
+	assert.Equal(t, "i1", lines[0].Container)
+	assert.Equal(t, "no timestamp", lines[1].Line)
+	assert.False(t, lines[1].Time.IsZero())
+	assert.Equal(t, "done", lines[2].Line)
+}
+
+func TestPush(t *testing.T) {
+	client := &fakeClient{}
+	err := Push(client, &v1alpha1.WorkflowRun{}, "stg", "i1", func(records chan<- *api.LogRecord) error {
+		return ScanRecords(strings.NewReader("2019-01-01T00:00:01Z a\n2019-01-01T00:00:02Z b\n"), "i1", api.LogStreamCombined, records)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(client.records))
+
+	readErr := errors.New("read error")
+	err = Push(&fakeClient{}, &v1alpha1.WorkflowRun{}, "stg", "i1", func(records chan<- *api.LogRecord) error {
+		return readErr
+	})
+	assert.Equal(t, readErr, err)
+
+	pushErr := errors.New("push error")
+	err = Push(&fakeClient{err: pushErr}, &v1alpha1.WorkflowRun{}, "stg", "i1", func(records chan<- *api.LogRecord) error {
+		return readErr
+	})
+	assert.Equal(t, pushErr, err)
+}
+
+func TestRecover(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "i1"}},
+			Containers:     []corev1.Container{{Name: "main"}, {Name: common.CoordinatorSidecarName}},
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "i1", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+			},
+		},
+	}
+	wfr := &v1alpha1.WorkflowRun{}
+
+	assert.Equal(t, []string{"i1", "main"}, podContainers(pod))
+	assert.True(t, started(pod, "i1"))
+	assert.False(t, started(pod, "main"))
+
+	// Logs of containers never started are not required.
+	client := &fakeClient{status: []api.LogStatus{{Container: "i1", State: api.LogStateCompleted}}}
+	assert.Nil(t, Recover(nil, client, pod, wfr, "stg", "PodFailed"))
+
+	client = &fakeClient{statusErr: errors.New("unavailable")}
+	status := Recover(nil, client, pod, wfr, "stg", "PodFailed")
+	assert.NotNil(t, status)
+	assert.Equal(t, "PodFailed", status.Reason)
+	assert.Equal(t, "Failed to get logs status: unavailable", status.Message)
+}
@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+)
+
+// namespace is the prefix of all metrics of workflow controller.
+const namespace = "cyclone"
+
+var (
+	stageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "stage_duration_seconds",
+		Help:      "Duration of stages from started to terminated.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 15),
+	}, []string{"workflow", "stage", "status"})
+
+	podCreationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pod_creation_errors_total",
+		Help:      "Number of errors creating pods for stages.",
+	}, []string{"workflow"})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time spent handling events of resources in controllers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"controller", "event"})
+
+	cronTriggers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cron_triggers_total",
+		Help:      "Number of cron trigger fires, result is either succeeded or failed.",
+	}, []string{"namespace", "trigger", "result"})
+
+	// runs tracks phases of WorkflowRuns observed by the controller.
+	runs = newRunTracker()
+)
+
+func init() {
+	prometheus.MustRegister(stageDuration, podCreationErrors, reconcileDuration, cronTriggers, runs)
+}
+
+// Serve serves metrics on '/metrics' endpoint at the given address, it blocks until the server fails.
+// Health and readiness checks are served on '/healthz' and '/readyz', nil check always passes.
+func Serve(address string, healthz, readyz func() error) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/healthz", checkHandler(healthz))
+	mux.Handle("/readyz", checkHandler(readyz))
+	log.WithField("address", address).Info("Serve metrics")
+	return http.ListenAndServe(address, mux)
+}
+
+// checkHandler responds 500 with the error if the check fails, otherwise 200.
+func checkHandler(check func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			if err := check(); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		w.Write([]byte("ok"))
+	}
+}
+
+// RegisterQueue registers a gauge for size of the named queue, size is called on each collection.
+func RegisterQueue(name string, size func() int) {
+	gauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_size",
+		Help:        "Number of items in queues of workflow controller.",
+		ConstLabels: prometheus.Labels{"queue": name},
+	}, func() float64 {
+		return float64(size())
+	})
+	if err := prometheus.Register(gauge); err != nil {
+		log.WithField("queue", name).Warning("Register queue metrics error: ", err)
+	}
+}
+
+// ObserveReconcile records time spent handling an event in the controller.
+func ObserveReconcile(controller, event string, start time.Time) {
+	reconcileDuration.WithLabelValues(controller, event).Observe(time.Since(start).Seconds())
+}
+
+// IncPodCreationErrors counts an error creating pod for stage of the Workflow.
+func IncPodCreationErrors(workflow string) {
+	podCreationErrors.WithLabelValues(workflow).Inc()
+}
+
+// IncCronTrigger counts a fire of the cron trigger.
+func IncCronTrigger(namespace, trigger string, succeeded bool) {
+	result := "succeeded"
+	if !succeeded {
+		result = "failed"
+	}
+	cronTriggers.WithLabelValues(namespace, trigger, result).Inc()
+}
+
+// ObserveWorkflowRun records phase of the WorkflowRun, and durations of its stages that terminated
+// since last observed.
+func ObserveWorkflowRun(wfr *v1alpha1.WorkflowRun) {
+	runs.observe(wfr)
+}
+
+// ForgetWorkflowRun stops tracking the deleted WorkflowRun.
+func ForgetWorkflowRun(wfr *v1alpha1.WorkflowRun) {
+	runs.forget(wfr)
+}
+
+// runTracker tracks WorkflowRuns and collects number of them by phase.
+type runTracker struct {
+	lock sync.Mutex
+	desc *prometheus.Desc
+	// phases are phases of WorkflowRuns.
+	phases map[types.UID]string
+	// terminated are stages terminated of WorkflowRuns.
+	terminated map[types.UID]map[string]bool
+}
+
+func newRunTracker() *runTracker {
+	return &runTracker{
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "workflowruns"),
+			"Number of WorkflowRuns by phase.", []string{"phase"}, nil),
+		phases:     make(map[types.UID]string),
+		terminated: make(map[types.UID]map[string]bool),
+	}
+}
+
+func (t *runTracker) observe(wfr *v1alpha1.WorkflowRun) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	phase := wfr.Status.Overall.Status
+	if phase == "" {
+		phase = v1alpha1.StatusPending
+	}
+	_, known := t.phases[wfr.UID]
+	t.phases[wfr.UID] = phase
+
+	terminated, ok := t.terminated[wfr.UID]
+	if !ok {
+		terminated = make(map[string]bool)
+		t.terminated[wfr.UID] = terminated
+	}
+	for stage, s := range wfr.Status.Stages {
+		if terminated[stage] || !isTerminated(s.Status.Status) {
+			continue
+		}
+		terminated[stage] = true
+
+		// Stages already terminated when the WorkflowRun is first observed, for example, after
+		// controller restarted, have been observed before.
+		if !known || s.Status.StartTime.IsZero() {
+			continue
+		}
+		end := s.Status.CompletionTime
+		if end.IsZero() {
+			end = s.Status.LastTransitionTime
+		}
+		stageDuration.WithLabelValues(wfr.Spec.WorkflowRef.Name, stage, s.Status.Status).
+			Observe(end.Sub(s.Status.StartTime.Time).Seconds())
+	}
+}
+
+func (t *runTracker) forget(wfr *v1alpha1.WorkflowRun) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.phases, wfr.UID)
+	delete(t.terminated, wfr.UID)
+}
+
+// Describe implements prometheus.Collector.
+func (t *runTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.desc
+}
+
+// Collect implements prometheus.Collector.
+func (t *runTracker) Collect(ch chan<- prometheus.Metric) {
+	t.lock.Lock()
+	counts := make(map[string]int)
+	for _, phase := range t.phases {
+		counts[phase]++
+	}
+	t.lock.Unlock()
+
+	for phase, count := range counts {
+		ch <- prometheus.MustNewConstMetric(t.desc, prometheus.GaugeValue, float64(count), phase)
+	}
+}
+
+func isTerminated(status string) bool {
+	return status == v1alpha1.StatusCompleted || status == v1alpha1.StatusError || status == v1alpha1.StatusCancelled
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+)
+
+func workflowRun(uid, overall, stage string) *v1alpha1.WorkflowRun {
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := v1alpha1.Status{Status: stage, StartTime: metav1.Time{Time: start}}
+	if isTerminated(stage) {
+		status.CompletionTime = metav1.Time{Time: start.Add(time.Minute)}
+	}
+	return &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid)},
+		Spec:       v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: "wf"}},
+		Status: v1alpha1.WorkflowRunStatus{
+			Overall: v1alpha1.Status{Status: overall},
+			Stages:  map[string]*v1alpha1.StageStatus{"build": {Status: status}},
+		},
+	}
+}
+
+func phases(t *runTracker) map[string]float64 {
+	ch := make(chan prometheus.Metric, 10)
+	t.Collect(ch)
+	close(ch)
+
+	results := make(map[string]float64)
+	for m := range ch {
+		metric := &dto.Metric{}
+		m.Write(metric)
+		results[metric.Label[0].GetValue()] = metric.Gauge.GetValue()
+	}
+	return results
+}
+
+func stageSamples(status string) uint64 {
+	metric := &dto.Metric{}
+	stageDuration.WithLabelValues("wf", "build", status).(prometheus.Metric).Write(metric)
+	return metric.Histogram.GetSampleCount()
+}
+
+func TestRunTracker(t *testing.T) {
+	tracker := newRunTracker()
+	completed := stageSamples(v1alpha1.StatusCompleted)
+	failed := stageSamples(v1alpha1.StatusError)
+
+	tracker.observe(workflowRun("a", "", ""))
+	tracker.observe(workflowRun("b", v1alpha1.StatusRunning, v1alpha1.StatusRunning))
+	tracker.observe(workflowRun("c", v1alpha1.StatusError, v1alpha1.StatusError))
+	assert.Equal(t, map[string]float64{
+		v1alpha1.StatusPending: 1,
+		v1alpha1.StatusRunning: 1,
+		v1alpha1.StatusError:   1,
+	}, phases(tracker))
+	// Stages terminated before the WorkflowRun observed are not counted.
+	assert.Equal(t, failed, stageSamples(v1alpha1.StatusError))
+
+	tracker.observe(workflowRun("b", v1alpha1.StatusCompleted, v1alpha1.StatusCompleted))
+	tracker.observe(workflowRun("b", v1alpha1.StatusCompleted, v1alpha1.StatusCompleted))
+	tracker.forget(workflowRun("c", "", ""))
+	assert.Equal(t, map[string]float64{
+		v1alpha1.StatusPending:   1,
+		v1alpha1.StatusCompleted: 1,
+	}, phases(tracker))
+	assert.Equal(t, completed+1, stageSamples(v1alpha1.StatusCompleted))
+}
+
+func TestCheckHandler(t *testing.T) {
+	cases := map[string]struct {
+		check func() error
+		code  int
+	}{
+		"nil":    {check: nil, code: http.StatusOK},
+		"passed": {check: func() error { return nil }, code: http.StatusOK},
+		"failed": {check: func() error { return fmt.Errorf("not ready") }, code: http.StatusInternalServerError},
+	}
+
+	for name, c := range cases {
+		recorder := httptest.NewRecorder()
+		checkHandler(c.check).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, c.code, recorder.Code, name)
+	}
+}
@@ -0,0 +1,136 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+const (
+	// defaultText is the default template of Slack messages and email subjects.
+	defaultText = "{{.Summary}}"
+
+	// defaultEmailBody is the default template of email bodies.
+	defaultEmailBody = `{{.Summary}}
+
+Project: {{.Project}}
+Workflow: {{.Workflow}}
+WorkflowRun: {{.WorkflowRun}}
+Status: {{.Status}}
+{{- if .Reason}}
+Reason: {{.Reason}}
+{{- end}}
+{{- if .Message}}
+Message: {{.Message}}
+{{- end}}
+{{- if .FailedStages}}
+Failed stages: {{join .FailedStages ", "}}
+{{- end}}
+Duration: {{.Duration}}
+`
+)
+
+// Data is information of a WorkflowRun that notification templates are executed with. It's
+// also the default body of webhook notifications.
+type Data struct {
+	// Project the WorkflowRun belongs to.
+	Project string `json:"project"`
+	// Workflow of the WorkflowRun.
+	Workflow string `json:"workflow"`
+	// WorkflowRun name.
+	WorkflowRun string `json:"workflowRun"`
+	// Namespace of the WorkflowRun.
+	Namespace string `json:"namespace"`
+	// Trigger of the notification.
+	Trigger v1alpha1.NotificationTrigger `json:"trigger"`
+	// Summary is a one line description of the notification.
+	Summary string `json:"summary"`
+	// Status is the overall status of the WorkflowRun.
+	Status string `json:"status"`
+	// Reason of the overall status.
+	Reason string `json:"reason,omitempty"`
+	// Message of the overall status.
+	Message string `json:"message,omitempty"`
+	// FailedStages are names of failed stages.
+	FailedStages []string `json:"failedStages,omitempty"`
+	// StartTime is when the WorkflowRun started.
+	StartTime time.Time `json:"startTime"`
+	// Duration is how long the WorkflowRun has run, such as '1m30s'.
+	Duration string `json:"duration"`
+}
+
+// newData collects information of the WorkflowRun.
+func newData(wfr *v1alpha1.WorkflowRun) *Data {
+	overall := wfr.Status.Overall
+	data := &Data{
+		Project:     wfr.Labels[common.ProjectNameLabelName],
+		Workflow:    wfr.Spec.WorkflowRef.Name,
+		WorkflowRun: wfr.Name,
+		Namespace:   wfr.Namespace,
+		Status:      overall.Status,
+		Reason:      overall.Reason,
+		Message:     overall.Message,
+		StartTime:   overall.StartTime.Time,
+	}
+
+	for stage, status := range wfr.Status.Stages {
+		if status.Status.Status == v1alpha1.StatusError {
+			data.FailedStages = append(data.FailedStages, stage)
+		}
+	}
+	sort.Strings(data.FailedStages)
+
+	end := time.Now()
+	if !overall.CompletionTime.IsZero() {
+		end = overall.CompletionTime.Time
+	}
+	if !overall.StartTime.IsZero() {
+		data.Duration = end.Sub(overall.StartTime.Time).Round(time.Second).String()
+	}
+
+	return data
+}
+
+// setTrigger sets trigger of the notification and summarizes it.
+func (d *Data) setTrigger(trigger v1alpha1.NotificationTrigger) {
+	d.Trigger = trigger
+	d.Summary = fmt.Sprintf("[%s/%s] WorkflowRun %s %s", d.Project, d.Workflow, d.WorkflowRun, describe(trigger))
+}
+
+// describe describes the trigger in messages.
+func describe(trigger v1alpha1.NotificationTrigger) string {
+	switch trigger {
+	case v1alpha1.NotificationTriggerFailed:
+		return "failed"
+	case v1alpha1.NotificationTriggerFixed:
+		return "is fixed"
+	case v1alpha1.NotificationTriggerCompleted:
+		return "completed"
+	case v1alpha1.NotificationTriggerWaiting:
+		return "is waiting for approval"
+	}
+	return fmt.Sprintf("is %s", trigger)
+}
+
+// render executes the template with the data, the default template is used if it's empty.
+func render(text, defaultText string, data *Data) (string, error) {
+	if text == "" {
+		text = defaultText
+	}
+	t, err := template.New("notification").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse template error: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("execute template error: %v", err)
+	}
+	return buf.String(), nil
+}
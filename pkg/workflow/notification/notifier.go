@@ -0,0 +1,226 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+)
+
+// retryInterval is the interval between retries of sending a notification, it grows linearly
+// with the number of attempts.
+var retryInterval = 5 * time.Second
+
+// Notifier sends notifications when overall status of WorkflowRuns transitions, receivers are
+// configured in the Workflow and its Project.
+type Notifier struct {
+	client   clientset.Interface
+	recorder record.EventRecorder
+	senders  map[v1alpha1.NotificationType]sender
+}
+
+// NewNotifier creates a notifier.
+func NewNotifier(client clientset.Interface) *Notifier {
+	return &Notifier{
+		client:   client,
+		recorder: common.GetEventRecorder(client, common.EventSourceWfrController),
+		senders: map[v1alpha1.NotificationType]sender{
+			v1alpha1.NotificationTypeWebhook: &webhookSender{},
+			v1alpha1.NotificationTypeSlack:   &slackSender{},
+			v1alpha1.NotificationTypeEmail:   &emailSender{},
+		},
+	}
+}
+
+// Notify sends notifications if overall status of the WorkflowRun transitioned since last
+// notified. The status is marked as notified before sending, so notifications are sent at
+// most once for each transition even if the WorkflowRun is observed repeatedly. Sending is
+// performed asynchronously and results are recorded as WorkflowRun events. Error is returned
+// if the status can't be marked, notifications should be retried in this case.
+func (n *Notifier) Notify(wfr *v1alpha1.WorkflowRun) error {
+	status := wfr.Status.Overall.Status
+	if status == "" || status == v1alpha1.StatusPending || status == wfr.Status.Notified {
+		return nil
+	}
+
+	triggers := n.triggers(wfr)
+	var receivers []v1alpha1.NotificationItem
+	if len(triggers) > 0 {
+		var err error
+		receivers, err = n.receivers(wfr)
+		if err != nil {
+			return fmt.Errorf("get notification receivers error: %v", err)
+		}
+	}
+
+	marked, err := n.mark(wfr, status)
+	if err != nil {
+		return fmt.Errorf("mark WorkflowRun notified error: %v", err)
+	}
+	if !marked {
+		return nil
+	}
+
+	data := newData(wfr)
+	for _, r := range receivers {
+		trigger, ok := match(r.Triggers, triggers)
+		if !ok {
+			continue
+		}
+		d := *data
+		d.setTrigger(trigger)
+		go n.send(wfr, r, &d)
+	}
+	return nil
+}
+
+// triggers gets triggers of the current overall status transition of the WorkflowRun.
+func (n *Notifier) triggers(wfr *v1alpha1.WorkflowRun) []v1alpha1.NotificationTrigger {
+	switch wfr.Status.Overall.Status {
+	case v1alpha1.StatusError:
+		return []v1alpha1.NotificationTrigger{v1alpha1.NotificationTriggerFailed}
+	case v1alpha1.StatusWaiting:
+		return []v1alpha1.NotificationTrigger{v1alpha1.NotificationTriggerWaiting}
+	case v1alpha1.StatusCompleted:
+		// Fixed is preferred to Completed, since it tells more.
+		if n.previousFailed(wfr) {
+			return []v1alpha1.NotificationTrigger{v1alpha1.NotificationTriggerFixed, v1alpha1.NotificationTriggerCompleted}
+		}
+		return []v1alpha1.NotificationTrigger{v1alpha1.NotificationTriggerCompleted}
+	}
+
+	return nil
+}
+
+// previousFailed checks whether the latest terminated WorkflowRun of the same Workflow created
+// before the WorkflowRun failed.
+func (n *Notifier) previousFailed(wfr *v1alpha1.WorkflowRun) bool {
+	selector := labels.Set{common.WorkflowNameLabelName: wfr.Spec.WorkflowRef.Name}.AsSelector().String()
+	wfrs, err := n.client.CycloneV1alpha1().WorkflowRuns(wfr.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		log.WithField("wfr", wfr.Name).Warning("List WorkflowRuns error: ", err)
+		return false
+	}
+
+	var previous *v1alpha1.WorkflowRun
+	for i, w := range wfrs.Items {
+		if w.Name == wfr.Name || !wfr.CreationTimestamp.After(w.CreationTimestamp.Time) {
+			continue
+		}
+		if w.Status.Overall.Status != v1alpha1.StatusCompleted && w.Status.Overall.Status != v1alpha1.StatusError {
+			continue
+		}
+		if previous == nil || previous.CreationTimestamp.Before(&w.CreationTimestamp) {
+			previous = &wfrs.Items[i]
+		}
+	}
+
+	return previous != nil && previous.Status.Overall.Status == v1alpha1.StatusError
+}
+
+// receivers gets notification receivers configured in the Workflow and its Project.
+func (n *Notifier) receivers(wfr *v1alpha1.WorkflowRun) ([]v1alpha1.NotificationItem, error) {
+	var receivers []v1alpha1.NotificationItem
+	namespace := wfr.Spec.WorkflowRef.Namespace
+	if namespace == "" {
+		namespace = wfr.Namespace
+	}
+	wf, err := n.client.CycloneV1alpha1().Workflows(namespace).Get(wfr.Spec.WorkflowRef.Name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		receivers = append(receivers, wf.Spec.Notifications...)
+	}
+
+	project, ok := wfr.Labels[common.ProjectNameLabelName]
+	if !ok {
+		return receivers, nil
+	}
+	p, err := n.client.CycloneV1alpha1().Projects(wfr.Namespace).Get(project, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		receivers = append(receivers, p.Spec.Notifications...)
+	}
+
+	return receivers, nil
+}
+
+// mark marks the status as notified in the WorkflowRun. It returns false if the status has
+// already been marked, for example, by a previous observation of the WorkflowRun.
+func (n *Notifier) mark(wfr *v1alpha1.WorkflowRun, status string) (bool, error) {
+	marked := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := n.client.CycloneV1alpha1().WorkflowRuns(wfr.Namespace).Get(wfr.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if latest.Status.Overall.Status != status || latest.Status.Notified == status {
+			return nil
+		}
+
+		latest.Status.Notified = status
+		if _, err = n.client.CycloneV1alpha1().WorkflowRuns(wfr.Namespace).Update(latest); err != nil {
+			return err
+		}
+		marked = true
+		return nil
+	})
+
+	return marked, err
+}
+
+// send sends the notification to the receiver, it's retried on failure, and the result is
+// recorded as an event of the WorkflowRun.
+func (n *Notifier) send(wfr *v1alpha1.WorkflowRun, receiver v1alpha1.NotificationItem, data *Data) {
+	s, ok := n.senders[receiver.Type]
+	if !ok {
+		n.recorder.Eventf(wfr, corev1.EventTypeWarning, "NotificationFailed", "Notification '%s' has unknown type '%s'", receiver.Name, receiver.Type)
+		return
+	}
+
+	var err error
+	for i := 0; i <= controller.Config.Notification.RetryCount; i++ {
+		if i > 0 {
+			time.Sleep(retryInterval * time.Duration(i))
+		}
+		if err = s.send(&receiver, data); err == nil {
+			break
+		}
+		log.WithField("wfr", wfr.Name).
+			WithField("notification", receiver.Name).
+			WithField("attempt", i+1).
+			Warning("Send notification error: ", err)
+	}
+
+	if err != nil {
+		n.recorder.Eventf(wfr, corev1.EventTypeWarning, "NotificationFailed", "Send notification '%s' for %s error: %v", receiver.Name, data.Trigger, err)
+		return
+	}
+	n.recorder.Eventf(wfr, corev1.EventTypeNormal, "NotificationSent", "Notification '%s' sent for %s", receiver.Name, data.Trigger)
+}
+
+// match finds the first of the triggers that the receiver is interested in.
+func match(interested, triggers []v1alpha1.NotificationTrigger) (v1alpha1.NotificationTrigger, bool) {
+	for _, t := range triggers {
+		for _, i := range interested {
+			if t == i {
+				return t, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,150 @@
+package notification
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+)
+
+type fakeSender struct {
+	sent chan *Data
+	errs []error
+}
+
+func (s *fakeSender) send(receiver *v1alpha1.NotificationItem, data *Data) error {
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return err
+	}
+	s.sent <- data
+	return nil
+}
+
+func newWorkflowRun(name, status string, created time.Time) *v1alpha1.WorkflowRun {
+	return &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.Time{Time: created},
+			Labels: map[string]string{
+				common.WorkflowNameLabelName: "wf1",
+				common.ProjectNameLabelName:  "p1",
+			},
+		},
+		Spec: v1alpha1.WorkflowRunSpec{
+			WorkflowRef: &corev1.ObjectReference{Name: "wf1"},
+		},
+		Status: v1alpha1.WorkflowRunStatus{
+			Overall: v1alpha1.Status{Status: status},
+		},
+	}
+}
+
+func TestNotify(t *testing.T) {
+	now := time.Now()
+	wfr := newWorkflowRun("wf1-2", v1alpha1.StatusCompleted, now)
+	client := fake.NewSimpleClientset(
+		newWorkflowRun("wf1-0", v1alpha1.StatusCompleted, now.Add(-2*time.Hour)),
+		newWorkflowRun("wf1-1", v1alpha1.StatusError, now.Add(-time.Hour)),
+		wfr,
+		&v1alpha1.Workflow{
+			ObjectMeta: metav1.ObjectMeta{Name: "wf1", Namespace: "default"},
+			Spec: v1alpha1.WorkflowSpec{
+				Notifications: []v1alpha1.NotificationItem{
+					{Name: "fixed", Type: v1alpha1.NotificationTypeWebhook, Triggers: []v1alpha1.NotificationTrigger{v1alpha1.NotificationTriggerFailed, v1alpha1.NotificationTriggerFixed}},
+				},
+			},
+		},
+		&v1alpha1.Project{
+			ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"},
+			Spec: v1alpha1.ProjectSpec{
+				Notifications: []v1alpha1.NotificationItem{
+					{Name: "completed", Type: v1alpha1.NotificationTypeWebhook, Triggers: []v1alpha1.NotificationTrigger{v1alpha1.NotificationTriggerCompleted}},
+					{Name: "waiting", Type: v1alpha1.NotificationTypeWebhook, Triggers: []v1alpha1.NotificationTrigger{v1alpha1.NotificationTriggerWaiting}},
+				},
+			},
+		},
+	)
+
+	s := &fakeSender{sent: make(chan *Data, 10)}
+	recorder := record.NewFakeRecorder(10)
+	n := &Notifier{
+		client:   client,
+		recorder: recorder,
+		senders:  map[v1alpha1.NotificationType]sender{v1alpha1.NotificationTypeWebhook: s},
+	}
+
+	n.Notify(wfr)
+	triggers := map[v1alpha1.NotificationTrigger]bool{}
+	for i := 0; i < 2; i++ {
+		triggers[(<-s.sent).Trigger] = true
+		assert.Contains(t, <-recorder.Events, "NotificationSent")
+	}
+	assert.Equal(t, map[v1alpha1.NotificationTrigger]bool{
+		v1alpha1.NotificationTriggerFixed:     true,
+		v1alpha1.NotificationTriggerCompleted: true,
+	}, triggers)
+
+	latest, err := client.CycloneV1alpha1().WorkflowRuns("default").Get("wf1-2", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha1.StatusCompleted, latest.Status.Notified)
+
+	// Notifications are not sent again for the same status.
+	n.Notify(wfr)
+	assert.Equal(t, 0, len(s.sent))
+}
+
+func TestSendRetry(t *testing.T) {
+	preInterval, preConfig := retryInterval, controller.Config.Notification
+	defer func() {
+		retryInterval, controller.Config.Notification = preInterval, preConfig
+	}()
+	retryInterval = time.Millisecond
+	controller.Config.Notification.RetryCount = 1
+
+	s := &fakeSender{sent: make(chan *Data, 1), errs: []error{errors.New("unavailable")}}
+	recorder := record.NewFakeRecorder(10)
+	n := &Notifier{
+		recorder: recorder,
+		senders:  map[v1alpha1.NotificationType]sender{v1alpha1.NotificationTypeSlack: s},
+	}
+	wfr := newWorkflowRun("wf1-1", v1alpha1.StatusError, time.Now())
+	receiver := v1alpha1.NotificationItem{Name: "slack", Type: v1alpha1.NotificationTypeSlack}
+
+	n.send(wfr, receiver, &Data{Trigger: v1alpha1.NotificationTriggerFailed})
+	assert.Equal(t, v1alpha1.NotificationTriggerFailed, (<-s.sent).Trigger)
+	assert.Equal(t, "Normal NotificationSent Notification 'slack' sent for Failed", <-recorder.Events)
+
+	s.errs = []error{errors.New("unavailable"), errors.New("unavailable")}
+	n.send(wfr, receiver, &Data{Trigger: v1alpha1.NotificationTriggerFailed})
+	assert.Equal(t, "Warning NotificationFailed Send notification 'slack' for Failed error: unavailable", <-recorder.Events)
+}
+
+func TestNewData(t *testing.T) {
+	start := time.Now().Add(-time.Minute)
+	wfr := newWorkflowRun("wf1-1", v1alpha1.StatusError, start)
+	wfr.Status.Overall.StartTime = metav1.Time{Time: start}
+	wfr.Status.Overall.CompletionTime = metav1.Time{Time: start.Add(90 * time.Second)}
+	wfr.Status.Stages = map[string]*v1alpha1.StageStatus{
+		"test":  {Status: v1alpha1.Status{Status: v1alpha1.StatusError}},
+		"build": {Status: v1alpha1.Status{Status: v1alpha1.StatusCompleted}},
+	}
+
+	data := newData(wfr)
+	data.setTrigger(v1alpha1.NotificationTriggerFailed)
+	assert.Equal(t, "p1", data.Project)
+	assert.Equal(t, []string{"test"}, data.FailedStages)
+	assert.Equal(t, "1m30s", data.Duration)
+	assert.Equal(t, "[p1/wf1] WorkflowRun wf1-1 failed", data.Summary)
+}
@@ -0,0 +1,158 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+)
+
+// requestTimeout is the timeout of webhook requests.
+const requestTimeout = 30 * time.Second
+
+var httpClient = &http.Client{Timeout: requestTimeout}
+
+// sender sends notifications to a type of receivers.
+type sender interface {
+	send(receiver *v1alpha1.NotificationItem, data *Data) error
+}
+
+// webhookSender sends notifications to generic HTTP webhooks.
+type webhookSender struct{}
+
+func (s *webhookSender) send(receiver *v1alpha1.NotificationItem, data *Data) error {
+	webhook := receiver.Webhook
+	if webhook == nil || webhook.URL == "" {
+		return fmt.Errorf("webhook url not set")
+	}
+
+	var body string
+	if webhook.Body == "" {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		body = string(b)
+	} else {
+		var err error
+		if body, err = render(webhook.Body, "", data); err != nil {
+			return err
+		}
+	}
+
+	method := webhook.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, webhook.URL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if webhook.Body == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range webhook.Headers {
+		req.Header.Set(k, v)
+	}
+
+	return do(req)
+}
+
+// slackSender sends notifications to Slack compatible incoming webhooks.
+type slackSender struct{}
+
+// slackMessage is the payload of Slack incoming webhooks.
+type slackMessage struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel,omitempty"`
+}
+
+func (s *slackSender) send(receiver *v1alpha1.NotificationItem, data *Data) error {
+	slack := receiver.Slack
+	if slack == nil || slack.URL == "" {
+		return fmt.Errorf("slack webhook url not set")
+	}
+
+	text, err := render(slack.Text, defaultText, data)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(&slackMessage{Text: text, Channel: slack.Channel})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, slack.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return do(req)
+}
+
+// do sends the request and checks the response status.
+func do(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		content, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, content)
+	}
+	return nil
+}
+
+// emailSender sends notifications by email via the SMTP server configured.
+type emailSender struct{}
+
+func (s *emailSender) send(receiver *v1alpha1.NotificationItem, data *Data) error {
+	email := receiver.Email
+	if email == nil || len(email.To) == 0 {
+		return fmt.Errorf("email recipients not set")
+	}
+	config := controller.Config.Notification.SMTP
+	if config.Address == "" || config.From == "" {
+		return fmt.Errorf("smtp server not configured")
+	}
+
+	subject, err := render(email.Subject, defaultText, data)
+	if err != nil {
+		return err
+	}
+	body, err := render(email.Body, defaultEmailBody, data)
+	if err != nil {
+		return err
+	}
+
+	msg := &bytes.Buffer{}
+	fmt.Fprintf(msg, "From: %s\r\n", config.From)
+	fmt.Fprintf(msg, "To: %s\r\n", strings.Join(email.To, ", "))
+	fmt.Fprintf(msg, "Subject: %s\r\n", strings.Replace(subject, "\n", " ", -1))
+	fmt.Fprintf(msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(msg, "Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.Replace(body, "\n", "\r\n", -1))
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		host, _, err := net.SplitHostPort(config.Address)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", config.Username, config.Password, host)
+	}
+
+	return smtp.SendMail(config.Address, auth, config.From, email.To, msg.Bytes())
+}
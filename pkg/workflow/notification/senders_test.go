@@ -0,0 +1,160 @@
+package notification
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/controller"
+)
+
+type request struct {
+	method string
+	header http.Header
+	body   string
+}
+
+func httpStub(status int) (*httptest.Server, <-chan request) {
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- request{method: r.Method, header: r.Header, body: string(body)}
+		w.WriteHeader(status)
+	}))
+	return server, requests
+}
+
+// smtpStub starts a SMTP server which accepts one email.
+func smtpStub(t *testing.T) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := make(chan string, 1)
+	go func() {
+		defer l.Close()
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conn := textproto.NewConn(c)
+		defer conn.Close()
+
+		conn.PrintfLine("220 stub")
+		for {
+			line, err := conn.ReadLine()
+			if err != nil {
+				return
+			}
+			switch {
+			case line == "DATA":
+				conn.PrintfLine("354 go ahead")
+				msg, err := conn.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				messages <- string(msg)
+				conn.PrintfLine("250 ok")
+			case line == "QUIT":
+				conn.PrintfLine("221 bye")
+				return
+			default:
+				conn.PrintfLine("250 ok")
+			}
+		}
+	}()
+
+	return l.Addr().String(), messages
+}
+
+func testData() *Data {
+	data := &Data{
+		Project:      "p1",
+		Workflow:     "wf1",
+		WorkflowRun:  "wf1-abc",
+		Status:       v1alpha1.StatusError,
+		FailedStages: []string{"build", "test"},
+		Duration:     "1m30s",
+	}
+	data.setTrigger(v1alpha1.NotificationTriggerFailed)
+	return data
+}
+
+func TestWebhookSender(t *testing.T) {
+	server, requests := httpStub(http.StatusOK)
+	defer server.Close()
+
+	s := &webhookSender{}
+	err := s.send(&v1alpha1.NotificationItem{Webhook: &v1alpha1.WebhookReceiver{URL: server.URL}}, testData())
+	assert.Nil(t, err)
+	r := <-requests
+	assert.Equal(t, http.MethodPost, r.method)
+	assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+	data := &Data{}
+	assert.Nil(t, json.Unmarshal([]byte(r.body), data))
+	assert.Equal(t, "[p1/wf1] WorkflowRun wf1-abc failed", data.Summary)
+
+	err = s.send(&v1alpha1.NotificationItem{Webhook: &v1alpha1.WebhookReceiver{
+		URL:     server.URL,
+		Method:  http.MethodPut,
+		Headers: map[string]string{"X-Token": "token"},
+		Body:    `{{.WorkflowRun}} {{.Trigger}}: {{join .FailedStages ","}}`,
+	}}, testData())
+	assert.Nil(t, err)
+	r = <-requests
+	assert.Equal(t, http.MethodPut, r.method)
+	assert.Equal(t, "token", r.header.Get("X-Token"))
+	assert.Equal(t, "wf1-abc Failed: build,test", r.body)
+
+	failed, _ := httpStub(http.StatusInternalServerError)
+	defer failed.Close()
+	err = s.send(&v1alpha1.NotificationItem{Webhook: &v1alpha1.WebhookReceiver{URL: failed.URL}}, testData())
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, s.send(&v1alpha1.NotificationItem{}, testData()))
+}
+
+func TestSlackSender(t *testing.T) {
+	server, requests := httpStub(http.StatusOK)
+	defer server.Close()
+
+	s := &slackSender{}
+	err := s.send(&v1alpha1.NotificationItem{Slack: &v1alpha1.SlackReceiver{URL: server.URL, Channel: "#ci"}}, testData())
+	assert.Nil(t, err)
+	msg := &slackMessage{}
+	assert.Nil(t, json.Unmarshal([]byte((<-requests).body), msg))
+	assert.Equal(t, &slackMessage{Text: "[p1/wf1] WorkflowRun wf1-abc failed", Channel: "#ci"}, msg)
+
+	err = s.send(&v1alpha1.NotificationItem{Slack: &v1alpha1.SlackReceiver{URL: server.URL, Text: "{{.Invalid"}}, testData())
+	assert.NotNil(t, err)
+}
+
+func TestEmailSender(t *testing.T) {
+	pre := controller.Config.Notification
+	defer func() {
+		controller.Config.Notification = pre
+	}()
+
+	s := &emailSender{}
+	receiver := &v1alpha1.NotificationItem{Email: &v1alpha1.EmailReceiver{To: []string{"dev@example.com"}}}
+	assert.NotNil(t, s.send(receiver, testData()))
+
+	addr, messages := smtpStub(t)
+	controller.Config.Notification.SMTP = controller.SMTPConfig{Address: addr, From: "cyclone@example.com"}
+	assert.Nil(t, s.send(receiver, testData()))
+
+	msg := <-messages
+	assert.Contains(t, msg, "To: dev@example.com\n")
+	assert.Contains(t, msg, "Subject: [p1/wf1] WorkflowRun wf1-abc failed\n")
+	assert.Contains(t, msg, "Failed stages: build, test\n")
+	assert.True(t, strings.HasSuffix(msg, "Duration: 1m30s\n"))
+}
@@ -0,0 +1,39 @@
+/*
+Package resolver implements the resolver side of the contract between Cyclone and resource
+resolvers, so that resolvers of custom General resources can be built in Go against a stable
+interface instead of shell scripts.
+
+A resolver is a container image run by Cyclone for each resource used in a stage. Input
+resources are pulled by resolvers run as init containers, and output resources are pushed by
+resolvers run as sidecars. The contract is:
+
+Command. The only argument is the command, 'pull' or 'push'. Other arguments print the usage.
+
+Parameters. Parameters of the resource, merged with those given in the WorkflowRun, are passed
+as environment variables, for example, GIT_URL for Git resources. WORKFLOWRUN_NAME is the name
+of the WorkflowRun, and WORKDIR is the workspace, '/workspace' by default.
+
+Data. Resource data is in the 'data' directory of the workspace. For pull, the resolver writes
+data there, and Cyclone mounts it to workload containers. For push, Cyclone collects outputs
+of the workload there.
+
+Lock. Data of a resource can be shared by stages of a WorkflowRun, for example, when it's in a
+persistent PVC, so only one resolver should pull it. A resolver pulls only if there is no data,
+and it holds the lock file '<WORKDIR>/<WORKFLOWRUN_NAME>-pulling.lock' while pulling. Other
+resolvers wait until the lock file is removed, then use the data pulled.
+
+Notify. Output resolvers start along with the workload, so they must wait for the file
+'<WORKDIR>/notify/ok', which is created by the coordinator once outputs are collected.
+
+Result. Resolvers report metadata of the resolved resource, such as the commit SHA of a Git
+resource or the digest of an image, as a JSON object of strings written to the termination
+message file of the container, '/dev/termination-log' by default. It's recorded in status of
+the container by Kubernetes.
+
+Resolvers implement the Resolver interface and call Main in their main function:
+
+	func main() {
+		resolver.Main(&myResolver{})
+	}
+*/
+package resolver
@@ -0,0 +1,97 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Lock is the lock of pulling shared resource data.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock acquires the lock to pull data to the data directory. nil lock is returned if
+// the data has been pulled by others, after waiting for them to finish. The lock file exists
+// while data is being pulled, so it's compatible with shell resolvers, which check existence
+// of the lock file.
+func AcquireLock(lockFile, dataDir string, timeout, interval time.Duration) (*Lock, error) {
+	if exists(dataDir) || exists(lockFile) {
+		return nil, waitPulled(lockFile, dataDir, timeout, interval)
+	}
+
+	f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err != syscall.EWOULDBLOCK {
+			return nil, fmt.Errorf("lock %s error: %v", lockFile, err)
+		}
+		return nil, waitPulled(lockFile, dataDir, timeout, interval)
+	}
+
+	lock := &Lock{file: f}
+	// Data may have been pulled by others just before the lock is acquired.
+	if exists(dataDir) {
+		lock.Release()
+		return nil, nil
+	}
+	return lock, nil
+}
+
+// Release removes the lock file and unlocks it.
+func (l *Lock) Release() {
+	if err := os.Remove(l.file.Name()); err != nil {
+		log.Warning("Remove lock file error: ", err)
+	}
+	syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	l.file.Close()
+}
+
+// waitPulled waits until the lock file is removed by others, and checks the data is pulled.
+func waitPulled(lockFile, dataDir string, timeout, interval time.Duration) error {
+	log.Info("Data is being pulled by others, wait it to be ready")
+	err := waitFor(func() bool { return !exists(lockFile) }, timeout, interval)
+	if err != nil {
+		return fmt.Errorf("wait for lock file %s to be removed: %v", lockFile, err)
+	}
+	if !exists(dataDir) {
+		return fmt.Errorf("data not pulled by others, lock file %s removed without data", lockFile)
+	}
+	return nil
+}
+
+// WaitNotify waits until the notify file is created, 0 timeout means waiting forever.
+func WaitNotify(notifyFile string, timeout, interval time.Duration) error {
+	err := waitFor(func() bool { return exists(notifyFile) }, timeout, interval)
+	if err != nil {
+		return fmt.Errorf("wait for notify file %s: %v", notifyFile, err)
+	}
+	return nil
+}
+
+// waitFor checks the condition every interval until it's true, or timeout if timeout is not 0.
+func waitFor(condition func() bool, timeout, interval time.Duration) error {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(timeout)
+	}
+	for !condition() {
+		select {
+		case <-deadline:
+			return fmt.Errorf("timeout after %s", timeout)
+		case <-time.After(interval):
+		}
+	}
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
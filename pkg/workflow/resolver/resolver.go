@@ -0,0 +1,231 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+const (
+	// PullCommand pulls the resource to the data directory.
+	PullCommand = common.ResourcePullCommand
+	// PushCommand pushes data in the data directory.
+	PushCommand = common.ResourcePushCommand
+
+	// EnvWorkdir is the environment variable of the workspace.
+	EnvWorkdir = "WORKDIR"
+	// EnvResultPath is the environment variable to override path of the result file.
+	EnvResultPath = "RESULT_PATH"
+
+	// DefaultResultPath is the default termination message path of containers.
+	DefaultResultPath = "/dev/termination-log"
+)
+
+// Resolver resolves a kind of resource.
+type Resolver interface {
+	// Pull pulls the resource to the data directory, and returns metadata of the resource pulled.
+	Pull(ctx *Context) (Result, error)
+	// Push pushes data in the data directory, and returns metadata of the resource pushed.
+	Push(ctx *Context) (Result, error)
+}
+
+// Context is the context of a resolver command, it's parsed from environment variables.
+type Context struct {
+	// Command is PullCommand or PushCommand.
+	Command string
+	// Workdir is the workspace of the resolver.
+	Workdir string
+	// WorkflowRun is name of the WorkflowRun.
+	WorkflowRun string
+	// Params are all environment variables, including parameters of the resource.
+	Params map[string]string
+}
+
+// NewContext creates a context for the command from environment variables in the form of
+// 'key=value', such as os.Environ().
+func NewContext(command string, environ []string) *Context {
+	params := make(map[string]string)
+	for _, kv := range environ {
+		if i := strings.Index(kv, "="); i > 0 {
+			params[kv[:i]] = kv[i+1:]
+		}
+	}
+
+	workdir := params[EnvWorkdir]
+	if workdir == "" {
+		workdir = common.ResolverDefaultWorkspacePath
+	}
+	return &Context{
+		Command:     command,
+		Workdir:     workdir,
+		WorkflowRun: params[common.EnvWorkflowrunName],
+		Params:      params,
+	}
+}
+
+// DataDir is the directory of resource data.
+func (c *Context) DataDir() string {
+	return filepath.Join(c.Workdir, "data")
+}
+
+// NotifyFile is the file created by the coordinator when output data is ready.
+func (c *Context) NotifyFile() string {
+	return filepath.Join(c.Workdir, common.ResolverNotifyDir, "ok")
+}
+
+// LockFile is the file to lock pulling of the resource shared by stages of the WorkflowRun.
+func (c *Context) LockFile() string {
+	return filepath.Join(c.Workdir, c.WorkflowRun+"-pulling.lock")
+}
+
+// Param gets the parameter, or the default value if it's not set.
+func (c *Context) Param(name, defaultValue string) string {
+	if v, ok := c.Params[name]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+// RequiredParam gets the parameter, it returns error if the parameter is not set or empty.
+func (c *Context) RequiredParam(name string) (string, error) {
+	v := c.Params[name]
+	if v == "" {
+		return "", fmt.Errorf("parameter %s is required", name)
+	}
+	return v, nil
+}
+
+// BoolParam gets the parameter as a bool, or the default value if it's not set or empty.
+func (c *Context) BoolParam(name string, defaultValue bool) (bool, error) {
+	v := c.Params[name]
+	if v == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("parameter %s should be a bool, but got '%s'", name, v)
+	}
+	return b, nil
+}
+
+// IntParam gets the parameter as an integer, or the default value if it's not set or empty.
+func (c *Context) IntParam(name string, defaultValue int) (int, error) {
+	v := c.Params[name]
+	if v == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %s should be an integer, but got '%s'", name, v)
+	}
+	return n, nil
+}
+
+// Options configures how commands are run.
+type Options struct {
+	// NotifyTimeout is the maximum time to wait for output data to be ready before push, 0
+	// means waiting forever.
+	NotifyTimeout time.Duration
+	// LockTimeout is the maximum time to wait for other resolvers to pull the shared data, 0
+	// means waiting forever.
+	LockTimeout time.Duration
+	// PollInterval is the interval to check the notify file and the lock file.
+	PollInterval time.Duration
+	// ResultPath is the file to write result, it's RESULT_PATH or DefaultResultPath if empty.
+	ResultPath string
+}
+
+// DefaultOptions are options used by Main.
+var DefaultOptions = Options{
+	PollInterval: 3 * time.Second,
+}
+
+// Run runs the command of the resolver in the context. For pull, the resolver pulls only if
+// there is no data, and holds the lock while pulling. For push, it waits for the notify file
+// before pushing. Result of the resolver is written to the result file.
+func Run(r Resolver, ctx *Context, opts Options) error {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultOptions.PollInterval
+	}
+	if opts.ResultPath == "" {
+		opts.ResultPath = ctx.Param(EnvResultPath, DefaultResultPath)
+	}
+	logger := log.WithField("command", ctx.Command).WithField("workdir", ctx.Workdir)
+
+	var result Result
+	var err error
+	switch ctx.Command {
+	case PullCommand:
+		result, err = pull(r, ctx, opts)
+	case PushCommand:
+		logger.Info("Wait for output data to be ready")
+		if err = WaitNotify(ctx.NotifyFile(), opts.NotifyTimeout, opts.PollInterval); err != nil {
+			return err
+		}
+		result, err = r.Push(ctx)
+	default:
+		return fmt.Errorf("unknown command '%s'", ctx.Command)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.WithField("result", result).Info("Resource resolved")
+	// Failure to report result doesn't fail the resolver, the resource has been resolved.
+	if err := result.Write(opts.ResultPath); err != nil {
+		logger.Warning("Write result error: ", err)
+	}
+	return nil
+}
+
+// pull pulls the resource by the resolver following the lock protocol. If the data has been
+// pulled by others, result saved by them is returned.
+func pull(r Resolver, ctx *Context, opts Options) (Result, error) {
+	lock, err := AcquireLock(ctx.LockFile(), ctx.DataDir(), opts.LockTimeout, opts.PollInterval)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		log.Info("Data has been pulled by others, use it directly")
+		return ReadResult(savedResultPath(ctx))
+	}
+	defer lock.Release()
+
+	result, err := r.Pull(ctx)
+	if err != nil {
+		// Remove partial data, so that it's not used by others.
+		os.RemoveAll(ctx.DataDir())
+		return nil, err
+	}
+	// Save result for resolvers sharing the data.
+	if err := result.Save(savedResultPath(ctx)); err != nil {
+		log.Warning("Save result error: ", err)
+	}
+	return result, nil
+}
+
+// savedResultPath is path of result saved by the resolver that pulled the shared data.
+func savedResultPath(ctx *Context) string {
+	return filepath.Join(ctx.Workdir, ctx.WorkflowRun+"-result.json")
+}
+
+// Main runs the resolver with the command given in arguments and parameters in environment
+// variables, it exits with non-zero code if failed.
+func Main(r Resolver) {
+	if len(os.Args) != 2 || (os.Args[1] != PullCommand && os.Args[1] != PushCommand) {
+		fmt.Fprintf(os.Stderr, "Usage: %s pull|push\n", filepath.Base(os.Args[0]))
+		os.Exit(1)
+	}
+
+	if err := Run(r, NewContext(os.Args[1], os.Environ()), DefaultOptions); err != nil {
+		log.Errorf("Resolver %s error: %v", os.Args[1], err)
+		os.Exit(1)
+	}
+}
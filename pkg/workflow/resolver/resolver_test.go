@@ -0,0 +1,181 @@
+package resolver
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResolver struct {
+	pulled int
+	pushed int
+	result Result
+	err    error
+}
+
+func (r *fakeResolver) Pull(ctx *Context) (Result, error) {
+	r.pulled++
+	if err := os.MkdirAll(ctx.DataDir(), 0755); err != nil {
+		return nil, err
+	}
+	return r.result, r.err
+}
+
+func (r *fakeResolver) Push(ctx *Context) (Result, error) {
+	r.pushed++
+	return r.result, r.err
+}
+
+func newTestContext(t *testing.T, command string) (*Context, func()) {
+	dir, err := ioutil.TempDir("", "resolver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := NewContext(command, []string{"WORKDIR=" + dir, "WORKFLOWRUN_NAME=wfr", "RESULT_PATH=" + filepath.Join(dir, "termination-log")})
+	return ctx, func() { os.RemoveAll(dir) }
+}
+
+func TestNewContext(t *testing.T) {
+	ctx := NewContext(PullCommand, []string{"WORKFLOWRUN_NAME=wfr", "GIT_DEPTH=1", "GIT_LFS=true", "EMPTY=", "INVALID"})
+	assert.Equal(t, "/workspace", ctx.Workdir)
+	assert.Equal(t, "wfr", ctx.WorkflowRun)
+	assert.Equal(t, "/workspace/data", ctx.DataDir())
+	assert.Equal(t, "/workspace/notify/ok", ctx.NotifyFile())
+	assert.Equal(t, "/workspace/wfr-pulling.lock", ctx.LockFile())
+
+	assert.Equal(t, "", ctx.Param("EMPTY", "default"))
+	assert.Equal(t, "default", ctx.Param("MISSING", "default"))
+	_, err := ctx.RequiredParam("EMPTY")
+	assert.Error(t, err)
+
+	depth, err := ctx.IntParam("GIT_DEPTH", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, depth)
+	_, err = ctx.IntParam("GIT_LFS", 0)
+	assert.Error(t, err)
+
+	lfs, err := ctx.BoolParam("GIT_LFS", false)
+	assert.Nil(t, err)
+	assert.True(t, lfs)
+	submodules, err := ctx.BoolParam("EMPTY", true)
+	assert.Nil(t, err)
+	assert.True(t, submodules)
+}
+
+func TestRunPull(t *testing.T) {
+	ctx, clean := newTestContext(t, PullCommand)
+	defer clean()
+
+	r := &fakeResolver{result: Result{ResultRevision: "abc"}}
+	assert.Nil(t, Run(r, ctx, Options{PollInterval: time.Millisecond}))
+	assert.Equal(t, 1, r.pulled)
+	assert.False(t, exists(ctx.LockFile()))
+	result, err := ReadResult(ctx.Params[EnvResultPath])
+	assert.Nil(t, err)
+	assert.Equal(t, Result{ResultRevision: "abc"}, result)
+
+	// Data pulled is shared, the second pull reports the saved result.
+	os.Remove(ctx.Params[EnvResultPath])
+	other := &fakeResolver{}
+	assert.Nil(t, Run(other, ctx, Options{PollInterval: time.Millisecond}))
+	assert.Equal(t, 0, other.pulled)
+	result, err = ReadResult(ctx.Params[EnvResultPath])
+	assert.Nil(t, err)
+	assert.Equal(t, Result{ResultRevision: "abc"}, result)
+}
+
+func TestRunPullError(t *testing.T) {
+	ctx, clean := newTestContext(t, PullCommand)
+	defer clean()
+
+	r := &fakeResolver{err: errors.New("pull error")}
+	assert.Error(t, Run(r, ctx, Options{PollInterval: time.Millisecond}))
+	assert.False(t, exists(ctx.DataDir()))
+	assert.False(t, exists(ctx.LockFile()))
+}
+
+func TestRunPullWaitLock(t *testing.T) {
+	ctx, clean := newTestContext(t, PullCommand)
+	defer clean()
+
+	if err := ioutil.WriteFile(ctx.LockFile(), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeResolver{}
+	err := Run(r, ctx, Options{PollInterval: time.Millisecond, LockTimeout: 10 * time.Millisecond})
+	assert.Error(t, err)
+	assert.Equal(t, 0, r.pulled)
+
+	// Lock file removed without data.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		os.Remove(ctx.LockFile())
+	}()
+	err = Run(r, ctx, Options{PollInterval: time.Millisecond})
+	assert.Error(t, err)
+	assert.Equal(t, 0, r.pulled)
+}
+
+func TestRunPush(t *testing.T) {
+	ctx, clean := newTestContext(t, PushCommand)
+	defer clean()
+
+	r := &fakeResolver{result: Result{ResultDigest: "sha256:abc"}}
+	err := Run(r, ctx, Options{PollInterval: time.Millisecond, NotifyTimeout: 10 * time.Millisecond})
+	assert.Error(t, err)
+	assert.Equal(t, 0, r.pushed)
+
+	if err := os.MkdirAll(filepath.Dir(ctx.NotifyFile()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(ctx.NotifyFile(), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, Run(r, ctx, Options{PollInterval: time.Millisecond}))
+	assert.Equal(t, 1, r.pushed)
+	result, err := ReadResult(ctx.Params[EnvResultPath])
+	assert.Nil(t, err)
+	assert.Equal(t, Result{ResultDigest: "sha256:abc"}, result)
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	ctx, clean := newTestContext(t, "help")
+	defer clean()
+
+	assert.Error(t, Run(&fakeResolver{}, ctx, Options{}))
+}
+
+func TestResult(t *testing.T) {
+	dir, err := ioutil.TempDir("", "result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "result")
+
+	// Empty result is not written, so that error messages are kept.
+	assert.Nil(t, Result{}.Write(path))
+	assert.False(t, exists(path))
+
+	large := Result{"data": strings.Repeat("x", maxResultSize)}
+	assert.Error(t, large.Write(path))
+
+	assert.Nil(t, Result{ResultSize: "1024"}.Write(path))
+	result, err := ReadResult(path)
+	assert.Nil(t, err)
+	assert.Equal(t, Result{ResultSize: "1024"}, result)
+
+	result, err = ReadResult(filepath.Join(dir, "missing"))
+	assert.Nil(t, err)
+	assert.Equal(t, Result{}, result)
+
+	assert.Equal(t, Result{ResultRevision: "abc"}, ParseResult(`{"revision":"abc"}`))
+	assert.Nil(t, ParseResult("clone error"))
+	assert.Nil(t, ParseResult("{}"))
+}
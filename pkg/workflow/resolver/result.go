@@ -0,0 +1,78 @@
+package resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Well-known keys of results, resolvers can also report other keys.
+const (
+	// ResultRevision is the revision resolved, for example, commit SHA of a Git resource.
+	ResultRevision = "revision"
+	// ResultDigest is the digest of the data, for example, digest of an image.
+	ResultDigest = "digest"
+	// ResultSize is the size of the data in bytes.
+	ResultSize = "size"
+)
+
+// maxResultSize is the maximum size of termination messages in Kubernetes.
+const maxResultSize = 4096
+
+// Result is metadata of the resource resolved.
+type Result map[string]string
+
+// Write writes the result to the termination message file, it's skipped if the result is empty.
+func (r Result) Write(path string) error {
+	if len(r) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxResultSize {
+		return fmt.Errorf("result is too large, %d bytes exceeds %d bytes", len(data), maxResultSize)
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Save saves the result to the file, an empty object is saved for empty result.
+func (r Result) Save(path string) error {
+	if r == nil {
+		r = Result{}
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// ReadResult reads the result from the file, empty result is returned if the file doesn't exist.
+func ReadResult(path string) (Result, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Result{}, nil
+		}
+		return nil, err
+	}
+
+	result := Result{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse result %s error: %v", path, err)
+	}
+	return result, nil
+}
+
+// ParseResult parses the result reported in a termination message, nil is returned if the
+// message is not a result, for example, an error message.
+func ParseResult(message string) Result {
+	result := Result{}
+	if err := json.Unmarshal([]byte(message), &result); err != nil || len(result) == 0 {
+		return nil
+	}
+	return result
+}
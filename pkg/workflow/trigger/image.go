@@ -0,0 +1,157 @@
+package trigger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/registry"
+)
+
+// MatchTag checks whether the tag matches the pattern, the whole tag should be matched.
+func MatchTag(pattern, tag string) bool {
+	if pattern == "" {
+		return true
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		log.WithField("pattern", pattern).Warning("Invalid tag pattern: ", err)
+		return false
+	}
+	return re.MatchString(tag)
+}
+
+// FireImageTrigger starts a WorkflowRun by the image trigger for the pushed image, and records it
+// in status of the trigger. Name of the WorkflowRun is derived from the pushed image, so that the
+// same push received repeatedly, for example, from both notifications and polling, only starts
+// one WorkflowRun.
+func FireImageTrigger(client clientset.Interface, wft *v1alpha1.WorkflowTrigger, host string, push registry.Push) (string, error) {
+	image := fmt.Sprintf("%s/%s:%s", host, push.Repository, push.Tag)
+	wfr := newImageWorkflowRun(wft, image, push)
+	_, err := client.CycloneV1alpha1().WorkflowRuns(wft.Namespace).Create(wfr)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	}
+	if err == nil {
+		log.WithField("wft", wft.Name).Infof("WorkflowRun %s started for %s", wfr.Name, image)
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := client.CycloneV1alpha1().WorkflowTriggers(wft.Namespace).Get(wft.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if latest.Status.LastWorkflowRun != wfr.Name {
+			latest.Status.Count++
+			latest.Status.LastFireTime = &metav1.Time{Time: time.Now()}
+			latest.Status.LastWorkflowRun = wfr.Name
+		}
+		latest.Status.LastError = ""
+		latest.Status.ImageTags = setImageTag(latest.Status.ImageTags, v1alpha1.ImageTag{Tag: push.Tag, Digest: push.Digest})
+		_, err = client.CycloneV1alpha1().WorkflowTriggers(wft.Namespace).Update(latest)
+		return err
+	})
+	return wfr.Name, err
+}
+
+// newImageWorkflowRun creates the WorkflowRun started by the image trigger, the pushed image is
+// passed to the Image resource.
+func newImageWorkflowRun(wft *v1alpha1.WorkflowTrigger, image string, push registry.Push) *v1alpha1.WorkflowRun {
+	h := fnv.New32a()
+	h.Write([]byte(push.Repository + ":" + push.Tag + "@" + push.Digest))
+
+	wfr := newWorkflowRun(wft, fmt.Sprintf("%s-%08x", wft.Name, h.Sum32()))
+	wfr.Spec.TriggeredBy = &v1alpha1.TriggeredBy{
+		Type:    v1alpha1.TriggerTypeImage,
+		Trigger: wft.Name,
+		Image:   image,
+		EventID: push.EventID,
+	}
+
+	resource := wft.Spec.Image.Resource
+	wfr.Spec.Resources = setParameter(wfr.Spec.Resources, resource, "IMAGE", image)
+	wfr.Spec.Resources = setParameter(wfr.Spec.Resources, resource, "TAG", push.Tag)
+	return wfr
+}
+
+// setImageTag updates digest of the tag, or adds it if not observed before.
+func setImageTag(tags []v1alpha1.ImageTag, tag v1alpha1.ImageTag) []v1alpha1.ImageTag {
+	for i := range tags {
+		if tags[i].Tag == tag.Tag {
+			tags[i].Digest = tag.Digest
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+// pollImage lists tags of the repository, and fires the trigger for new tags or tags pointing to
+// new digests. Tags observed in the first poll are recorded as a baseline.
+func pollImage(client clientset.Interface, wft *v1alpha1.WorkflowTrigger) error {
+	spec := wft.Spec.Image
+	in, err := integration(client, wft.Namespace, spec.Integration)
+	if err != nil {
+		return err
+	}
+	if in.Type != api.DockerRegistry || in.DockerRegistry == nil {
+		return fmt.Errorf("type of integration '%s' is not %s", spec.Integration, api.DockerRegistry)
+	}
+
+	rc := registry.NewClient(in.DockerRegistry)
+	tags, err := rc.Tags(spec.Repository)
+	if err != nil {
+		return err
+	}
+
+	observed := make(map[string]string)
+	for _, t := range wft.Status.ImageTags {
+		observed[t.Tag] = t.Digest
+	}
+	var current []v1alpha1.ImageTag
+	var pushes []registry.Push
+	for _, tag := range tags {
+		if !MatchTag(spec.Tag, tag) {
+			continue
+		}
+		digest, err := rc.Digest(spec.Repository, tag)
+		if err != nil {
+			return err
+		}
+		current = append(current, v1alpha1.ImageTag{Tag: tag, Digest: digest})
+		if d, ok := observed[tag]; !ok || d != digest {
+			pushes = append(pushes, registry.Push{Repository: spec.Repository, Tag: tag, Digest: digest})
+		}
+	}
+
+	// Tags observed the first time are recorded as the baseline without firing the trigger.
+	if wft.Status.ImageTags != nil {
+		host := registry.Host(in.DockerRegistry.Server)
+		for _, push := range pushes {
+			if _, err := FireImageTrigger(client, wft, host, push); err != nil {
+				return err
+			}
+		}
+	} else if current == nil {
+		// Empty baseline is distinguished from no baseline.
+		current = []v1alpha1.ImageTag{}
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := client.CycloneV1alpha1().WorkflowTriggers(wft.Namespace).Get(wft.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest.Status.ImageTags = current
+		_, err = client.CycloneV1alpha1().WorkflowTriggers(wft.Namespace).Update(latest)
+		return err
+	})
+}
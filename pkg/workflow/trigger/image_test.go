@@ -0,0 +1,59 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/registry"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+func TestMatchTag(t *testing.T) {
+	assert.True(t, MatchTag("", "latest"))
+	assert.True(t, MatchTag(`1\.\d+`, "1.12"))
+	assert.False(t, MatchTag(`1\.\d+`, "1.12-alpine"))
+	assert.False(t, MatchTag("(", "("))
+}
+
+func TestNewImageWorkflowRun(t *testing.T) {
+	wft := &v1alpha1.WorkflowTrigger{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "golang",
+			Namespace: "cyclone-system",
+			Labels:    map[string]string{common.ProjectNameLabelName: "p"},
+		},
+		Spec: v1alpha1.WorkflowTriggerSpec{
+			Type:  v1alpha1.TriggerTypeImage,
+			Image: v1alpha1.ImageTrigger{Resource: "base-image"},
+			WorkflowRunSpec: v1alpha1.WorkflowRunSpec{
+				Resources: []v1alpha1.ParameterConfig{{
+					Name:       "base-image",
+					Parameters: []v1alpha1.ParameterItem{{Name: "IMAGE", Value: "golang:latest"}},
+				}},
+			},
+		},
+	}
+	push := registry.Push{Repository: "library/golang", Tag: "1.12", Digest: "sha256:a", EventID: "e"}
+
+	wfr := newImageWorkflowRun(wft, "docker.io/library/golang:1.12", push)
+	assert.Equal(t, wfr.Name, newImageWorkflowRun(wft, "docker.io/library/golang:1.12", push).Name)
+	assert.Equal(t, "p", wfr.Labels[common.ProjectNameLabelName])
+	assert.Equal(t, string(v1alpha1.TriggerTypeImage), wfr.Labels[common.TriggerTypeLabelName])
+	assert.Equal(t, &v1alpha1.TriggeredBy{
+		Type:    v1alpha1.TriggerTypeImage,
+		Trigger: "golang",
+		Image:   "docker.io/library/golang:1.12",
+		EventID: "e",
+	}, wfr.Spec.TriggeredBy)
+	assert.Equal(t, []v1alpha1.ParameterItem{
+		{Name: "IMAGE", Value: "docker.io/library/golang:1.12"},
+		{Name: "TAG", Value: "1.12"},
+	}, wfr.Spec.Resources[0].Parameters)
+	assert.Equal(t, "golang:latest", wft.Spec.Resources[0].Parameters[0].Value)
+
+	push.Digest = "sha256:b"
+	assert.NotEqual(t, wfr.Name, newImageWorkflowRun(wft, "docker.io/library/golang:1.12", push).Name)
+}
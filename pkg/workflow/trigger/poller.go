@@ -0,0 +1,139 @@
+package trigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	servercommon "github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+// Poller polls sources of WorkflowTriggers that have poll interval configured, such as image
+// triggers and SCM poll triggers. Only one poller should run in the cluster, so that triggers
+// are not polled repeatedly.
+type Poller struct {
+	client clientset.Interface
+	// nextPolls are times to poll next time for each trigger, keyed by namespace and name.
+	nextPolls map[string]time.Time
+}
+
+// NewPoller creates a trigger poller.
+func NewPoller(client clientset.Interface) *Poller {
+	return &Poller{
+		client:    client,
+		nextPolls: make(map[string]time.Time),
+	}
+}
+
+// Run checks WorkflowTriggers every interval until stopCh is closed, and polls sources of them
+// when their poll intervals elapsed.
+func (p *Poller) Run(interval time.Duration, stopCh <-chan struct{}) {
+	wait.Until(p.pollAll, interval, stopCh)
+}
+
+// pollSource gets the poll interval of the WorkflowTrigger, and the function to poll its source.
+// Zero interval is returned if the trigger isn't polled.
+func pollSource(wft *v1alpha1.WorkflowTrigger) (time.Duration, func(clientset.Interface, *v1alpha1.WorkflowTrigger) error) {
+	switch wft.Spec.Type {
+	case v1alpha1.TriggerTypeImage:
+		return time.Duration(wft.Spec.Image.PollIntervalSeconds) * time.Second, pollImage
+	case v1alpha1.TriggerTypeSCMPoll:
+		return time.Duration(wft.Spec.SCMPoll.PollIntervalSeconds) * time.Second, pollSCM
+	default:
+		return 0, nil
+	}
+}
+
+func (p *Poller) pollAll() {
+	wfts, err := p.client.CycloneV1alpha1().WorkflowTriggers(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		log.Error("List WorkflowTriggers error: ", err)
+		return
+	}
+
+	now := time.Now()
+	polled := make(map[string]time.Time)
+	for i := range wfts.Items {
+		wft := &wfts.Items[i]
+		interval, poll := pollSource(wft)
+		if wft.Spec.Disabled || interval <= 0 {
+			continue
+		}
+
+		key := wft.Namespace + "/" + wft.Name
+		if next, ok := p.nextPolls[key]; ok && now.Before(next) {
+			polled[key] = next
+			continue
+		}
+		polled[key] = now.Add(interval)
+		if err := poll(p.client, wft); err != nil {
+			log.WithField("wft", key).Warningf("Poll %s trigger error: %v", wft.Spec.Type, err)
+		}
+	}
+	// Triggers deleted or not polled anymore are dropped.
+	p.nextPolls = polled
+}
+
+// integration gets spec of the integration in the namespace.
+func integration(client clientset.Interface, namespace, name string) (*api.IntegrationSpec, error) {
+	secret, err := client.CoreV1().Secrets(namespace).Get(servercommon.IntegrationSecret(name), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	spec := &api.IntegrationSpec{}
+	if err := json.Unmarshal(secret.Data[servercommon.SecretKeyIntegration], spec); err != nil {
+		return nil, fmt.Errorf("unmarshal integration %s error: %v", name, err)
+	}
+	return spec, nil
+}
+
+// newWorkflowRun creates a WorkflowRun from the spec of the trigger, labeled with the project,
+// workflow and trigger it belongs to.
+func newWorkflowRun(wft *v1alpha1.WorkflowTrigger, name string) *v1alpha1.WorkflowRun {
+	wfr := &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: wft.Namespace,
+			Labels:    make(map[string]string),
+		},
+		Spec: *wft.Spec.WorkflowRunSpec.DeepCopy(),
+	}
+	if project, ok := wft.Labels[common.ProjectNameLabelName]; ok {
+		wfr.Labels[common.ProjectNameLabelName] = project
+	}
+	if wfr.Spec.WorkflowRef != nil {
+		wfr.Labels[common.WorkflowNameLabelName] = wfr.Spec.WorkflowRef.Name
+	}
+	wfr.Labels[common.WorkflowTriggerNameLabelName] = wft.Name
+	wfr.Labels[common.TriggerTypeLabelName] = string(wft.Spec.Type)
+	return wfr
+}
+
+// setParameter sets the parameter of the resource or stage, it overrides the existing one.
+func setParameter(configs []v1alpha1.ParameterConfig, name, key, value string) []v1alpha1.ParameterConfig {
+	for i := range configs {
+		if configs[i].Name != name {
+			continue
+		}
+		for j := range configs[i].Parameters {
+			if configs[i].Parameters[j].Name == key {
+				configs[i].Parameters[j].Value = value
+				return configs
+			}
+		}
+		configs[i].Parameters = append(configs[i].Parameters, v1alpha1.ParameterItem{Name: key, Value: value})
+		return configs
+	}
+	return append(configs, v1alpha1.ParameterConfig{
+		Name:       name,
+		Parameters: []v1alpha1.ParameterItem{{Name: key, Value: value}},
+	})
+}
@@ -0,0 +1,92 @@
+package trigger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/server/biz/scm"
+)
+
+// pollSCM gets head revision of the branch, and fires the trigger if it changed since last poll.
+// The revision observed in the first poll is recorded as a baseline.
+func pollSCM(client clientset.Interface, wft *v1alpha1.WorkflowTrigger) error {
+	spec := wft.Spec.SCMPoll
+	// WorkflowTriggers may be created without validation of Cyclone server.
+	if err := scm.ValidateRepository(spec.Repository); err != nil {
+		return err
+	}
+	in, err := integration(client, wft.Namespace, spec.Integration)
+	if err != nil {
+		return err
+	}
+	if in.Type != api.SCM || in.SCM == nil {
+		return fmt.Errorf("type of integration '%s' is not %s", spec.Integration, api.SCM)
+	}
+
+	revision, err := scm.HeadRevision(in.SCM, spec.Repository, spec.Branch)
+	if err != nil {
+		return err
+	}
+	if revision == wft.Status.LastRevision {
+		return nil
+	}
+
+	var created string
+	if wft.Status.LastRevision != "" {
+		wfr := newSCMWorkflowRun(wft, revision)
+		_, err := client.CycloneV1alpha1().WorkflowRuns(wft.Namespace).Create(wfr)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		if err == nil {
+			log.WithField("wft", wft.Name).Infof("WorkflowRun %s started for revision %s", wfr.Name, revision)
+		}
+		created = wfr.Name
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := client.CycloneV1alpha1().WorkflowTriggers(wft.Namespace).Get(wft.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if created != "" && latest.Status.LastWorkflowRun != created {
+			latest.Status.Count++
+			latest.Status.LastFireTime = &metav1.Time{Time: time.Now()}
+			latest.Status.LastWorkflowRun = created
+			latest.Status.LastError = ""
+		}
+		latest.Status.LastRevision = revision
+		_, err = client.CycloneV1alpha1().WorkflowTriggers(wft.Namespace).Update(latest)
+		return err
+	})
+}
+
+// newSCMWorkflowRun creates the WorkflowRun started by the SCM poll trigger for the revision, the
+// revision found is passed to the Git resource, so that the commit polled is built even if the
+// branch moves on before the WorkflowRun starts.
+func newSCMWorkflowRun(wft *v1alpha1.WorkflowTrigger, revision string) *v1alpha1.WorkflowRun {
+	h := fnv.New32a()
+	h.Write([]byte(wft.Spec.SCMPoll.Branch + "@" + revision))
+
+	wfr := newWorkflowRun(wft, fmt.Sprintf("%s-%08x", wft.Name, h.Sum32()))
+	wfr.Spec.TriggeredBy = &v1alpha1.TriggeredBy{
+		Type:    v1alpha1.TriggerTypeSCMPoll,
+		Trigger: wft.Name,
+		Commit:  revision,
+	}
+
+	spec := wft.Spec.SCMPoll
+	if spec.Resource != "" && revision != "" {
+		wfr.Spec.Resources = setParameter(wfr.Spec.Resources, spec.Resource, "GIT_REVISION", revision)
+	}
+	return wfr
+}
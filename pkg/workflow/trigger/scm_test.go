@@ -0,0 +1,166 @@
+package trigger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	servercommon "github.com/caicloud/cyclone/pkg/server/common"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@cyclone.dev",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@cyclone.dev")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v error: %v, %s", args, err, out)
+	}
+}
+
+// serveGit serves Git repositories in the directory by smart HTTP protocol.
+func serveGit(t *testing.T, dir string) *httptest.Server {
+	out, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Fatalf("git --exec-path error: %v", err)
+	}
+	return httptest.NewServer(&cgi.Handler{
+		Path: filepath.Join(strings.TrimSpace(string(out)), "git-http-backend"),
+		Env:  []string{"GIT_PROJECT_ROOT=" + dir, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+}
+
+func TestPollSCM(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir, err := ioutil.TempDir("", "scmtrigger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	server := serveGit(t, dir)
+	defer server.Close()
+
+	remote := filepath.Join(dir, "remote.git")
+	work := filepath.Join(dir, "work")
+	git(t, dir, "init", "--bare", remote)
+	git(t, dir, "clone", remote, work)
+	git(t, work, "checkout", "-b", "master")
+	git(t, work, "commit", "--allow-empty", "-m", "first")
+	git(t, work, "push", "origin", "master")
+
+	namespace := servercommon.TenantNamespace("t")
+	data, _ := json.Marshal(&api.IntegrationSpec{
+		Type:              api.SCM,
+		IntegrationSource: api.IntegrationSource{SCM: &api.SCMSource{Type: api.GitLab}},
+	})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: servercommon.IntegrationSecret("git"), Namespace: namespace},
+		Data:       map[string][]byte{servercommon.SecretKeyIntegration: data},
+	}
+	wft := &v1alpha1.WorkflowTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "poll", Namespace: namespace},
+		Spec: v1alpha1.WorkflowTriggerSpec{
+			Type: v1alpha1.TriggerTypeSCMPoll,
+			SCMPoll: v1alpha1.SCMPollTrigger{
+				Integration:         "git",
+				Repository:          server.URL + "/remote.git",
+				Branch:              "master",
+				PollIntervalSeconds: 60,
+				Resource:            "code",
+			},
+			WorkflowRunSpec: v1alpha1.WorkflowRunSpec{
+				WorkflowRef: &corev1.ObjectReference{Name: "wf"},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(secret, wft)
+
+	poll := func() *v1alpha1.WorkflowTrigger {
+		latest, err := client.CycloneV1alpha1().WorkflowTriggers(namespace).Get("poll", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Nil(t, pollSCM(client, latest))
+		latest, err = client.CycloneV1alpha1().WorkflowTriggers(namespace).Get("poll", metav1.GetOptions{})
+		assert.Nil(t, err)
+		return latest
+	}
+	runs := func() []v1alpha1.WorkflowRun {
+		wfrs, err := client.CycloneV1alpha1().WorkflowRuns(namespace).List(metav1.ListOptions{})
+		assert.Nil(t, err)
+		return wfrs.Items
+	}
+
+	// The first poll records the baseline.
+	latest := poll()
+	assert.NotEmpty(t, latest.Status.LastRevision)
+	assert.Empty(t, runs())
+
+	// Nothing changed.
+	baseline := latest.Status.LastRevision
+	latest = poll()
+	assert.Equal(t, baseline, latest.Status.LastRevision)
+	assert.Empty(t, runs())
+
+	// A new commit fires the trigger.
+	git(t, work, "commit", "--allow-empty", "-m", "second")
+	git(t, work, "push", "origin", "master")
+	latest = poll()
+	assert.NotEqual(t, baseline, latest.Status.LastRevision)
+	assert.Equal(t, 1, latest.Status.Count)
+
+	wfrs := runs()
+	if assert.Len(t, wfrs, 1) {
+		wfr := wfrs[0]
+		assert.Equal(t, latest.Status.LastWorkflowRun, wfr.Name)
+		assert.Equal(t, &v1alpha1.TriggeredBy{
+			Type:    v1alpha1.TriggerTypeSCMPoll,
+			Trigger: "poll",
+			Commit:  latest.Status.LastRevision,
+		}, wfr.Spec.TriggeredBy)
+		assert.Equal(t, "wf", wfr.Labels[common.WorkflowNameLabelName])
+		assert.Equal(t, []v1alpha1.ParameterConfig{{
+			Name:       "code",
+			Parameters: []v1alpha1.ParameterItem{{Name: "GIT_REVISION", Value: latest.Status.LastRevision}},
+		}}, wfr.Spec.Resources)
+	}
+}
+
+func TestPollSCMInvalidRepository(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scmtrigger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	injected := filepath.Join(dir, "injected")
+	wft := &v1alpha1.WorkflowTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "poll", Namespace: "default"},
+		Spec: v1alpha1.WorkflowTriggerSpec{
+			Type: v1alpha1.TriggerTypeSCMPoll,
+			SCMPoll: v1alpha1.SCMPollTrigger{
+				Integration:         "git",
+				Repository:          "--upload-pack=touch " + injected,
+				PollIntervalSeconds: 60,
+			},
+		},
+	}
+	assert.NotNil(t, pollSCM(fake.NewSimpleClientset(wft), wft))
+	_, err = os.Stat(injected)
+	assert.True(t, os.IsNotExist(err))
+}
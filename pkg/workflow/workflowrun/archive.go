@@ -0,0 +1,59 @@
+package workflowrun
+
+import (
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/coordinator/cycloneserver"
+	"github.com/caicloud/cyclone/pkg/workflow/history"
+)
+
+// serverArchiver archives summaries of WorkflowRuns to Cyclone server.
+type serverArchiver struct {
+	client clientset.Interface
+	server cycloneserver.Client
+}
+
+// NewArchiver creates an archiver that archives summaries of WorkflowRuns to Cyclone server.
+func NewArchiver(client clientset.Interface, server cycloneserver.Client) history.Archiver {
+	return &serverArchiver{
+		client: client,
+		server: server,
+	}
+}
+
+// Archive ...
+func (a *serverArchiver) Archive(wfr *v1alpha1.WorkflowRun) error {
+	return a.server.CreateWorkflowRunSummary(wfr, Summarize(a.client, wfr))
+}
+
+// Summarize summarizes the WorkflowRun for archive. Paths of output artifacts are got from the
+// Stages, they are omitted if the Stage can't be got.
+func Summarize(client clientset.Interface, wfr *v1alpha1.WorkflowRun) *api.WorkflowRunSummary {
+	summary := history.Summarize(wfr)
+	summary.PVC = GetExecutionContext(wfr).PVC
+	if summary.PVC == "" {
+		return summary
+	}
+
+	for name, stage := range summary.Stages {
+		stg, err := client.CycloneV1alpha1().Stages(wfr.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			log.WithField("wfr", wfr.Name).WithField("stg", name).Warning("Get stage error, artifacts omitted: ", err)
+			continue
+		}
+		if stg.Spec.Pod == nil {
+			continue
+		}
+		for _, artifact := range stg.Spec.Pod.Outputs.Artifacts {
+			stage.Artifacts = append(stage.Artifacts, common.ArtifactPath(wfr.Name, name, artifact.Name))
+		}
+		summary.Stages[name] = stage
+	}
+
+	return summary
+}
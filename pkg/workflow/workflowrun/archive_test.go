@@ -0,0 +1,60 @@
+package workflowrun
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+)
+
+func TestSummarize(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1alpha1.Stage{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "default"},
+		Spec: v1alpha1.StageSpec{Pod: &v1alpha1.PodWorkload{
+			Outputs: v1alpha1.Outputs{Artifacts: []v1alpha1.ArtifactItem{{Name: "bin", Path: "/workspace/bin"}}},
+		}},
+	})
+	wfr := &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wfr1",
+			Namespace: "default",
+			Labels: map[string]string{
+				common.ProjectNameLabelName:         "p1",
+				common.WorkflowTriggerNameLabelName: "nightly",
+			},
+		},
+		Spec: v1alpha1.WorkflowRunSpec{
+			WorkflowRef:      &corev1.ObjectReference{Name: "wf1"},
+			Stages:           []v1alpha1.ParameterConfig{{Name: "build", Parameters: []v1alpha1.ParameterItem{{Name: "a", Value: "b"}}}},
+			ExecutionContext: &v1alpha1.ExecutionContext{PVC: "pvc1"},
+		},
+		Status: v1alpha1.WorkflowRunStatus{
+			Overall: v1alpha1.Status{Status: v1alpha1.StatusCompleted},
+			Stages: map[string]*v1alpha1.StageStatus{
+				"build": {
+					Status:  v1alpha1.Status{Status: v1alpha1.StatusCompleted},
+					Outputs: []v1alpha1.KeyValue{{Key: "image", Value: "app:v1"}},
+				},
+				"test": {
+					Status: v1alpha1.Status{Status: v1alpha1.StatusCompleted},
+				},
+			},
+		},
+	}
+
+	summary := Summarize(client, wfr)
+	assert.Equal(t, "p1", summary.Project)
+	assert.Equal(t, "wf1", summary.Workflow)
+	assert.Equal(t, &v1alpha1.TriggeredBy{Type: v1alpha1.TriggerTypeCron, Trigger: "nightly"}, summary.TriggeredBy)
+	assert.Equal(t, "pvc1", summary.PVC)
+	assert.Equal(t, wfr.Spec.Stages, summary.Parameters)
+	assert.Equal(t, v1alpha1.StatusCompleted, summary.Status.Status)
+	assert.Equal(t, []v1alpha1.KeyValue{{Key: "image", Value: "app:v1"}}, summary.Stages["build"].Outputs)
+	assert.Equal(t, []string{"workflowruns/wfr1/stages/build/artifacts/bin"}, summary.Stages["build"].Artifacts)
+	assert.Equal(t, 0, len(summary.Stages["test"].Artifacts))
+}
@@ -1,6 +1,7 @@
 package workflowrun
 
 import (
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -20,6 +21,40 @@ type GCProcessor struct {
 	enabled  bool
 }
 
+// recoveries counts stages whose logs are being recovered for each WorkflowRun. Logs are recovered
+// by reading stage pods, so GC of the WorkflowRun waits for them to finish before deleting pods.
+var recoveries = struct {
+	sync.Mutex
+	items map[string]int
+}{items: make(map[string]int)}
+
+// StartLogRecovery marks logs of a stage in the WorkflowRun being recovered, GC of the WorkflowRun
+// is postponed until the returned function is called.
+func StartLogRecovery(wfr *v1alpha1.WorkflowRun) func() {
+	key := (&workflowRunItem{name: wfr.Name, namespace: wfr.Namespace}).String()
+	recoveries.Lock()
+	recoveries.items[key]++
+	recoveries.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			recoveries.Lock()
+			defer recoveries.Unlock()
+			if recoveries.items[key]--; recoveries.items[key] <= 0 {
+				delete(recoveries.items, key)
+			}
+		})
+	}
+}
+
+// recoveringLogs checks whether logs of the WorkflowRun are being recovered.
+func recoveringLogs(item *workflowRunItem) bool {
+	recoveries.Lock()
+	defer recoveries.Unlock()
+	return recoveries.items[item.String()] > 0
+}
+
 // NewGCProcessor create new GC processor.
 func NewGCProcessor(client clientset.Interface, enabled bool) *GCProcessor {
 	processor := &GCProcessor{
@@ -59,6 +94,11 @@ func (p *GCProcessor) Add(wfr *v1alpha1.WorkflowRun) {
 		Debug("Added to GCProcessor")
 }
 
+// Size returns number of WorkflowRuns waiting for GC.
+func (p *GCProcessor) Size() int {
+	return len(p.items)
+}
+
 // Enable the processor and start it.
 func (p *GCProcessor) Enable() {
 	if p.enabled {
@@ -83,6 +123,10 @@ func (p *GCProcessor) process() {
 	var expired []*workflowRunItem
 	for _, v := range p.items {
 		if v.expireTime.Before(time.Now()) {
+			if recoveringLogs(v) {
+				log.WithField("wfr", v.name).Debug("GC postponed since logs are being recovered")
+				continue
+			}
 			expired = append(expired, v)
 		}
 	}
@@ -149,6 +149,30 @@ func (s *GCProcessorSuite) TestProcess() {
 	assert.Nil(s.T(), s.processor.items["default:test1"])
 }
 
+func (s *GCProcessorSuite) TestProcessWaitLogRecovery() {
+	wfr := &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test1",
+			Namespace: "default",
+		},
+		Status: v1alpha1.WorkflowRunStatus{
+			Overall: v1alpha1.Status{
+				Status:             v1alpha1.StatusCompleted,
+				LastTransitionTime: metav1.Time{Time: time.Now().Add(-time.Hour)},
+			},
+		},
+	}
+	done := StartLogRecovery(wfr)
+	s.processor.Add(wfr)
+	s.processor.process()
+	assert.Equal(s.T(), "test1", s.processor.items["default:test1"].name)
+
+	done()
+	done()
+	s.processor.process()
+	assert.Nil(s.T(), s.processor.items["default:test1"])
+}
+
 func TestGCProcessorSuite(t *testing.T) {
 	suite.Run(t, new(GCProcessorSuite))
 }
@@ -0,0 +1,180 @@
+package workflowrun
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Parameters of Git resources, they are passed to the git resolver as environment variables.
+const (
+	// gitURLParameter is URL of the repository, HTTP(S) and SSH URLs are supported.
+	gitURLParameter = "GIT_URL"
+	// gitRevisionParameter is the revision to pull, it can be a branch, a tag, a commit SHA, or a
+	// ref such as 'refs/pull/1/head' and 'refs/pull/1/merge'.
+	gitRevisionParameter = "GIT_REVISION"
+	// gitTokenParameter is the token to access HTTP(S) repositories.
+	gitTokenParameter = "GIT_TOKEN"
+	// gitSSHKeyParameter is the private key to access SSH repositories, it usually refers the
+	// SSH key in a SCM integration secret.
+	gitSSHKeyParameter = "GIT_SSH_KEY"
+	// gitSSHKnownHostsParameter is known hosts of SSH repositories, host keys of SSH servers are
+	// checked against them.
+	gitSSHKnownHostsParameter = "GIT_SSH_KNOWN_HOSTS"
+	// gitSSHInsecureParameter is whether to skip checking host keys of SSH servers when known
+	// hosts are not given, it should only be set explicitly for trusted networks.
+	gitSSHInsecureParameter = "GIT_SSH_INSECURE_SKIP_HOST_KEY_CHECK"
+	// gitDepthParameter is depth of the clone, 0 means full history.
+	gitDepthParameter = "GIT_DEPTH"
+	// gitSubmodulesParameter is whether to init submodules recursively.
+	gitSubmodulesParameter = "GIT_SUBMODULES"
+	// gitLFSParameter is whether to pull Git LFS objects.
+	gitLFSParameter = "GIT_LFS"
+	// gitPushTagParameter is the tag to create on the head commit and push, for output resources.
+	gitPushTagParameter = "GIT_PUSH_TAG"
+	// gitPushBranchParameter is the branch to push the head commit to, for output resources.
+	gitPushBranchParameter = "GIT_PUSH_BRANCH"
+)
+
+var (
+	// scpLikeURL matches SSH URLs in scp-like syntax, for example, 'git@github.com:caicloud/cyclone.git'.
+	scpLikeURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/].*$`)
+	// commitSHA matches full or abbreviated commit SHAs.
+	commitSHA = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+	// invalidRefChars matches characters not allowed in ref names by 'git check-ref-format'.
+	invalidRefChars = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]`)
+)
+
+// isSSHGitURL checks whether the Git URL is a SSH URL.
+func isSSHGitURL(u string) bool {
+	return strings.HasPrefix(u, "ssh://") || scpLikeURL.MatchString(u)
+}
+
+// validateGitURL checks the Git URL is a HTTP(S) or SSH URL.
+func validateGitURL(u string) error {
+	if scpLikeURL.MatchString(u) {
+		return nil
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("invalid %s '%s': %v", gitURLParameter, u, err)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "ssh":
+	default:
+		return fmt.Errorf("invalid %s '%s': only HTTP(S) and SSH are supported", gitURLParameter, u)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid %s '%s': host is empty", gitURLParameter, u)
+	}
+	return nil
+}
+
+// validateRefName checks the ref name by rules of 'git check-ref-format', for example, branches,
+// tags or full refs like 'refs/pull/1/head'.
+func validateRefName(name string) error {
+	switch {
+	case name == "" || name == "@":
+		return fmt.Errorf("empty ref name")
+	case strings.HasPrefix(name, "-") || strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/"):
+		return fmt.Errorf("ref name '%s' can't begin with '-' or '/', or end with '/'", name)
+	case strings.HasSuffix(name, ".") || strings.HasSuffix(name, ".lock"):
+		return fmt.Errorf("ref name '%s' can't end with '.' or '.lock'", name)
+	case strings.Contains(name, "..") || strings.Contains(name, "//") || strings.Contains(name, "@{") || strings.Contains(name, "/."):
+		return fmt.Errorf("ref name '%s' can't contain '..', '//', '@{' or '/.'", name)
+	case strings.HasPrefix(name, "."):
+		return fmt.Errorf("ref name '%s' can't begin with '.'", name)
+	case invalidRefChars.MatchString(name):
+		return fmt.Errorf("ref name '%s' contains invalid characters", name)
+	}
+	return nil
+}
+
+// validateGitRevision checks the revision is a commit SHA or a valid ref name.
+func validateGitRevision(revision string) error {
+	if commitSHA.MatchString(revision) {
+		return nil
+	}
+	if err := validateRefName(revision); err != nil {
+		return fmt.Errorf("invalid %s: %v", gitRevisionParameter, err)
+	}
+	return nil
+}
+
+// validateGitParameters validates parameters of a Git resource before they are passed to the git
+// resolver, push indicates whether the resource is used as output.
+func validateGitParameters(params map[string]string, push bool) error {
+	u := params[gitURLParameter]
+	if u == "" {
+		return fmt.Errorf("%s is required", gitURLParameter)
+	}
+	if err := validateGitURL(u); err != nil {
+		return err
+	}
+
+	if isSSHGitURL(u) {
+		key := params[gitSSHKeyParameter]
+		if key == "" {
+			return fmt.Errorf("%s is required for SSH URL '%s'", gitSSHKeyParameter, u)
+		}
+		if !strings.Contains(key, "PRIVATE KEY-----") {
+			return fmt.Errorf("%s is not a PEM encoded private key", gitSSHKeyParameter)
+		}
+		if params[gitTokenParameter] != "" {
+			return fmt.Errorf("%s is only supported for HTTP(S) URLs", gitTokenParameter)
+		}
+		insecure, err := parseBoolParameter(params, gitSSHInsecureParameter)
+		if err != nil {
+			return err
+		}
+		if params[gitSSHKnownHostsParameter] == "" && !insecure {
+			return fmt.Errorf("%s is required for SSH URL '%s', or set %s to true to skip checking host keys",
+				gitSSHKnownHostsParameter, u, gitSSHInsecureParameter)
+		}
+	} else if params[gitSSHKeyParameter] != "" {
+		return fmt.Errorf("%s is only supported for SSH URLs", gitSSHKeyParameter)
+	}
+
+	if depth, ok := params[gitDepthParameter]; ok && depth != "" {
+		if n, err := strconv.Atoi(depth); err != nil || n < 0 {
+			return fmt.Errorf("invalid %s '%s': it should be a non-negative integer", gitDepthParameter, depth)
+		}
+	}
+	for _, p := range []string{gitSubmodulesParameter, gitLFSParameter} {
+		if _, err := parseBoolParameter(params, p); err != nil {
+			return err
+		}
+	}
+
+	if !push {
+		revision, ok := params[gitRevisionParameter]
+		if !ok {
+			return fmt.Errorf("%s is required", gitRevisionParameter)
+		}
+		return validateGitRevision(revision)
+	}
+
+	tag, branch := params[gitPushTagParameter], params[gitPushBranchParameter]
+	if (tag == "") == (branch == "") {
+		return fmt.Errorf("one of %s and %s is required to push", gitPushTagParameter, gitPushBranchParameter)
+	}
+	if err := validateRefName(tag + branch); err != nil {
+		return fmt.Errorf("invalid ref to push: %v", err)
+	}
+	return nil
+}
+
+// parseBoolParameter parses the boolean parameter, it's false if not set.
+func parseBoolParameter(params map[string]string, name string) (bool, error) {
+	v := params[name]
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s '%s': it should be true or false", name, v)
+	}
+	return b, nil
+}
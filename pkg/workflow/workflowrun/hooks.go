@@ -0,0 +1,189 @@
+package workflowrun
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+)
+
+// maxHookRetries is the maximum number of retries of hooks failed for a WorkflowRun, the
+// WorkflowRun is handled again when it's observed next time after retries are exhausted.
+const maxHookRetries = 5
+
+// Hook is performed when phase of a WorkflowRun transitions, for example, to send notifications
+// or update status of its Workflow. Hooks should be idempotent, since they may be performed
+// repeatedly for a phase when any hook fails.
+type Hook struct {
+	// Name of the hook, it's used in logs.
+	Name string
+	// Observe handles the WorkflowRun whose phase transitioned.
+	Observe func(wfr *v1alpha1.WorkflowRun) error
+	// Forget handles the deleted WorkflowRun, it's optional.
+	Forget func(wfr *v1alpha1.WorkflowRun) error
+}
+
+// hookItem is a WorkflowRun waiting for hooks.
+type hookItem struct {
+	wfr     *v1alpha1.WorkflowRun
+	deleted bool
+	// retries is the number of retries performed for the item.
+	retries int
+}
+
+// HookProcessor performs hooks of WorkflowRuns when their phases transition. Hooks may call
+// external services and list resources, so they are performed by workers of a rate limited
+// queue, rather than in handlers of informer events.
+type HookProcessor struct {
+	hooks []Hook
+	queue workqueue.RateLimitingInterface
+
+	lock sync.Mutex
+	// observed are phases of WorkflowRuns that hooks are performed successfully for.
+	observed map[types.UID]string
+	// items are latest WorkflowRuns waiting for hooks, keyed by namespace and name.
+	items map[string]*hookItem
+}
+
+// NewHookProcessor creates a processor to perform the hooks.
+func NewHookProcessor(hooks ...Hook) *HookProcessor {
+	return &HookProcessor{
+		hooks:    hooks,
+		queue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		observed: make(map[types.UID]string),
+		items:    make(map[string]*hookItem),
+	}
+}
+
+// Run starts workers to perform hooks until stopCh is closed.
+func (p *HookProcessor) Run(workers int, stopCh <-chan struct{}) {
+	defer p.queue.ShutDown()
+	for i := 0; i < workers; i++ {
+		go wait.Until(p.work, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+// Add adds the WorkflowRun to perform hooks, if its phase changed since hooks are performed
+// for it last time.
+func (p *HookProcessor) Add(wfr *v1alpha1.WorkflowRun) {
+	p.add(wfr, false)
+}
+
+// Delete adds the deleted WorkflowRun to perform hooks.
+func (p *HookProcessor) Delete(wfr *v1alpha1.WorkflowRun) {
+	p.add(wfr, true)
+}
+
+func (p *HookProcessor) add(wfr *v1alpha1.WorkflowRun, deleted bool) {
+	key, err := cache.MetaNamespaceKeyFunc(wfr)
+	if err != nil {
+		log.WithField("wfr", wfr.Name).Warning("Get key of WorkflowRun error: ", err)
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if deleted {
+		delete(p.observed, wfr.UID)
+	} else if observed, ok := p.observed[wfr.UID]; ok && observed == phase(wfr) {
+		return
+	}
+	p.items[key] = &hookItem{wfr: wfr, deleted: deleted}
+	p.queue.AddRateLimited(key)
+}
+
+// Size returns number of WorkflowRuns waiting for hooks.
+func (p *HookProcessor) Size() int {
+	return p.queue.Len()
+}
+
+func (p *HookProcessor) work() {
+	for p.next() {
+	}
+}
+
+func (p *HookProcessor) next() bool {
+	key, shutdown := p.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer p.queue.Done(key)
+
+	p.lock.Lock()
+	item, ok := p.items[key.(string)]
+	p.lock.Unlock()
+	if !ok {
+		p.queue.Forget(key)
+		return true
+	}
+
+	err := p.perform(item)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err != nil && item.retries < maxHookRetries {
+		log.WithField("wfr", key).Warning("Perform hooks error (will retry): ", err)
+		item.retries++
+		p.queue.AddRateLimited(key)
+		return true
+	}
+	if err != nil {
+		log.WithField("wfr", key).Error("Perform hooks error (gave up): ", err)
+	}
+	p.queue.Forget(key)
+
+	// WorkflowRuns added while performing hooks are kept, they have been queued again and will
+	// be handled later.
+	if p.items[key.(string)] != item {
+		return true
+	}
+	delete(p.items, key.(string))
+	if err == nil && !item.deleted {
+		p.observed[item.wfr.UID] = phase(item.wfr)
+	}
+	return true
+}
+
+// perform performs all hooks for the WorkflowRun, hooks failed don't stop others.
+func (p *HookProcessor) perform(item *hookItem) error {
+	var errs []string
+	for _, h := range p.hooks {
+		f := h.Observe
+		if item.deleted {
+			f = h.Forget
+		}
+		if f == nil {
+			continue
+		}
+		if err := f(item.wfr); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", h.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// phase gets the phase of the WorkflowRun, which consists of its overall status and statuses of
+// its stages, for example, 'Running;build=Completed,test=Running'.
+func phase(wfr *v1alpha1.WorkflowRun) string {
+	stages := make([]string, 0, len(wfr.Status.Stages))
+	for name, s := range wfr.Status.Stages {
+		if s != nil {
+			stages = append(stages, name+"="+s.Status.Status)
+		}
+	}
+	sort.Strings(stages)
+	return wfr.Status.Overall.Status + ";" + strings.Join(stages, ",")
+}
@@ -0,0 +1,92 @@
+package workflowrun
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+)
+
+func hookWorkflowRun(overall, stage string) *v1alpha1.WorkflowRun {
+	return &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "wfr", Namespace: "default", UID: types.UID("wfr")},
+		Status: v1alpha1.WorkflowRunStatus{
+			Overall: v1alpha1.Status{Status: overall},
+			Stages: map[string]*v1alpha1.StageStatus{
+				"build": {Status: v1alpha1.Status{Status: stage}},
+			},
+		},
+	}
+}
+
+func TestPhase(t *testing.T) {
+	wfr := hookWorkflowRun(v1alpha1.StatusRunning, v1alpha1.StatusCompleted)
+	wfr.Status.Stages["test"] = &v1alpha1.StageStatus{Status: v1alpha1.Status{Status: v1alpha1.StatusRunning}}
+	assert.Equal(t, "Running;build=Completed,test=Running", phase(wfr))
+}
+
+func TestHookProcessor(t *testing.T) {
+	var observed, forgotten []string
+	fail := 0
+	p := NewHookProcessor(Hook{
+		Name: "test",
+		Observe: func(wfr *v1alpha1.WorkflowRun) error {
+			observed = append(observed, phase(wfr))
+			if fail > 0 {
+				fail--
+				return fmt.Errorf("failed")
+			}
+			return nil
+		},
+		Forget: func(wfr *v1alpha1.WorkflowRun) error {
+			forgotten = append(forgotten, wfr.Name)
+			return nil
+		},
+	})
+	defer p.queue.ShutDown()
+
+	// Hooks are performed once for each phase.
+	p.Add(hookWorkflowRun(v1alpha1.StatusRunning, v1alpha1.StatusRunning))
+	p.next()
+	p.Add(hookWorkflowRun(v1alpha1.StatusRunning, v1alpha1.StatusRunning))
+	assert.Equal(t, 0, p.Size())
+	assert.Equal(t, []string{"Running;build=Running"}, observed)
+
+	// Stage transitions are also phase transitions.
+	p.Add(hookWorkflowRun(v1alpha1.StatusRunning, v1alpha1.StatusCompleted))
+	p.next()
+	assert.Equal(t, []string{"Running;build=Running", "Running;build=Completed"}, observed)
+
+	// Hooks failed are retried.
+	observed = nil
+	fail = 1
+	p.Add(hookWorkflowRun(v1alpha1.StatusCompleted, v1alpha1.StatusCompleted))
+	p.next()
+	p.next()
+	assert.Equal(t, []string{"Completed;build=Completed", "Completed;build=Completed"}, observed)
+	p.Add(hookWorkflowRun(v1alpha1.StatusCompleted, v1alpha1.StatusCompleted))
+	assert.Equal(t, 0, p.Size())
+
+	// Hooks are performed again after retries are exhausted, once the WorkflowRun is observed.
+	observed = nil
+	fail = maxHookRetries + 1
+	p.Add(hookWorkflowRun(v1alpha1.StatusError, v1alpha1.StatusError))
+	for i := 0; i <= maxHookRetries; i++ {
+		p.next()
+	}
+	assert.Equal(t, maxHookRetries+1, len(observed))
+	assert.Equal(t, 0, p.Size())
+	p.Add(hookWorkflowRun(v1alpha1.StatusError, v1alpha1.StatusError))
+	p.next()
+	assert.Equal(t, maxHookRetries+2, len(observed))
+
+	p.Delete(hookWorkflowRun(v1alpha1.StatusError, v1alpha1.StatusError))
+	p.next()
+	assert.Equal(t, []string{"wfr"}, forgotten)
+	assert.Empty(t, p.observed)
+	assert.Empty(t, p.items)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/history"
 )
 
 // LimitedQueues manages WorkflowRun queue for each Workflow. Queue for each Workflow is limited to
@@ -24,6 +25,8 @@ type LimitedQueues struct {
 	Queues map[string]*LimitedSortedQueue
 	// k8s client used to clean old WorkflowRun
 	Client clientset.Interface
+	// Archiver archives old WorkflowRun before it's deleted, nil means no archive.
+	Archiver history.Archiver
 }
 
 // NewLimitedQueues creates a limited queues for WorkflowRuns, and start auto scan.
@@ -70,6 +73,9 @@ func (w *LimitedQueues) AddOrRefresh(wfr *v1alpha1.WorkflowRun) {
 	for q.size > w.MaxQueueSize {
 		log.WithField("max", w.MaxQueueSize).Debug("Max WorkflowRun exceeded, delete the oldest one")
 		old := q.Pop()
+		if !w.archive(q, old) {
+			break
+		}
 		err := w.Client.CycloneV1alpha1().WorkflowRuns(old.namespace).Delete(old.wfr, &metav1.DeleteOptions{})
 		if err != nil && !errors.IsNotFound(err) {
 			log.WithField("wfr", old.wfr).Error("Delete old WorkflowRun error: ", err)
@@ -79,6 +85,47 @@ func (w *LimitedQueues) AddOrRefresh(wfr *v1alpha1.WorkflowRun) {
 	}
 }
 
+// archive archives the old WorkflowRun popped from the queue, it returns whether the WorkflowRun
+// can be deleted. If archive failed, the WorkflowRun is pushed back to the queue, and it will be
+// tried again when next WorkflowRun of the Workflow is added.
+func (w *LimitedQueues) archive(q *LimitedSortedQueue, old *Node) bool {
+	if w.Archiver == nil {
+		return true
+	}
+
+	wfr, err := w.Client.CycloneV1alpha1().WorkflowRuns(old.namespace).Get(old.wfr, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return true
+		}
+		log.WithField("wfr", old.wfr).Error("Get old WorkflowRun error: ", err)
+		q.PushOrRefresh(&v1alpha1.WorkflowRun{ObjectMeta: metav1.ObjectMeta{
+			Name:              old.wfr,
+			Namespace:         old.namespace,
+			CreationTimestamp: metav1.Unix(old.created, 0),
+		}})
+		return false
+	}
+
+	if err := w.Archiver.Archive(wfr); err != nil {
+		log.WithField("wfr", old.wfr).Error("Archive old WorkflowRun error, skip deleting it: ", err)
+		q.PushOrRefresh(wfr)
+		return false
+	}
+	return true
+}
+
+// Size returns total number of WorkflowRuns in all queues.
+func (w *LimitedQueues) Size() int {
+	size := 0
+	for _, q := range w.Queues {
+		q.lock.Lock()
+		size += q.size
+		q.lock.Unlock()
+	}
+	return size
+}
+
 // AutoScan scans all WorkflowRuns in the queues regularly, remove abnormal ones with old enough
 // refresh time.
 func (w *LimitedQueues) AutoScan() {
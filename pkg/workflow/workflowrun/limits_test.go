@@ -1,12 +1,14 @@
 package workflowrun
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
@@ -125,3 +127,54 @@ func (s *LimitQueuesSuite) TestAddOrRefresh() {
 func TestLimitQueuesSuite(t *testing.T) {
 	suite.Run(t, new(LimitQueuesSuite))
 }
+
+type fakeArchiver struct {
+	archived []string
+	err      error
+}
+
+func (a *fakeArchiver) Archive(wfr *v1alpha1.WorkflowRun) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.archived = append(a.archived, wfr.Name)
+	return nil
+}
+
+func TestAddOrRefreshArchive(t *testing.T) {
+	now := time.Now()
+	wfr := func(name string, created int) *v1alpha1.WorkflowRun {
+		return &v1alpha1.WorkflowRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.Time{Time: now.Add(time.Duration(created) * time.Second)},
+			},
+			Spec: v1alpha1.WorkflowRunSpec{
+				WorkflowRef: &corev1.ObjectReference{Namespace: "default", Name: "wf1"},
+			},
+		}
+	}
+	client := fake.NewSimpleClientset(wfr("wfr1", 1), wfr("wfr2", 2), wfr("wfr3", 3))
+	archiver := &fakeArchiver{err: fmt.Errorf("unavailable")}
+	queues := &LimitedQueues{
+		MaxQueueSize: 1,
+		Queues:       make(map[string]*LimitedSortedQueue),
+		Client:       client,
+		Archiver:     archiver,
+	}
+
+	queues.AddOrRefresh(wfr("wfr1", 1))
+	queues.AddOrRefresh(wfr("wfr2", 2))
+	// Archive failed, the old WorkflowRun is retained.
+	assert.Equal(t, 2, queues.Size())
+	_, err := client.CycloneV1alpha1().WorkflowRuns("default").Get("wfr1", metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	archiver.err = nil
+	queues.AddOrRefresh(wfr("wfr3", 3))
+	assert.Equal(t, 1, queues.Size())
+	assert.Equal(t, []string{"wfr1", "wfr2"}, archiver.archived)
+	_, err = client.CycloneV1alpha1().WorkflowRuns("default").Get("wfr1", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
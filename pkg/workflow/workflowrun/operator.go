@@ -17,6 +17,7 @@ import (
 	"github.com/caicloud/cyclone/pkg/k8s/clientset"
 	"github.com/caicloud/cyclone/pkg/workflow/common"
 	"github.com/caicloud/cyclone/pkg/workflow/controller"
+	"github.com/caicloud/cyclone/pkg/workflow/metrics"
 )
 
 // Operator is used to perform operations on a WorkflowRun instance, such
@@ -28,8 +29,16 @@ type Operator interface {
 	Update() error
 	// Update stage status.
 	UpdateStageStatus(stage string, status *v1alpha1.Status)
+	// Update running time of the stage.
+	UpdateStageRunningTime(stage string, runningTime metav1.Time)
 	// Update stage pod info.
 	UpdateStagePodInfo(stage string, podInfo *v1alpha1.PodInfo)
+	// Update stage logs status.
+	UpdateStageLogsStatus(stage string, logs *v1alpha1.LogsStatus)
+	// Update stage diagnostics.
+	UpdateStageDiagnostics(stage string, diagnostics *v1alpha1.StageDiagnostics)
+	// Update status of resources resolved in the stage.
+	UpdateStageResources(stage string, resources []v1alpha1.ResourceStatus)
 	// Decide overall status of the WorkflowRun from stage status.
 	OverallStatus() (*v1alpha1.Status, error)
 	// Garbage collection on the WorkflowRun based on GC policy configured
@@ -127,7 +136,8 @@ func (o *operator) Update() error {
 
 		// Apply changes to latest WorkflowRun
 		combined.Status.Cleaned = combined.Status.Cleaned || o.wfr.Status.Cleaned
-		combined.Status.Overall = *resolveStatus(&combined.Status.Overall, &o.wfr.Status.Overall)
+		overall := mergeTimes(*resolveStatus(&combined.Status.Overall, &o.wfr.Status.Overall), &o.wfr.Status.Overall)
+		combined.Status.Overall = mergeTimes(overall, &latest.Status.Overall)
 		for stage, status := range o.wfr.Status.Stages {
 			s, ok := combined.Status.Stages[stage]
 			if !ok {
@@ -135,13 +145,23 @@ func (o *operator) Update() error {
 				continue
 			}
 
-			combined.Status.Stages[stage].Status = *resolveStatus(&s.Status, &status.Status)
+			resolved := mergeTimes(*resolveStatus(&s.Status, &status.Status), &status.Status)
+			combined.Status.Stages[stage].Status = mergeTimes(resolved, &s.Status)
 			if s.Pod == nil {
 				combined.Status.Stages[stage].Pod = status.Pod
 			}
 			if len(s.Outputs) == 0 {
 				combined.Status.Stages[stage].Outputs = status.Outputs
 			}
+			if status.Logs != nil {
+				combined.Status.Stages[stage].Logs = status.Logs
+			}
+			if status.Diagnostics != nil {
+				combined.Status.Stages[stage].Diagnostics = status.Diagnostics
+			}
+			if len(status.Resources) > 0 {
+				combined.Status.Stages[stage].Resources = status.Resources
+			}
 		}
 
 		if !reflect.DeepEqual(staticStatus(&latest.Status), staticStatus(&combined.Status)) ||
@@ -168,15 +188,23 @@ func (o *operator) UpdateStageStatus(stage string, status *v1alpha1.Status) {
 
 	if _, ok := o.wfr.Status.Stages[stage]; !ok {
 		o.wfr.Status.Stages[stage] = &v1alpha1.StageStatus{
-			Status: *status,
+			Status: mergeTimes(*status, &v1alpha1.Status{}),
 		}
 	} else {
-		// keep startTime unchanged
-		startTime := o.wfr.Status.Stages[stage].Status.StartTime
-		o.wfr.Status.Stages[stage].Status = *status
-		o.wfr.Status.Stages[stage].Status.StartTime = startTime
+		// keep startTime and runningTime unchanged
+		o.wfr.Status.Stages[stage].Status = mergeTimes(*status, &o.wfr.Status.Stages[stage].Status)
+	}
+}
+
+// UpdateStageRunningTime records the time when containers of the stage start running. It's
+// only recorded once.
+func (o *operator) UpdateStageRunningTime(stage string, runningTime metav1.Time) {
+	status, ok := o.wfr.Status.Stages[stage]
+	if !ok || !status.Status.RunningTime.IsZero() {
+		return
 	}
 
+	status.Status.RunningTime = runningTime
 }
 
 // UpdateStagePodInfo updates stage pod information to WorkflowRun.
@@ -196,11 +224,73 @@ func (o *operator) UpdateStagePodInfo(stage string, podInfo *v1alpha1.PodInfo) {
 	o.wfr.Status.Stages[stage].Pod = podInfo
 }
 
+// UpdateStageLogsStatus updates stage logs status to WorkflowRun.
+func (o *operator) UpdateStageLogsStatus(stage string, logs *v1alpha1.LogsStatus) {
+	if o.wfr.Status.Stages == nil {
+		o.wfr.Status.Stages = make(map[string]*v1alpha1.StageStatus)
+	}
+
+	if _, ok := o.wfr.Status.Stages[stage]; !ok {
+		o.wfr.Status.Stages[stage] = &v1alpha1.StageStatus{}
+	}
+
+	o.wfr.Status.Stages[stage].Logs = logs
+}
+
+// UpdateStageDiagnostics updates stage diagnostics to WorkflowRun.
+func (o *operator) UpdateStageDiagnostics(stage string, diagnostics *v1alpha1.StageDiagnostics) {
+	if o.wfr.Status.Stages == nil {
+		o.wfr.Status.Stages = make(map[string]*v1alpha1.StageStatus)
+	}
+
+	if _, ok := o.wfr.Status.Stages[stage]; !ok {
+		o.wfr.Status.Stages[stage] = &v1alpha1.StageStatus{}
+	}
+
+	o.wfr.Status.Stages[stage].Diagnostics = diagnostics
+}
+
+// UpdateStageResources updates status of resources resolved in the stage to WorkflowRun.
+func (o *operator) UpdateStageResources(stage string, resources []v1alpha1.ResourceStatus) {
+	if o.wfr.Status.Stages == nil {
+		o.wfr.Status.Stages = make(map[string]*v1alpha1.StageStatus)
+	}
+
+	if _, ok := o.wfr.Status.Stages[stage]; !ok {
+		o.wfr.Status.Stages[stage] = &v1alpha1.StageStatus{}
+	}
+
+	o.wfr.Status.Stages[stage].Resources = resources
+}
+
 // OverallStatus calculates the overall status of the WorkflowRun. When a stage has its status
 // changed, the change will be updated in WorkflowRun stage status, but the overall status is
 // not calculated. So when we observed a WorkflowRun updated, we need to calculate its overall
-// status and update it if changed.
+// status and update it if changed. Running time of the WorkflowRun is that of its first running
+// stage, and completion time is that of its last terminated stage.
 func (o *operator) OverallStatus() (*v1alpha1.Status, error) {
+	overall, err := o.resolveOverallStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	terminated := overall.Status == v1alpha1.StatusCompleted || overall.Status == v1alpha1.StatusError
+	for _, status := range o.wfr.Status.Stages {
+		t := status.Status.RunningTime
+		if !t.IsZero() && (overall.RunningTime.IsZero() || t.Before(&overall.RunningTime)) {
+			overall.RunningTime = t
+		}
+		t = status.Status.CompletionTime
+		if terminated && overall.CompletionTime.Before(&t) {
+			overall.CompletionTime = t
+		}
+	}
+
+	return overall, nil
+}
+
+// resolveOverallStatus resolves the overall status from stage status.
+func (o *operator) resolveOverallStatus() (*v1alpha1.Status, error) {
 	startTime := o.wfr.ObjectMeta.CreationTimestamp
 	// If the WorkflowRun has no stage status recorded yet, we resolve the overall status as pending.
 	if o.wfr.Status.Stages == nil || len(o.wfr.Status.Stages) == 0 {
@@ -346,6 +436,7 @@ func (o *operator) Reconcile() error {
 		pod, err = o.client.CoreV1().Pods(GetExecutionContext(o.wfr).Namespace).Create(pod)
 		if err != nil {
 			log.WithField("wfr", o.wfr.Name).WithField("stg", stage).Error("Create pod for stage error: ", err)
+			metrics.IncPodCreationErrors(o.wfr.Spec.WorkflowRef.Name)
 			o.recorder.Eventf(o.wfr, corev1.EventTypeWarning, "StagePodCreated", "Create pod for stage '%s' error: %v", stage, err)
 			o.UpdateStageStatus(stage, &v1alpha1.Status{
 				Status:             v1alpha1.StatusError,
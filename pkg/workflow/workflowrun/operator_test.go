@@ -2,6 +2,7 @@ package workflowrun
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -140,3 +141,47 @@ func TestOverallStatus(t *testing.T) {
 	overall, _ = o.OverallStatus()
 	assert.Equal(t, v1alpha1.StatusRunning, overall.Status)
 }
+
+func TestOverallStatusTimes(t *testing.T) {
+	first := metav1.Time{Time: time.Now().Add(-time.Minute)}
+	second := metav1.Time{Time: time.Now().Add(-time.Second * 30)}
+	wf := &v1alpha1.Workflow{
+		Spec: v1alpha1.WorkflowSpec{
+			Stages: []v1alpha1.StageItem{{Name: "A"}, {Name: "B"}},
+		},
+	}
+	wfr := &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Status: v1alpha1.WorkflowRunStatus{
+			Stages: map[string]*v1alpha1.StageStatus{
+				"A": {
+					Status: v1alpha1.Status{Status: v1alpha1.StatusCompleted, RunningTime: first, CompletionTime: second},
+				},
+				"B": {
+					Status: v1alpha1.Status{Status: v1alpha1.StatusRunning, RunningTime: second},
+				},
+			},
+		},
+	}
+	o := &operator{
+		client: fake.NewSimpleClientset(),
+		wf:     wf,
+		wfr:    wfr,
+	}
+	overall, _ := o.OverallStatus()
+	assert.Equal(t, v1alpha1.StatusRunning, overall.Status)
+	assert.Equal(t, first, overall.RunningTime)
+	assert.True(t, overall.CompletionTime.IsZero())
+
+	now := metav1.Time{Time: time.Now()}
+	o.UpdateStageStatus("B", &v1alpha1.Status{Status: v1alpha1.StatusError, LastTransitionTime: now})
+	assert.Equal(t, second, wfr.Status.Stages["B"].Status.RunningTime)
+	assert.Equal(t, now, wfr.Status.Stages["B"].Status.CompletionTime)
+
+	overall, _ = o.OverallStatus()
+	assert.Equal(t, v1alpha1.StatusError, overall.Status)
+	assert.Equal(t, now, overall.CompletionTime)
+}
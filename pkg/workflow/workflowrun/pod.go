@@ -267,17 +267,27 @@ func (m *PodBuilder) ResolveInputResources() error {
 			}
 		}
 		var envs []corev1.EnvVar
+		resolvedMap := make(map[string]string)
 		for key, value := range envsMap {
 			resolved, err := ResolveRefStringValue(value, m.client)
 			if err != nil {
 				return fmt.Errorf("resolve ref value '%s' error: %v", value, err)
 			}
+			resolvedMap[key] = resolved
 			envs = append(envs, corev1.EnvVar{
 				Name:  key,
 				Value: resolved,
 			})
 		}
 
+		// Validate parameters of Git resources before the pod is built, so that invalid ones fail
+		// the stage early instead of failing in the resolver container.
+		if resource.Spec.Type == v1alpha1.GitResourceType {
+			if err := validateGitParameters(resolvedMap, false); err != nil {
+				return fmt.Errorf("invalid parameters of resource %s: %v", r.Name, err)
+			}
+		}
+
 		container := corev1.Container{
 			Name:  InputContainerName(index + 1),
 			Image: image,
@@ -356,17 +366,27 @@ func (m *PodBuilder) ResolveOutputResources() error {
 			}
 		}
 		var envs []corev1.EnvVar
+		resolvedMap := make(map[string]string)
 		for key, value := range envsMap {
 			resolved, err := ResolveRefStringValue(value, m.client)
 			if err != nil {
 				return fmt.Errorf("resolve ref value '%s' error: %v", value, err)
 			}
+			resolvedMap[key] = resolved
 			envs = append(envs, corev1.EnvVar{
 				Name:  key,
 				Value: resolved,
 			})
 		}
 
+		// Validate parameters of Git resources before the pod is built, so that invalid ones fail
+		// the stage early instead of failing in the resolver container.
+		if resource.Spec.Type == v1alpha1.GitResourceType {
+			if err := validateGitParameters(resolvedMap, true); err != nil {
+				return fmt.Errorf("invalid parameters of resource %s: %v", r.Name, err)
+			}
+		}
+
 		container := corev1.Container{
 			Name:  OutputContainerName(index + 1),
 			Image: image,
@@ -92,6 +92,11 @@ func (m *TimeoutProcessor) Add(wfr *v1alpha1.WorkflowRun) error {
 	return nil
 }
 
+// Size returns number of WorkflowRuns managed by the timeout manager.
+func (m *TimeoutProcessor) Size() int {
+	return len(m.items)
+}
+
 // Run will check timeout of managed WorkflowRun and process items that have expired their time.
 func (m *TimeoutProcessor) Run(interval time.Duration) {
 	ticker := time.NewTicker(interval)
@@ -39,6 +39,33 @@ func resolveStatus(latest, update *v1alpha1.Status) *v1alpha1.Status {
 	return latest
 }
 
+// mergeTimes keeps timing of the status from the previous one: start time and running time
+// are kept once set, completion time is only kept for terminated status and defaults to the
+// last transition time.
+func mergeTimes(status v1alpha1.Status, previous *v1alpha1.Status) v1alpha1.Status {
+	if !previous.StartTime.IsZero() {
+		status.StartTime = previous.StartTime
+	}
+	if !previous.RunningTime.IsZero() {
+		status.RunningTime = previous.RunningTime
+	}
+
+	if status.Status != v1alpha1.StatusCompleted && status.Status != v1alpha1.StatusError {
+		status.CompletionTime = metav1.Time{}
+		return status
+	}
+	if status.CompletionTime.IsZero() && (previous.Status == v1alpha1.StatusCompleted || previous.Status == v1alpha1.StatusError) {
+		status.CompletionTime = previous.CompletionTime
+	}
+	if status.CompletionTime.IsZero() {
+		status.CompletionTime = status.LastTransitionTime
+	}
+	if status.CompletionTime.IsZero() {
+		status.CompletionTime = metav1.Time{Time: time.Now()}
+	}
+	return status
+}
+
 // NextStages determine next stages that can be started to execute. It returns
 // stages that are not started yet but have all depended stages finished.
 func NextStages(wf *v1alpha1.Workflow, wfr *v1alpha1.WorkflowRun) []string {
@@ -73,6 +73,40 @@ func TestResolveStatus(t *testing.T) {
 	assert.Equal(t, expected, result)
 }
 
+func TestMergeTimes(t *testing.T) {
+	start := metav1.Time{Time: time.Now().Add(-time.Minute)}
+	running := metav1.Time{Time: time.Now().Add(-time.Second * 30)}
+	now := metav1.Time{Time: time.Now()}
+
+	previous := &v1alpha1.Status{
+		Status:      v1alpha1.StatusRunning,
+		StartTime:   start,
+		RunningTime: running,
+	}
+	result := mergeTimes(v1alpha1.Status{
+		Status:             v1alpha1.StatusCompleted,
+		LastTransitionTime: now,
+	}, previous)
+	assert.Equal(t, start, result.StartTime)
+	assert.Equal(t, running, result.RunningTime)
+	assert.Equal(t, now, result.CompletionTime)
+
+	// Completion time of terminated status is kept.
+	result = mergeTimes(v1alpha1.Status{
+		Status:             v1alpha1.StatusError,
+		LastTransitionTime: metav1.Time{Time: now.Add(time.Second)},
+	}, &result)
+	assert.Equal(t, now, result.CompletionTime)
+
+	// Status not terminated has no completion time.
+	result = mergeTimes(v1alpha1.Status{
+		Status:         v1alpha1.StatusRunning,
+		CompletionTime: now,
+	}, previous)
+	assert.True(t, result.CompletionTime.IsZero())
+	assert.Equal(t, start, result.StartTime)
+}
+
 func TestNextStages(t *testing.T) {
 	wf := &v1alpha1.Workflow{
 		Spec: v1alpha1.WorkflowSpec{
@@ -0,0 +1,342 @@
+package workflowstatus
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/history"
+)
+
+// recentWindow is the number of latest terminated WorkflowRuns that success rate is calculated from.
+const recentWindow = 10
+
+// SummaryLister lists summaries of WorkflowRuns archived in the summary store, cycloneserver.Client
+// implements it.
+type SummaryLister interface {
+	// ListWorkflowRunSummaries lists archived summaries of WorkflowRuns of the Workflow, newest first.
+	ListWorkflowRunSummaries(namespace, project, workflow, status string, limit int) ([]api.WorkflowRunSummary, error)
+}
+
+// Updater maintains status of Workflows, including conditions and statistics of their WorkflowRuns.
+// Statistics are calculated from summaries archived, merged with WorkflowRuns not deleted yet.
+type Updater struct {
+	client    clientset.Interface
+	summaries SummaryLister
+	recorder  record.EventRecorder
+
+	lock sync.Mutex
+	// observed are statuses of WorkflowRuns last observed.
+	observed map[types.UID]string
+}
+
+// NewUpdater creates a Workflow status updater.
+func NewUpdater(client clientset.Interface, summaries SummaryLister) *Updater {
+	return &Updater{
+		client:    client,
+		summaries: summaries,
+		recorder:  common.GetEventRecorder(client, common.EventSourceWfController),
+		observed:  make(map[types.UID]string),
+	}
+}
+
+// Update updates status of the Workflow, events are recorded when conditions transitioned.
+func (u *Updater) Update(wf *v1alpha1.Workflow) error {
+	var updated *v1alpha1.Workflow
+	var transitioned []v1alpha1.WorkflowCondition
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := u.client.CycloneV1alpha1().Workflows(wf.Namespace).Get(wf.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		status, err := u.status(latest)
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(status, &latest.Status) {
+			return nil
+		}
+
+		transitioned = changedConditions(latest.Status.Conditions, status.Conditions)
+		latest.Status = *status
+		updated, err = u.client.CycloneV1alpha1().Workflows(wf.Namespace).Update(latest)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range transitioned {
+		eventType := corev1.EventTypeNormal
+		if c.Status != corev1.ConditionTrue {
+			eventType = corev1.EventTypeWarning
+		}
+		u.recorder.Event(updated, eventType, c.Reason, c.Message)
+	}
+	return nil
+}
+
+// Observe updates status of the Workflow that the WorkflowRun belongs to, if status of the
+// WorkflowRun changed since last observed.
+func (u *Updater) Observe(wfr *v1alpha1.WorkflowRun) error {
+	u.lock.Lock()
+	status, ok := u.observed[wfr.UID]
+	u.lock.Unlock()
+	if ok && status == wfr.Status.Overall.Status {
+		return nil
+	}
+
+	if err := u.refresh(wfr); err != nil {
+		return err
+	}
+	u.lock.Lock()
+	u.observed[wfr.UID] = wfr.Status.Overall.Status
+	u.lock.Unlock()
+	return nil
+}
+
+// Forget updates status of the Workflow that the deleted WorkflowRun belongs to.
+func (u *Updater) Forget(wfr *v1alpha1.WorkflowRun) error {
+	u.lock.Lock()
+	delete(u.observed, wfr.UID)
+	u.lock.Unlock()
+
+	return u.refresh(wfr)
+}
+
+// refresh updates status of the Workflow that the WorkflowRun belongs to, WorkflowRuns without
+// Workflow reference and Workflows not found are skipped.
+func (u *Updater) refresh(wfr *v1alpha1.WorkflowRun) error {
+	if wfr.Spec.WorkflowRef == nil {
+		return nil
+	}
+
+	wf := &v1alpha1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      wfr.Spec.WorkflowRef.Name,
+			Namespace: wfr.Namespace,
+		},
+	}
+	if err := u.Update(wf); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("update status of workflow %s error: %v", wf.Name, err)
+	}
+	return nil
+}
+
+// status calculates status of the Workflow, transition time of conditions are kept if they are not changed.
+func (u *Updater) status(wf *v1alpha1.Workflow) (*v1alpha1.WorkflowStatus, error) {
+	status := &v1alpha1.WorkflowStatus{}
+
+	valid := condition(v1alpha1.WorkflowConditionValid, "Valid", "")
+	if err := validate(wf); err != nil {
+		valid = condition(v1alpha1.WorkflowConditionValid, "Invalid", err.Error())
+		valid.Status = corev1.ConditionFalse
+	}
+	resolved, err := u.resolved(wf)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range []v1alpha1.WorkflowCondition{valid, resolved} {
+		for _, previous := range wf.Status.Conditions {
+			if previous.Type == c.Type && previous.Status == c.Status {
+				c.LastTransitionTime = previous.LastTransitionTime
+			}
+		}
+		status.Conditions = append(status.Conditions, c)
+	}
+
+	summaries, err := u.history(wf)
+	if err != nil {
+		return nil, err
+	}
+	runs(status, summaries)
+
+	return status, nil
+}
+
+// history gets summaries of recent runs of the Workflow and its last successful run, archived
+// ones are merged with WorkflowRuns not deleted yet.
+func (u *Updater) history(wf *v1alpha1.Workflow) ([]api.WorkflowRunSummary, error) {
+	project := wf.Labels[common.ProjectNameLabelName]
+	recent, err := u.summaries.ListWorkflowRunSummaries(wf.Namespace, project, wf.Name, "", recentWindow)
+	if err != nil {
+		return nil, err
+	}
+	succeeded, err := u.summaries.ListWorkflowRunSummaries(wf.Namespace, project, wf.Name, v1alpha1.StatusCompleted, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	wfrs, err := u.client.CycloneV1alpha1().WorkflowRuns(wf.Namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", common.WorkflowNameLabelName, wf.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return history.Merge(append(recent, succeeded...), wfrs.Items), nil
+}
+
+// resolved checks whether all stages referred in the Workflow exist.
+func (u *Updater) resolved(wf *v1alpha1.Workflow) (v1alpha1.WorkflowCondition, error) {
+	var missing []string
+	for _, s := range wf.Spec.Stages {
+		_, err := u.client.CycloneV1alpha1().Stages(wf.Namespace).Get(s.Name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				missing = append(missing, s.Name)
+				continue
+			}
+			return v1alpha1.WorkflowCondition{}, err
+		}
+	}
+
+	if len(missing) > 0 {
+		c := condition(v1alpha1.WorkflowConditionStagesResolved, "StageNotFound",
+			fmt.Sprintf("Stages not found: %s", strings.Join(missing, ", ")))
+		c.Status = corev1.ConditionFalse
+		return c, nil
+	}
+	return condition(v1alpha1.WorkflowConditionStagesResolved, "StagesResolved", ""), nil
+}
+
+// runs records last run, last successful run and success rate of recent runs in the status,
+// summaries should be sorted newest first.
+func runs(status *v1alpha1.WorkflowStatus, summaries []api.WorkflowRunSummary) {
+	succeeded := 0
+	for i := range summaries {
+		s := &summaries[i]
+		if status.LastRun == nil {
+			status.LastRun = runRecord(s)
+		}
+		switch s.Status.Status {
+		case v1alpha1.StatusCompleted:
+			if status.LastSuccessfulRun == nil {
+				status.LastSuccessfulRun = runRecord(s)
+			}
+			if status.RecentRuns < recentWindow {
+				status.RecentRuns++
+				succeeded++
+			}
+		case v1alpha1.StatusError, v1alpha1.StatusCancelled:
+			if status.RecentRuns < recentWindow {
+				status.RecentRuns++
+			}
+		}
+	}
+
+	if status.RecentRuns > 0 {
+		status.SuccessRate = succeeded * 100 / status.RecentRuns
+	}
+}
+
+func runRecord(s *api.WorkflowRunSummary) *v1alpha1.WorkflowRunRecord {
+	return &v1alpha1.WorkflowRunRecord{
+		Name:           s.Name,
+		Status:         s.Status.Status,
+		StartTime:      s.Status.StartTime,
+		CompletionTime: s.Status.CompletionTime,
+	}
+}
+
+func condition(t v1alpha1.WorkflowConditionType, reason, message string) v1alpha1.WorkflowCondition {
+	return v1alpha1.WorkflowCondition{
+		Type:               t,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// changedConditions gets conditions whose status changed, conditions that are newly added
+// are only returned when they are not true.
+func changedConditions(previous, current []v1alpha1.WorkflowCondition) []v1alpha1.WorkflowCondition {
+	var results []v1alpha1.WorkflowCondition
+	for _, c := range current {
+		found := false
+		for _, p := range previous {
+			if p.Type != c.Type {
+				continue
+			}
+			found = true
+			if p.Status != c.Status || p.Message != c.Message {
+				results = append(results, c)
+			}
+		}
+		if !found && c.Status != corev1.ConditionTrue {
+			results = append(results, c)
+		}
+	}
+	return results
+}
+
+// validate validates spec of the Workflow: stages should be given with unique names, dependencies
+// should refer to stages in the Workflow and have no cycle.
+func validate(wf *v1alpha1.Workflow) error {
+	if len(wf.Spec.Stages) == 0 {
+		return fmt.Errorf("no stages")
+	}
+
+	depends := make(map[string][]string)
+	for _, s := range wf.Spec.Stages {
+		if s.Name == "" {
+			return fmt.Errorf("stage name is empty")
+		}
+		if _, ok := depends[s.Name]; ok {
+			return fmt.Errorf("duplicated stage %s", s.Name)
+		}
+		depends[s.Name] = s.Depends
+	}
+	for stage, ds := range depends {
+		for _, d := range ds {
+			if _, ok := depends[d]; !ok {
+				return fmt.Errorf("stage %s depends on %s which is not in the workflow", stage, d)
+			}
+		}
+	}
+
+	// Detect cycles with depth first search, stages being visited are marked false and visited
+	// ones are marked true.
+	visited := make(map[string]bool)
+	var visit func(stage string) error
+	visit = func(stage string) error {
+		done, ok := visited[stage]
+		if ok {
+			if !done {
+				return fmt.Errorf("dependency cycle found at stage %s", stage)
+			}
+			return nil
+		}
+
+		visited[stage] = false
+		for _, d := range depends[stage] {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		visited[stage] = true
+		return nil
+	}
+	for _, s := range wf.Spec.Stages {
+		if err := visit(s.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package workflowstatus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/caicloud/cyclone/pkg/apis/cyclone/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/k8s/clientset/fake"
+	api "github.com/caicloud/cyclone/pkg/server/apis/v1alpha1"
+	"github.com/caicloud/cyclone/pkg/workflow/common"
+	"github.com/caicloud/cyclone/pkg/workflow/history"
+)
+
+func workflowRun(name string, created int64, status string) *v1alpha1.WorkflowRun {
+	return &v1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.Time{Time: time.Unix(created, 0)},
+			Labels:            map[string]string{common.WorkflowNameLabelName: "wf"},
+		},
+		Spec:   v1alpha1.WorkflowRunSpec{WorkflowRef: &corev1.ObjectReference{Name: "wf"}},
+		Status: v1alpha1.WorkflowRunStatus{Overall: v1alpha1.Status{Status: status}},
+	}
+}
+
+// fakeLister lists archived summaries, newest first.
+type fakeLister struct {
+	summaries []api.WorkflowRunSummary
+}
+
+func (l *fakeLister) ListWorkflowRunSummaries(namespace, project, workflow, status string, limit int) ([]api.WorkflowRunSummary, error) {
+	var results []api.WorkflowRunSummary
+	for _, s := range l.summaries {
+		if s.Workflow == workflow && (status == "" || s.Status.Status == status) && len(results) < limit {
+			results = append(results, s)
+		}
+	}
+	return results, nil
+}
+
+func TestUpdate(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&v1alpha1.Workflow{
+			ObjectMeta: metav1.ObjectMeta{Name: "wf", Namespace: "default"},
+			Spec: v1alpha1.WorkflowSpec{Stages: []v1alpha1.StageItem{
+				{Name: "a"},
+				{Name: "b", Depends: []string{"a"}},
+			}},
+		},
+		&v1alpha1.Stage{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}},
+		workflowRun("wf-3", 3, v1alpha1.StatusCompleted),
+		workflowRun("wf-4", 4, v1alpha1.StatusCancelled),
+		workflowRun("wf-5", 5, v1alpha1.StatusRunning),
+	)
+	// WorkflowRuns wf-1 and wf-2 have been deleted, wf-3 is archived but not deleted yet.
+	lister := &fakeLister{}
+	for _, wfr := range []*v1alpha1.WorkflowRun{
+		workflowRun("wf-3", 3, v1alpha1.StatusCompleted),
+		workflowRun("wf-2", 2, v1alpha1.StatusError),
+		workflowRun("wf-1", 1, v1alpha1.StatusCompleted),
+	} {
+		lister.summaries = append(lister.summaries, *history.Summarize(wfr))
+	}
+	recorder := record.NewFakeRecorder(10)
+	u := &Updater{client: client, summaries: lister, recorder: recorder, observed: make(map[types.UID]string)}
+
+	wf := &v1alpha1.Workflow{ObjectMeta: metav1.ObjectMeta{Name: "wf", Namespace: "default"}}
+	assert.Nil(t, u.Update(wf))
+	wf, _ = client.CycloneV1alpha1().Workflows("default").Get("wf", metav1.GetOptions{})
+	assert.Equal(t, 2, len(wf.Status.Conditions))
+	assert.Equal(t, corev1.ConditionTrue, wf.Status.Conditions[0].Status)
+	assert.Equal(t, corev1.ConditionFalse, wf.Status.Conditions[1].Status)
+	assert.Equal(t, "Stages not found: b", wf.Status.Conditions[1].Message)
+	assert.Equal(t, "wf-5", wf.Status.LastRun.Name)
+	assert.Equal(t, v1alpha1.StatusRunning, wf.Status.LastRun.Status)
+	assert.Equal(t, "wf-3", wf.Status.LastSuccessfulRun.Name)
+	assert.Equal(t, 4, wf.Status.RecentRuns)
+	assert.Equal(t, 50, wf.Status.SuccessRate)
+	assert.Equal(t, 1, len(recorder.Events))
+	<-recorder.Events
+
+	// Stage created and the running WorkflowRun completed.
+	client.CycloneV1alpha1().Stages("default").Create(&v1alpha1.Stage{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default"}})
+	client.CycloneV1alpha1().WorkflowRuns("default").Update(workflowRun("wf-5", 5, v1alpha1.StatusCompleted))
+	transition := wf.Status.Conditions[0].LastTransitionTime
+	u.Observe(workflowRun("wf-5", 5, v1alpha1.StatusCompleted))
+	wf, _ = client.CycloneV1alpha1().Workflows("default").Get("wf", metav1.GetOptions{})
+	assert.Equal(t, transition, wf.Status.Conditions[0].LastTransitionTime)
+	assert.Equal(t, corev1.ConditionTrue, wf.Status.Conditions[1].Status)
+	assert.Equal(t, "wf-5", wf.Status.LastSuccessfulRun.Name)
+	assert.Equal(t, 5, wf.Status.RecentRuns)
+	assert.Equal(t, 60, wf.Status.SuccessRate)
+	assert.Equal(t, "Normal StagesResolved ", <-recorder.Events)
+
+	// WorkflowRun without Workflow reference is skipped.
+	invalid := workflowRun("wf-6", 6, v1alpha1.StatusCompleted)
+	invalid.Spec.WorkflowRef = nil
+	u.Observe(invalid)
+	u.Forget(invalid)
+}
+
+func TestRuns(t *testing.T) {
+	// The last successful run is out of the recent window.
+	var summaries []api.WorkflowRunSummary
+	for i := 0; i < recentWindow+2; i++ {
+		summaries = append(summaries, *history.Summarize(workflowRun("wf", int64(recentWindow+2-i), v1alpha1.StatusError)))
+	}
+	summaries[recentWindow+1].Status.Status = v1alpha1.StatusCompleted
+	status := &v1alpha1.WorkflowStatus{}
+	runs(status, summaries)
+	assert.Equal(t, v1alpha1.StatusError, status.LastRun.Status)
+	assert.Equal(t, v1alpha1.StatusCompleted, status.LastSuccessfulRun.Status)
+	assert.Equal(t, recentWindow, status.RecentRuns)
+	assert.Equal(t, 0, status.SuccessRate)
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		stages []v1alpha1.StageItem
+		valid  bool
+	}{
+		"valid": {
+			stages: []v1alpha1.StageItem{{Name: "a"}, {Name: "b", Depends: []string{"a"}}, {Name: "c", Depends: []string{"a", "b"}}},
+			valid:  true,
+		},
+		"empty": {
+			stages: nil,
+		},
+		"duplicated": {
+			stages: []v1alpha1.StageItem{{Name: "a"}, {Name: "a"}},
+		},
+		"unknown dependency": {
+			stages: []v1alpha1.StageItem{{Name: "a", Depends: []string{"b"}}},
+		},
+		"cycle": {
+			stages: []v1alpha1.StageItem{{Name: "a", Depends: []string{"c"}}, {Name: "b", Depends: []string{"a"}}, {Name: "c", Depends: []string{"b"}}},
+		},
+	}
+
+	for name, c := range cases {
+		err := validate(&v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Stages: c.stages}})
+		assert.Equal(t, c.valid, err == nil, name)
+	}
+}